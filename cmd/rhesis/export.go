@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/audio"
+	"github.com/jmcarbo/rhesis/internal/exporter"
+	"github.com/jmcarbo/rhesis/internal/generator"
+	"github.com/jmcarbo/rhesis/internal/player"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// runExport writes a script in a format other presentation tools can open.
+// Content that could not be converted is reported on stderr. It returns the
+// process exit code: 0 on success, 1 when the export fails and 2 on usage errors.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	pptxPath := fs.String("pptx", "", "Write the presentation as a PowerPoint deck to this path")
+	audioDir := fs.String("audio", "", "Directory with the narration written by -sound (<output>_audio) to attach to the slides")
+	autoAdvance := fs.Bool("auto-advance", true, "Advance to the next slide after its duration")
+	pdfPath := fs.String("pdf", "", "Print the slides to a PDF file at this path")
+	pageSize := fs.String("page-size", player.PageSizeSlide, "PDF page size ("+strings.Join(player.PageSizes(), ", ")+")")
+	handout := fs.Bool("handout", false, "Print each slide above its narration and speaker notes")
+	imagesDir := fs.String("images", "", "Save every slide as an image (slide_01.png, ...) in this directory")
+	imageFormat := fs.String("image-format", player.ImageFormatPNG, "Format of the slide images (png or webp)")
+	resolution := fs.String("resolution", "", "Viewport size of the images and PDF slides as WIDTHxHEIGHT (default 1920x1080, or the front matter resolution)")
+	aspect := fs.String("aspect", "", "Aspect ratio such as 16:9, 4:3 or 9:16; without -resolution the short side is 1080 pixels")
+	deviceScale := fs.Float64("device-scale", 1, "Device pixels per CSS pixel of the images, such as 2 for 3840x2160 images of a 1920x1080 viewport")
+	contactSheet := fs.String("contact-sheet", "", "Also save all slides as thumbnails in a grid to this .png or .webp file")
+	columns := fs.Int("columns", 4, "Number of thumbnails in every row of the contact sheet")
+	style := fs.String("style", "modern", "Presentation style for the PDF and images (modern, minimal, dark, elegant, or path to custom CSS file)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rhesis export [-pptx deck.pptx] [-audio dir] [-auto-advance=false] [-pdf slides.pdf] [-page-size slide|a4|letter] [-handout] [-images dir] [-image-format png|webp] [-contact-sheet sheet.png] [-resolution WxH] [-aspect W:H] [-device-scale n] [-style name] <script-file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || (*pptxPath == "" && *pdfPath == "" && *imagesDir == "") || (*contactSheet != "" && *imagesDir == "") {
+		fs.Usage()
+		return 2
+	}
+
+	parsed, err := script.Load(fs.Arg(0))
+	if err != nil {
+		var parseErr *script.ParseError
+		if errors.As(err, &parseErr) {
+			for _, d := range parseErr.Diagnostics {
+				fmt.Fprintln(os.Stderr, d.String())
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Failed to parse script: %v\n", err)
+		return 1
+	}
+
+	// Resolve slide durations the same way presentation mode does: narrated
+	// slides last as long as their audio and the others share what is left
+	var audioFiles []string
+	if *pptxPath != "" && *audioDir != "" {
+		audioFiles = narration(parsed, *audioDir)
+	}
+	if parsed.Duration > 0 {
+		report, err := parsed.FitDuration()
+		fmt.Fprint(os.Stderr, report.String())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fit slide timings: %v\n", err)
+			return 1
+		}
+	}
+
+	if *pptxPath != "" {
+		opts := exporter.PPTXOptions{AutoAdvance: *autoAdvance, AudioFiles: audioFiles}
+
+		warnings, err := exporter.ExportPPTX(parsed, *pptxPath, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export presentation: %v\n", err)
+			return 1
+		}
+		for _, d := range warnings {
+			fmt.Fprintln(os.Stderr, d.String())
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d slide(s) to %s\n", len(parsed.Slides), *pptxPath)
+	}
+
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	if !setFlags["style"] && parsed.Theme != "" {
+		*style = parsed.Theme
+		if strings.HasSuffix(strings.ToLower(*style), ".css") {
+			// Stylesheets named in the front matter live next to the script
+			*style = parsed.ResolvePath(*style)
+		}
+	}
+	viewport, err := applyViewport(parsed, setFlags, *resolution, *aspect, *deviceScale)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid viewport: %v\n", err)
+		return 2
+	}
+
+	if *pdfPath != "" {
+		opts := player.PDFOptions{PageSize: *pageSize, Handout: *handout}
+		err := withHTML(parsed, *style, func(htmlPath string) error {
+			p := player.NewPresentationPlayer()
+			p.SetViewport(viewport)
+			return p.ExportPDF(htmlPath, *pdfPath, opts)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export PDF: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Printed %d slide(s) to %s\n", len(parsed.Slides), *pdfPath)
+	}
+
+	if *imagesDir != "" {
+		opts := player.ScreenshotOptions{
+			Format:       *imageFormat,
+			ContactSheet: *contactSheet,
+			Columns:      *columns,
+		}
+		var paths []string
+		err := withHTML(parsed, *style, func(htmlPath string) error {
+			var err error
+			p := player.NewPresentationPlayer()
+			p.SetViewport(viewport)
+			paths, err = p.ExportScreenshots(htmlPath, *imagesDir, opts)
+			return err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export images: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Saved %d slide image(s) to %s\n", len(paths), *imagesDir)
+		if *contactSheet != "" {
+			fmt.Fprintf(os.Stderr, "Saved contact sheet to %s\n", *contactSheet)
+		}
+	}
+	return 0
+}
+
+// withHTML generates the presentation into a temporary directory for the
+// browser to render and removes it once fn returns. Speaker notes are embedded
+// for handouts since the file never leaves the temporary directory.
+func withHTML(s *script.Script, style string, fn func(htmlPath string) error) error {
+	dir, err := os.MkdirTemp("", "rhesis-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	htmlPath := filepath.Join(dir, "presentation.html")
+	gen := generator.NewHTMLGenerator()
+	gen.SetSpeakerNotes(true)
+	if err := gen.GeneratePresentation(s, htmlPath, style, false); err != nil {
+		return fmt.Errorf("failed to generate presentation: %w", err)
+	}
+	return fn(htmlPath)
+}
+
+// narration returns the audio file of every slide found in dir and times the
+// slides to their narration as presentation mode does. Slides are matched to
+// the narration cache by their transcription, so audio of edited or moved
+// slides is never attached to the wrong one. Directories without a manifest
+// are read as slide_01.mp3, slide_02.mp3, ...
+func narration(s *script.Script, dir string) []string {
+	files := make([]string, len(s.Slides))
+	var cache *audio.NarrationCache
+	if _, err := os.Stat(filepath.Join(dir, audio.ManifestFile)); err == nil {
+		if cache, err = audio.OpenNarrationCache(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return files
+		}
+	}
+
+	var missing []int
+	for i, slide := range s.Slides {
+		var path string
+		var duration time.Duration
+		if cache != nil {
+			if slide.Transcription == "" {
+				continue
+			}
+			cached, ok := cache.Find(slide.Transcription)
+			if !ok {
+				missing = append(missing, i+1)
+				continue
+			}
+			path, duration = cached.Path, cached.Duration
+		} else {
+			path = filepath.Join(dir, fmt.Sprintf("slide_%02d.mp3", i+1))
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			var err error
+			if duration, err = audio.GetAudioDuration(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Could not get duration for audio file %s: %v\n", path, err)
+				files[i] = path
+				continue
+			}
+		}
+		files[i] = path
+		s.Slides[i].Duration = duration + audioBuffer
+		s.Slides[i].NarrationDuration = duration
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no narration in %s for the transcription of slides %v; run with -sound to generate it\n", dir, missing)
+	}
+	return files
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// runFmt rewrites scripts in canonical Markdown. With -check it only reports
+// the files that are not formatted. It returns the process exit code: 0 when
+// every file is (or now is) formatted, 1 when a file needs formatting or could
+// not be processed and 2 on usage errors.
+func runFmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := fs.Bool("check", false, "Report files that are not formatted instead of rewriting them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rhesis fmt [-check] <script-file>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	exitCode := 0
+	for _, path := range fs.Args() {
+		changed, err := formatScript(path, !*check)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			exitCode = 1
+			continue
+		}
+		if changed {
+			if *check {
+				fmt.Printf("%s: not formatted\n", path)
+				exitCode = 1
+			} else {
+				fmt.Printf("%s: formatted\n", path)
+			}
+		}
+	}
+	return exitCode
+}
+
+// formatScript formats a single script and reports whether its content changed.
+// The file is only rewritten when write is set.
+func formatScript(path string, write bool) (bool, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return false, fmt.Errorf("only Markdown scripts can be formatted")
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	parsed, err := script.ParseScript(path)
+	if err != nil {
+		var parseErr *script.ParseError
+		if errors.As(err, &parseErr) {
+			for _, d := range parseErr.Diagnostics {
+				fmt.Fprintln(os.Stderr, d.String())
+			}
+		}
+		return false, fmt.Errorf("cannot format: %w", err)
+	}
+
+	formatted, err := parsed.Markdown()
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(original, formatted) {
+		return false, nil
+	}
+
+	if write {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/importer"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// runImport converts a Marp, reveal.js or PowerPoint deck into a script and
+// writes it as Markdown (or JSON with -json) to -output or stdout. Constructs
+// that could not be converted are reported on stderr. It returns the process exit code:
+// 0 on success, 1 when the deck cannot be converted and 2 on usage errors.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	from := fs.String("from", "", "Format of the deck: "+strings.Join(importer.Formats(), " or "))
+	output := fs.String("output", "", "Path of the script to write (default: stdout)")
+	jsonOutput := fs.Bool("json", false, "Write the script as JSON instead of Markdown")
+	assets := fs.String("assets", "", "Directory for images extracted from PowerPoint decks (default: <script>-assets)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: rhesis import -from %s [-output script.md] [-assets dir] <deck-file>\n", strings.Join(importer.Formats(), "|"))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *from == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	deck := fs.Arg(0)
+	var imported *script.Script
+	var err error
+	if importer.Format(*from) == importer.FormatPPTX {
+		// Image paths are written relative to the script, which goes to the
+		// current directory when printed to stdout
+		scriptPath := *output
+		if scriptPath == "" {
+			scriptPath = strings.TrimSuffix(filepath.Base(deck), filepath.Ext(deck)) + ".md"
+		}
+		assetsDir := *assets
+		if assetsDir == "" {
+			assetsDir = strings.TrimSuffix(scriptPath, filepath.Ext(scriptPath)) + "-assets"
+		}
+		imported, err = importer.ImportPPTX(deck, scriptPath, assetsDir)
+	} else {
+		imported, err = importer.Import(deck, importer.Format(*from))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import deck: %v\n", err)
+		return 1
+	}
+	for _, d := range imported.Diagnostics {
+		fmt.Fprintln(os.Stderr, d.String())
+	}
+
+	var b bytes.Buffer
+	if *jsonOutput {
+		err = imported.WriteJSON(&b)
+	} else {
+		err = imported.WriteMarkdown(&b)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode script: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		os.Stdout.Write(b.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*output, b.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write script: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d slide(s) into %s\n", len(imported.Slides), *output)
+	return 0
+}
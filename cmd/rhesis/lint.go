@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// lintReport is the JSON document printed by `rhesis lint -json`
+type lintReport struct {
+	Diagnostics []script.Diagnostic `json:"diagnostics"`
+	Errors      int                 `json:"errors"`
+	Warnings    int                 `json:"warnings"`
+}
+
+// runLint parses every script given on the command line and prints the
+// diagnostics found. It returns the process exit code: 0 when there are no
+// errors, 1 when at least one error was reported and 2 on usage errors.
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print diagnostics as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rhesis lint [-json] <script-file>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	report := lintReport{Diagnostics: make([]script.Diagnostic, 0)}
+	for _, path := range fs.Args() {
+		report.Diagnostics = append(report.Diagnostics, lintScript(path)...)
+	}
+
+	for _, d := range report.Diagnostics {
+		switch d.Severity {
+		case script.SeverityError:
+			report.Errors++
+		case script.SeverityWarning:
+			report.Warnings++
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode diagnostics: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, d := range report.Diagnostics {
+			fmt.Println(d.String())
+		}
+		fmt.Printf("%d error(s), %d warning(s)\n", report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// lintScript returns the diagnostics for a single script file
+func lintScript(path string) []script.Diagnostic {
+	parsed, err := script.Load(path)
+	if err != nil {
+		var parseErr *script.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.Diagnostics
+		}
+		return []script.Diagnostic{{
+			Severity: script.SeverityError,
+			File:     path,
+			Code:     "read-error",
+			Message:  err.Error(),
+		}}
+	}
+	return parsed.Diagnostics
+}
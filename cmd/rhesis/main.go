@@ -6,8 +6,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jmcarbo/rhesis/internal/audio"
 	"github.com/jmcarbo/rhesis/internal/generator"
@@ -16,27 +16,67 @@ import (
 	"github.com/jmcarbo/rhesis/internal/subtitle"
 )
 
+// audioBuffer is the pause added after the narration of each slide
+const audioBuffer = 500 * time.Millisecond
+
 func main() {
+	// Subcommands have their own flags and are dispatched before the main flag set is parsed
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:]))
+		case "parse":
+			os.Exit(runParse(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		}
+	}
+
 	var (
-		scriptPath    = flag.String("script", "", "Path to the presentation script file")
+		scriptPath    = flag.String("script", "", "Path to the presentation script file (Markdown, or JSON as written by rhesis parse -json)")
 		outputPath    = flag.String("output", "presentation.html", "Output HTML file path")
 		recordPath    = flag.String("record", "", "Path to save video recording (optional)")
 		play          = flag.Bool("play", false, "Play the presentation after generating")
 		style         = flag.String("style", "modern", "Presentation style (modern, minimal, dark, elegant, or path to custom CSS file)")
 		transcription = flag.Bool("transcription", false, "Include transcription panel in presentation")
+		notes         = flag.Bool("notes", false, "Embed speaker notes for the presenter view (anyone with the HTML file can read them)")
 		subtitlePath  = flag.String("subtitle", "", "Generate subtitle file (optional, .srt or .vtt)")
-		sound         = flag.Bool("sound", false, "Generate audio from transcriptions using ElevenLabs")
+		sound         = flag.Bool("sound", false, "Generate audio from transcriptions with the -tts provider")
+		ttsProvider   = flag.String("tts", audio.DefaultProvider, "Text-to-speech provider ("+strings.Join(audio.Providers(), ", ")+"), or the front matter tts")
+		ttsKey        = flag.String("tts-key", "", "API key of the -tts provider (or set its environment variable, such as ELEVENLABS_API_KEY)")
+		ttsURL        = flag.String("tts-url", "", "Endpoint of the -tts provider, replacing its default")
 		apiKey        = flag.String("elevenlabs-key", os.Getenv("ELEVENLABS_API_KEY"), "ElevenLabs API key (or set ELEVENLABS_API_KEY env var)")
-		voiceID       = flag.String("voice", "", "ElevenLabs voice ID (optional, defaults to Rachel)")
-		skipAudioGen  = flag.Bool("skip-audio-creation", false, "Skip audio generation if audio files already exist")
+		voiceID       = flag.String("voice", "", "Voice of the -tts provider (optional, such as an ElevenLabs voice ID; defaults to the provider's)")
+		modelID       = flag.String("model", "", "Model of the -tts provider (optional, defaults to the provider's)")
+		skipAudioGen  = flag.Bool("skip-audio-creation", false, "Use cached narration only, never calling the -tts provider")
+		ttsWorkers    = flag.Int("tts-concurrency", audio.DefaultConcurrency, "Number of slides narrated at once")
+		ttsRate       = flag.Float64("tts-rate", 0, "Maximum requests per second to the -tts provider, retries included (0 is unlimited)")
+		ttsRetries    = flag.Int("tts-retries", 3, "Times a rate-limited or failed request is retried, with exponential backoff")
+		allowMissing  = flag.Bool("allow-missing-audio", false, "Leave slides whose narration could not be produced silent instead of failing")
 		background    = flag.Bool("background", false, "Run presentation in background (headless mode)")
+		render        = flag.Bool("render", false, "Render the -record video frame by frame on a paused clock instead of recording in real time (requires ffmpeg)")
+		fps           = flag.Int("fps", player.DefaultFPS, "Frame rate of videos made with -render")
+		segment       = flag.Int("segment", 0, "Record the -record video in clips of this many slides and join them with ffmpeg (requires ffmpeg; 0 records in one piece)")
+		resolution    = flag.String("resolution", "", "Viewport and video size as WIDTHxHEIGHT (default 1920x1080, or the front matter resolution)")
+		aspect        = flag.String("aspect", "", "Aspect ratio such as 16:9, 4:3 or 9:16; without -resolution the short side is 1080 pixels")
+		deviceScale   = flag.Float64("device-scale", 1, "Device pixels per CSS pixel, such as 2 for sharper video at the same layout")
 		fuse          = flag.Bool("fuse", false, "Fuse mode: merge video and audio files (requires -video, -audio, and -output)")
 		videoPath     = flag.String("video", "", "Input video file path (for fuse mode)")
 		audioPath     = flag.String("audio", "", "Input audio file path or directory (for fuse mode)")
-		durations     = flag.String("durations", "", "Comma-separated slide durations in seconds (for fuse mode with audio directory)")
+		durations     = flag.String("durations", "", "Comma-separated slide durations in seconds or as durations like 1m30s (for fuse mode with audio directory)")
 	)
 	flag.Parse()
 
+	// Remember which flags were given explicitly so they can override front matter
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
 	// Handle fuse mode separately
 	if *fuse {
 		if *videoPath == "" || *audioPath == "" || *outputPath == "" {
@@ -44,7 +84,7 @@ func main() {
 			fmt.Println("  -video: Input video file path")
 			fmt.Println("  -audio: Input audio file path (single file) or directory (multiple audio files)")
 			fmt.Println("  -output: Output video file path")
-			fmt.Println("  -durations: Comma-separated slide durations in seconds (optional - will be inferred from audio files if not provided)")
+			fmt.Println("  -durations: Comma-separated slide durations such as 5,2.5,1m30s (optional - will be inferred from audio files if not provided)")
 			os.Exit(1)
 		}
 
@@ -57,95 +97,155 @@ func main() {
 
 	// Normal presentation mode
 	if *scriptPath == "" {
-		fmt.Println("Usage: rhesis -script <script-file> [-output <html-file>] [-style <style-name|css-file>] [-record <video-file>] [-play] [-background] [-transcription] [-subtitle <subtitle-file>] [-sound] [-skip-audio-creation] [-elevenlabs-key <api-key>] [-voice <voice-id>]")
+		fmt.Println("Usage: rhesis -script <script-file> [-output <html-file>] [-style <style-name|css-file>] [-record <video-file>] [-render] [-fps <rate>] [-segment <slides>] [-resolution <WxH>] [-aspect <W:H>] [-device-scale <n>] [-play] [-background] [-transcription] [-subtitle <subtitle-file>] [-sound] [-skip-audio-creation] [-allow-missing-audio] [-tts-concurrency <n>] [-tts-rate <per-second>] [-tts-retries <n>] [-tts <provider>] [-tts-key <api-key>] [-tts-url <url>] [-voice <voice>] [-model <model>]")
 		fmt.Println("\nOr for fuse mode:")
 		fmt.Println("  rhesis -fuse -video <video-file> -audio <audio-file-or-directory> -output <output-file> [-durations <comma-separated-durations>]")
+		fmt.Println("\nOr to check and format scripts:")
+		fmt.Println("  rhesis lint [-json] <script-file>...")
+		fmt.Println("  rhesis fmt [-check] <script-file>...")
+		fmt.Println("  rhesis parse [-json] <script-file>")
+		fmt.Println("  rhesis import -from marp|reveal|pptx [-output script.md] <deck-file>")
+		fmt.Println("  rhesis export -pptx deck.pptx [-audio dir] <script-file>")
+		fmt.Println("  rhesis export -pdf slides.pdf [-page-size slide|a4|letter] [-handout] <script-file>")
+		fmt.Println("  rhesis export -images dir [-image-format png|webp] [-contact-sheet sheet.png] <script-file>")
 		os.Exit(1)
 	}
 
-	parsedScript, err := script.ParseScript(*scriptPath)
+	parsedScript, err := script.Load(*scriptPath)
 	if err != nil {
 		log.Fatalf("Failed to parse script: %v", err)
 	}
+	for _, d := range parsedScript.Diagnostics {
+		fmt.Println(d.String())
+	}
+
+	// Front matter provides defaults for anything not set on the command line
+	if !setFlags["style"] && parsedScript.Theme != "" {
+		*style = parsedScript.Theme
+		if strings.HasSuffix(strings.ToLower(*style), ".css") {
+			// Stylesheets named in the front matter live next to the script
+			*style = parsedScript.ResolvePath(*style)
+		}
+	}
+	if !setFlags["transcription"] && parsedScript.Transcription != nil {
+		*transcription = *parsedScript.Transcription
+	}
+	viewport, err := applyViewport(parsedScript, setFlags, *resolution, *aspect, *deviceScale)
+	if err != nil {
+		log.Fatalf("Invalid viewport: %v", err)
+	}
 
 	// Generate audio if requested
-	audioFiles := make([]string, 0)
+	// audioFiles holds the narration of every slide, empty for silent slides
+	var audioFiles []string
+	narrated := false
 	if *sound {
-		// Only require API key if we're not skipping audio generation entirely
-		if *apiKey == "" && !*skipAudioGen {
-			log.Fatal("ElevenLabs API key is required when using -sound flag. Use -elevenlabs-key or set ELEVENLABS_API_KEY environment variable.")
+		provider, ttsConfig := resolveTTS(parsedScript, setFlags, ttsFlags{
+			provider: *ttsProvider,
+			key:      *ttsKey,
+			url:      *ttsURL,
+			voice:    *voiceID,
+			model:    *modelID,
+		})
+		if ttsConfig.APIKey == "" && provider == audio.DefaultProvider {
+			ttsConfig.APIKey = *apiKey
 		}
 
-		audioGen := audio.NewElevenLabsGenerator(audio.ElevenLabsConfig{
-			APIKey:  *apiKey,
-			VoiceID: *voiceID,
-		})
+		// Only require API key if we're not skipping audio generation entirely;
+		// self-hosted endpoints may not need one
+		ttsProviderInfo, _ := audio.LookupProvider(provider)
+		if keyEnv := ttsProviderInfo.KeyEnv; keyEnv != "" && ttsConfig.APIKey == "" && ttsConfig.URL == "" && os.Getenv(keyEnv) == "" && !*skipAudioGen {
+			log.Fatalf("%s API key is required when using -sound flag. Use -tts-key or set %s environment variable.", provider, keyEnv)
+		}
+
+		audioGen, err := audio.NewGenerator(provider, ttsConfig)
+		if err != nil {
+			log.Fatalf("Failed to set up text-to-speech: %v", err)
+		}
+		fmt.Printf("Narrating with %s\n", provider)
 
-		// Create audio output directory
+		// Narration is cached under a hash of the provider settings and the
+		// text, so only new or edited transcriptions are sent to the provider
 		audioDir := strings.TrimSuffix(*outputPath, filepath.Ext(*outputPath)) + "_audio"
-		if err := os.MkdirAll(audioDir, 0755); err != nil {
-			log.Fatalf("Failed to create audio directory: %v", err)
+		cache, err := audio.OpenNarrationCache(audioDir)
+		if err != nil {
+			log.Fatalf("Failed to open narration cache: %v", err)
 		}
 
 		fmt.Println("Processing audio files...")
+		texts := make([]string, len(parsedScript.Slides))
 		for i, slide := range parsedScript.Slides {
-			if slide.Transcription != "" {
-				audioPath := filepath.Join(audioDir, fmt.Sprintf("slide_%02d.mp3", i+1))
-
-				// Check if we should skip audio generation
-				if *skipAudioGen {
-					if _, err := os.Stat(audioPath); err == nil {
-						// Audio file exists, skip generation
-						fmt.Printf("Using existing audio file for slide %d: %s\n", i+1, audioPath)
-
-						// Get audio duration to adjust slide timing
-						audioDuration, err := audio.GetAudioDuration(audioPath)
-						if err == nil {
-							audioDurationSeconds := audioDuration.Seconds()
-							originalDuration := slide.Duration
-							// Always adjust slide duration to audio duration + 0.5 seconds
-							parsedScript.Slides[i].Duration = int(audioDurationSeconds + 0.5)
-							fmt.Printf("Adjusted slide %d duration from %ds to %.1fs to match audio + 0.5s buffer\n",
-								i+1, originalDuration, audioDurationSeconds+0.5)
-						} else {
-							fmt.Printf("Warning: Could not get duration for audio file %s: %v\n", audioPath, err)
-						}
-
-						audioFiles = append(audioFiles, audioPath)
-						continue
-					} else {
-						fmt.Printf("Audio file not found for slide %d, generating...\n", i+1)
-					}
-				}
+			texts[i] = slide.Transcription
+		}
+		narrator := &audio.Narrator{
+			Cache:     cache,
+			Provider:  provider,
+			Config:    ttsConfig,
+			Generator: audioGen,
+			Exact:     ttsProviderInfo.Exact,
+			Options: audio.NarratorOptions{
+				Concurrency: *ttsWorkers,
+				Rate:        *ttsRate,
+				Retries:     *ttsRetries,
+				CachedOnly:  *skipAudioGen,
+				OnRetry: func(slide, attempt int, delay time.Duration, err error) {
+					fmt.Printf("Retrying slide %d in %v (attempt %d of %d): %v\n", slide+1, delay, attempt+1, *ttsRetries+1, err)
+				},
+			},
+		}
+		results := narrator.NarrateAll(texts)
+
+		keys := make([]string, len(parsedScript.Slides))
+		audioFiles = make([]string, len(parsedScript.Slides))
+		for i, result := range results {
+			switch {
+			case result.Key == "":
+				continue
+			case result.Err != nil:
+				log.Printf("Warning: Failed to generate audio for slide %d: %v", i+1, result.Err)
+				continue
+			case result.Cached:
+				fmt.Printf("Using cached audio for slide %d: %s\n", i+1, result.Path)
+			default:
+				fmt.Printf("Generated audio for slide %d (duration: %v)\n", i+1, result.Duration)
+			}
+			keys[i] = result.Key
 
-				// Generate audio
-				audioDuration, err := audioGen.GenerateAudio(slide.Transcription, audioPath)
-				if err != nil {
-					log.Printf("Warning: Failed to generate audio for slide %d: %v", i+1, err)
-					continue
-				}
+			// Always adjust slide duration to audio duration + 0.5 seconds
+			originalDuration := parsedScript.Slides[i].Duration
+			parsedScript.Slides[i].Duration = result.Duration + audioBuffer
+			parsedScript.Slides[i].NarrationDuration = result.Duration
+			fmt.Printf("Adjusted slide %d duration from %v to %v to match audio + %v buffer\n",
+				i+1, originalDuration, parsedScript.Slides[i].Duration, audioBuffer)
 
-				// Get actual audio duration if possible
-				actualDuration, err := audio.GetAudioDuration(audioPath)
-				if err == nil {
-					audioDuration = actualDuration
-				}
+			audioFiles[i] = result.Path
+			narrated = true
+		}
 
-				// Always adjust slide duration to audio duration + 0.5 seconds
-				audioDurationSeconds := audioDuration.Seconds()
-				originalDuration := slide.Duration
-				parsedScript.Slides[i].Duration = int(audioDurationSeconds + 0.5)
-				fmt.Printf("Adjusted slide %d duration from %ds to %.1fs to match audio + 0.5s buffer\n",
-					i+1, originalDuration, audioDurationSeconds+0.5)
+		if err := cache.SetSlides(keys); err != nil {
+			log.Printf("Warning: Failed to record slide narration: %v", err)
+		}
 
-				audioFiles = append(audioFiles, audioPath)
-				fmt.Printf("Generated audio for slide %d (duration: %v)\n", i+1, audioDuration)
-			}
+		summary := audio.Summarize(results)
+		fmt.Println(summary)
+		if summary.Failed > 0 && !*allowMissing {
+			log.Fatalf("Narration failed for slides %v. Fix the errors above and run again (narration already produced is cached), or use -allow-missing-audio to leave them silent.", summary.FailedSlides)
+		}
+	}
+
+	// Fit slide timings to the total duration, if the script sets one;
+	// narrated slides keep the length of their audio
+	if parsedScript.Duration > 0 {
+		report, err := parsedScript.FitDuration()
+		fmt.Print(report.String())
+		if err != nil {
+			log.Fatalf("Failed to fit slide timings: %v", err)
 		}
 	}
 
 	gen := generator.NewHTMLGenerator()
-	if *sound && len(audioFiles) > 0 {
+	gen.SetSpeakerNotes(*notes)
+	if narrated {
 		if err := gen.GeneratePresentationWithOptions(parsedScript, *outputPath, *style, *transcription, audioFiles, *background); err != nil {
 			log.Fatalf("Failed to generate presentation: %v", err)
 		}
@@ -161,7 +261,7 @@ func main() {
 	if *subtitlePath != "" {
 		// Extract transcriptions and durations from slides
 		var transcriptions []string
-		var durations []int
+		var durations []time.Duration
 		for _, slide := range parsedScript.Slides {
 			transcriptions = append(transcriptions, slide.Transcription)
 			durations = append(durations, slide.Duration)
@@ -180,32 +280,50 @@ func main() {
 	}
 
 	if *play {
-		if *background {
-			fmt.Println("Running presentation in background mode (headless)...")
-		}
 		p := player.NewPresentationPlayer()
-		if err := p.PlayPresentationWithOptions(*outputPath, *recordPath, *background); err != nil {
-			log.Fatalf("Failed to play presentation: %v", err)
+		p.SetViewport(viewport)
+		if *render && *recordPath != "" {
+			fmt.Println("Rendering presentation frame by frame...")
+			if err := p.RenderPresentation(*outputPath, *recordPath, player.RenderOptions{FPS: *fps}); err != nil {
+				log.Fatalf("Failed to render presentation: %v", err)
+			}
+		} else if *segment > 0 && *recordPath != "" {
+			fmt.Printf("Recording presentation in segments of %d slides...\n", *segment)
+			segments, err := p.RecordSegments(*outputPath, *recordPath, player.SegmentOptions{SlidesPerSegment: *segment, Headless: *background})
+			if err != nil {
+				log.Fatalf("Failed to record presentation: %v", err)
+			}
+			fmt.Printf("Joined %d segments into %s\n", len(segments), *recordPath)
+		} else {
+			if *background {
+				fmt.Println("Running presentation in background mode (headless)...")
+			}
+			if err := p.PlayPresentationWithOptions(*outputPath, *recordPath, *background); err != nil {
+				log.Fatalf("Failed to play presentation: %v", err)
+			}
 		}
 
 		// If both recording and sound were enabled, merge audio with video
-		if *recordPath != "" && *sound && len(audioFiles) > 0 {
+		if *recordPath != "" && narrated {
 			fmt.Println("Merging audio with video recording...")
 			merger := audio.NewAudioVideoMerger()
 
 			// Extract slide durations
-			durations := make([]int, len(parsedScript.Slides))
-			totalExpectedDuration := 0
+			durations := make([]time.Duration, len(parsedScript.Slides))
 			for i, slide := range parsedScript.Slides {
 				durations[i] = slide.Duration
-				totalExpectedDuration += slide.Duration
 			}
-			fmt.Printf("Expected total duration: %d seconds\n", totalExpectedDuration)
+			fmt.Printf("Expected total duration: %v\n", parsedScript.GetTotalDuration())
 
 			// Create output path for merged video
 			mergedPath := strings.TrimSuffix(*recordPath, filepath.Ext(*recordPath)) + "_with_audio" + filepath.Ext(*recordPath)
 
-			if err := merger.MergeAudioWithVideo(*recordPath, audioFiles, durations, mergedPath); err != nil {
+			// Rendered and segmented videos last exactly the slide durations
+			merge := merger.MergeAudioWithVideo
+			if *render || *segment > 0 {
+				merge = merger.MergeAudioWithTimedVideo
+			}
+			if err := merge(*recordPath, audioFiles, durations, mergedPath); err != nil {
 				log.Printf("Warning: Failed to merge audio with video: %v", err)
 				log.Printf("Original video saved without audio to: %s", *recordPath)
 			} else {
@@ -235,40 +353,58 @@ func runFuseMode(videoPath, audioPath, outputPath, durationsStr string) error {
 			return fmt.Errorf("failed to read audio directory: %w", err)
 		}
 
-		// Sort entries by name to ensure consistent ordering
-		for _, entry := range entries {
-			if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".mp3") || strings.HasSuffix(entry.Name(), ".wav") || strings.HasSuffix(entry.Name(), ".m4a")) {
-				audioFiles = append(audioFiles, filepath.Join(audioPath, entry.Name()))
+		// Narration written by -sound is taken in slide order from its
+		// manifest, with "" for silent slides so files and durations stay
+		// paired by slide; other directories in file name order
+		narrated := 0
+		if cache, err := audio.OpenNarrationCache(audioPath); err == nil {
+			for _, narration := range cache.Slides() {
+				audioFiles = append(audioFiles, narration.Path)
+				if narration.Path != "" {
+					narrated++
+				}
 			}
 		}
+		if narrated == 0 {
+			audioFiles = audioFiles[:0]
+			for _, entry := range entries {
+				if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".mp3") || strings.HasSuffix(entry.Name(), ".wav") || strings.HasSuffix(entry.Name(), ".m4a")) {
+					audioFiles = append(audioFiles, filepath.Join(audioPath, entry.Name()))
+				}
+			}
+			narrated = len(audioFiles)
+		}
 
-		if len(audioFiles) == 0 {
+		if narrated == 0 {
 			return fmt.Errorf("no audio files found in directory: %s", audioPath)
 		}
 
-		fmt.Printf("Found %d audio files in directory\n", len(audioFiles))
+		fmt.Printf("Found %d audio files in directory for %d slides\n", narrated, len(audioFiles))
 
 		// Parse or infer durations
-		var durations []int
+		var durations []time.Duration
 		if durationsStr == "" {
 			// Infer durations from audio files
 			fmt.Println("No durations provided, inferring from audio files...")
-			durations = make([]int, len(audioFiles))
+			durations = make([]time.Duration, len(audioFiles))
 			for i, audioFile := range audioFiles {
+				if audioFile == "" {
+					return fmt.Errorf("slide %d has no narration to infer its duration from; give every slide's duration with -durations", i+1)
+				}
 				duration, err := audio.GetAudioDuration(audioFile)
 				if err != nil {
 					return fmt.Errorf("failed to get duration for audio file %s: %w", audioFile, err)
 				}
-				// Add 0.5 second buffer to each audio duration
-				durations[i] = int(duration.Seconds() + 0.5)
-				fmt.Printf("  %s: %.2fs (using %.0fs with buffer)\n", filepath.Base(audioFile), duration.Seconds(), duration.Seconds()+0.5)
+				// Add a buffer to each audio duration
+				durations[i] = duration + audioBuffer
+				fmt.Printf("  %s: %.2fs (using %.2fs with buffer)\n", filepath.Base(audioFile), duration.Seconds(), durations[i].Seconds())
 			}
 		} else {
 			// Use provided durations
 			durationStrs := strings.Split(durationsStr, ",")
-			durations = make([]int, len(durationStrs))
+			durations = make([]time.Duration, len(durationStrs))
 			for i, dStr := range durationStrs {
-				d, err := strconv.Atoi(strings.TrimSpace(dStr))
+				d, err := script.ParseDuration(strings.TrimSpace(dStr))
 				if err != nil {
 					return fmt.Errorf("invalid duration at position %d: %w", i+1, err)
 				}
@@ -277,7 +413,7 @@ func runFuseMode(videoPath, audioPath, outputPath, durationsStr string) error {
 
 			// Ensure we have enough durations
 			if len(durations) < len(audioFiles) {
-				return fmt.Errorf("not enough durations provided: have %d durations for %d audio files", len(durations), len(audioFiles))
+				return fmt.Errorf("not enough durations provided: have %d durations for %d slides", len(durations), len(audioFiles))
 			}
 		}
 
@@ -290,16 +426,14 @@ func runFuseMode(videoPath, audioPath, outputPath, durationsStr string) error {
 
 		// Get video duration to use as the single slide duration
 		videoDurationTime, err := audio.GetVideoDuration(videoPath)
-		var videoDuration int
+		videoDuration := videoDurationTime
 		if err != nil {
 			// If we can't get video duration, use a large default
 			fmt.Printf("Warning: Could not get video duration, using default: %v\n", err)
-			videoDuration = 300 // 5 minutes default
-		} else {
-			videoDuration = int(videoDurationTime.Seconds())
+			videoDuration = 5 * time.Minute
 		}
 
 		// For a single audio file, treat it as one slide with the video's duration
-		return merger.MergeAudioWithVideo(videoPath, []string{audioPath}, []int{videoDuration}, outputPath)
+		return merger.MergeAudioWithVideo(videoPath, []string{audioPath}, []time.Duration{videoDuration}, outputPath)
 	}
 }
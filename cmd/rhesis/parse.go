@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// runParse parses a script and prints what the generator will see, either as a
+// summary or as the versioned JSON representation. It returns the process exit
+// code: 0 on success, 1 when the script cannot be parsed and 2 on usage errors.
+func runParse(args []string) int {
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the parsed script as JSON")
+	schema := fs.Bool("schema", false, "Print the JSON Schema of the JSON representation and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rhesis parse [-json] <script-file>")
+		fmt.Fprintln(fs.Output(), "       rhesis parse -schema")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *schema {
+		os.Stdout.Write(script.JSONSchema)
+		return 0
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	parsed, err := script.Load(fs.Arg(0))
+	if err != nil {
+		var parseErr *script.ParseError
+		if errors.As(err, &parseErr) {
+			for _, d := range parseErr.Diagnostics {
+				fmt.Fprintln(os.Stderr, d.String())
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Failed to parse script: %v\n", err)
+		return 1
+	}
+
+	// Resolve slide durations the same way presentation mode does
+	if parsed.Duration > 0 {
+		report, err := parsed.FitDuration()
+		fmt.Fprint(os.Stderr, report.String())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fit slide timings: %v\n", err)
+			return 1
+		}
+	}
+
+	if *jsonOutput {
+		if err := parsed.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode script: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, d := range parsed.Diagnostics {
+		fmt.Println(d.String())
+	}
+	fmt.Printf("%s: %d slide(s), %v\n", parsed.Title, len(parsed.Slides), parsed.GetTotalDuration())
+	for i, slide := range parsed.Slides {
+		fmt.Printf("  %3d. %s (%v)\n", i+1, slide.Title, slide.Duration)
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/audio"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// ttsFlags are the text-to-speech settings given on the command line
+type ttsFlags struct {
+	provider, key, url, voice, model string
+}
+
+// resolveTTS picks the text-to-speech provider, in lower case, and its
+// settings. Flags given on the command line win over the provider's
+// ttsProviders block in the front matter, which wins over the
+// presentation-wide voice and model for ElevenLabs.
+func resolveTTS(s *script.Script, setFlags map[string]bool, f ttsFlags) (string, audio.ProviderConfig) {
+	provider := audio.DefaultProvider
+	if setFlags["tts"] {
+		provider = f.provider
+	} else if s.TTS != "" {
+		provider = s.TTS
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+
+	speech := s.SpeechConfig(provider)
+	config := audio.ProviderConfig{
+		APIKey: f.key,
+		Voice:  speech.Voice,
+		Model:  speech.Model,
+		URL:    speech.URL,
+		Speed:  speech.Speed,
+		Format: speech.Format,
+	}
+	if setFlags["voice"] {
+		config.Voice = f.voice
+	}
+	if setFlags["model"] {
+		config.Model = f.model
+	} else if p, ok := audio.LookupProvider(provider); ok && p.ModelFile && config.Model != "" {
+		config.Model = s.ResolvePath(config.Model)
+	}
+	if setFlags["tts-url"] {
+		config.URL = f.url
+	}
+	return provider, config
+}
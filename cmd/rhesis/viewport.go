@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// applyViewport overrides the viewport front matter of a script with the
+// flags given on the command line and returns the resulting viewport. The
+// generator reads the script, so the HTML layout follows the same viewport
+// the browser is given.
+func applyViewport(s *script.Script, setFlags map[string]bool, resolution, aspect string, deviceScale float64) (script.Viewport, error) {
+	if setFlags["resolution"] || setFlags["aspect"] {
+		// A resolution or aspect ratio on the command line replaces both from the front matter
+		s.Resolution, s.Aspect = resolution, aspect
+	}
+	if setFlags["device-scale"] {
+		s.DeviceScale = deviceScale
+	}
+	return s.Viewport()
+}
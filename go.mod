@@ -2,11 +2,20 @@ module github.com/jmcarbo/rhesis
 
 go 1.24.5
 
-require github.com/playwright-community/playwright-go v0.5200.0
+require (
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/playwright-community/playwright-go v0.5200.0
+	github.com/yuin/goldmark v1.7.12
+	github.com/yuin/goldmark-highlighting/v2 v2.0.0-20230729083705-37449abec8cc
+	go.abhg.dev/goldmark/mermaid v0.5.0
+	golang.org/x/image v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
+	oss.terrastruct.com/d2 v0.7.0
+	oss.terrastruct.com/util-go v0.0.0-20250213174338-243d8661088a
+)
 
 require (
 	github.com/PuerkitoBio/goquery v1.10.0 // indirect
-	github.com/alecthomas/chroma/v2 v2.14.0 // indirect
 	github.com/andybalholm/cascadia v1.3.2 // indirect
 	github.com/coder/websocket v1.8.12 // indirect
 	github.com/deckarep/golang-set/v2 v2.8.0 // indirect
@@ -32,12 +41,8 @@ require (
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/taigrr/elevenlabs v0.1.18 // indirect
-	github.com/yuin/goldmark v1.7.12 // indirect
-	github.com/yuin/goldmark-highlighting/v2 v2.0.0-20230729083705-37449abec8cc // indirect
-	go.abhg.dev/goldmark/mermaid v0.5.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/exp v0.0.0-20240909161429-701f63a606c0 // indirect
-	golang.org/x/image v0.20.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
 	golang.org/x/term v0.29.0 // indirect
@@ -45,6 +50,4 @@ require (
 	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
 	gonum.org/v1/plot v0.14.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	oss.terrastruct.com/d2 v0.7.0 // indirect
-	oss.terrastruct.com/util-go v0.0.0-20250213174338-243d8661088a // indirect
 )
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jmcarbo/rhesis/internal/audio"
 )
@@ -55,7 +56,7 @@ func TestAudioVideoMergingE2E(t *testing.T) {
 	// Test merging to MP4 (should transcode VP8 to H264)
 	t.Run("VP8_to_MP4", func(t *testing.T) {
 		outputPath := filepath.Join(tmpDir, "output.mp4")
-		durations := []int{3, 3} // Two 3-second slides
+		durations := []time.Duration{3 * time.Second, 3 * time.Second} // Two 3-second slides
 
 		err := merger.MergeAudioWithVideo(videoPath, audioFiles, durations, outputPath)
 		if err != nil {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jmcarbo/rhesis/internal/generator"
 	"github.com/jmcarbo/rhesis/internal/script"
@@ -423,11 +424,11 @@ Just transcription
 More content`,
 			expectError: false,
 			validate: func(t *testing.T, s *script.Script) {
-				if s.DefaultTime != 10 {
-					t.Errorf("Expected default time 10, got %d", s.DefaultTime)
+				if s.DefaultTime != 10*time.Second {
+					t.Errorf("Expected default time 10s, got %v", s.DefaultTime)
 				}
-				if s.Slides[0].Duration != 10 {
-					t.Errorf("Expected slide duration 10, got %d", s.Slides[0].Duration)
+				if s.Slides[0].Duration != 10*time.Second {
+					t.Errorf("Expected slide duration 10s, got %v", s.Slides[0].Duration)
 				}
 			},
 		},
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,6 +24,8 @@ type ElevenLabsConfig struct {
 	APIKey  string
 	VoiceID string
 	ModelID string
+	// BaseURL replaces https://api.elevenlabs.io, such as for a proxy
+	BaseURL string
 }
 
 type ElevenLabsGenerator struct {
@@ -37,6 +40,9 @@ func NewElevenLabsGenerator(config ElevenLabsConfig) *ElevenLabsGenerator {
 	if config.ModelID == "" {
 		config.ModelID = "eleven_multilingual_v2"
 	}
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.elevenlabs.io"
+	}
 
 	return &ElevenLabsGenerator{
 		config: config,
@@ -78,7 +84,7 @@ func (g *ElevenLabsGenerator) GenerateAudio(text string, outputPath string) (tim
 	}
 
 	// Create HTTP request
-	url := fmt.Sprintf("https://api.elevenlabs.io/v1/text-to-speech/%s", g.config.VoiceID)
+	url := fmt.Sprintf("%s/v1/text-to-speech/%s", strings.TrimSuffix(g.config.BaseURL, "/"), g.config.VoiceID)
 	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request: %w", err)
@@ -97,7 +103,7 @@ func (g *ElevenLabsGenerator) GenerateAudio(text string, outputPath string) (tim
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("ElevenLabs API error (status %d): %s", resp.StatusCode, string(body))
+		return 0, newAPIError("ElevenLabs", resp, body)
 	}
 
 	// Ensure output directory exists
@@ -122,6 +128,11 @@ func (g *ElevenLabsGenerator) GenerateAudio(text string, outputPath string) (tim
 		return 0, fmt.Errorf("failed to write audio file: %w", err)
 	}
 
+	return estimateDuration(text), nil
+}
+
+// estimateDuration estimates how long text takes to speak
+func estimateDuration(text string) time.Duration {
 	// Estimate duration based on text length and average speech rate
 	// Average speech rate is about 150 words per minute
 	// This is a rough estimate; for accurate duration, we'd need to decode the MP3
@@ -134,11 +145,30 @@ func (g *ElevenLabsGenerator) GenerateAudio(text string, outputPath string) (tim
 		duration = time.Second
 	}
 
-	return duration, nil
+	return duration
 }
 
-// GetAudioDuration gets the actual duration of an audio file using ffmpeg
+// GetAudioDuration gets the actual duration of an audio file using ffmpeg, or
+// from the header of WAV files, and estimates it from the file size otherwise
 func GetAudioDuration(filePath string) (time.Duration, error) {
+	if duration, ok := measureAudioDuration(filePath); ok {
+		return duration, nil
+	}
+
+	// Fall back to a file size estimate if the duration cannot be measured
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("could not extract duration from audio file and stat failed: %w", err)
+	}
+
+	// Rough estimate: file size in bytes / (128000 bits/sec / 8 bits/byte)
+	seconds := float64(info.Size()) / 16000.0
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// measureAudioDuration reads the duration of an audio file with ffmpeg, or
+// from the header of WAV files. Unlike GetAudioDuration it never estimates.
+func measureAudioDuration(filePath string) (time.Duration, bool) {
 	cmd := exec.Command("ffmpeg", "-i", filePath)
 	output, _ := cmd.CombinedOutput()
 	outputStr := string(output)
@@ -150,16 +180,11 @@ func GetAudioDuration(filePath string) (time.Duration, error) {
 		minutes, _ := strconv.ParseFloat(matches[2], 64)
 		seconds, _ := strconv.ParseFloat(matches[3], 64)
 		totalSeconds := hours*3600 + minutes*60 + seconds
-		return time.Duration(totalSeconds * float64(time.Second)), nil
+		return time.Duration(totalSeconds * float64(time.Second)), true
 	}
 
-	// Fall back to file size estimate if ffmpeg fails
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return 0, fmt.Errorf("could not extract duration from audio file and stat failed: %w", err)
+	if duration, err := WAVDuration(filePath); err == nil {
+		return duration, true
 	}
-
-	// Rough estimate: file size in bytes / (128000 bits/sec / 8 bits/byte)
-	seconds := float64(info.Size()) / 16000.0
-	return time.Duration(seconds * float64(time.Second)), nil
+	return 0, false
 }
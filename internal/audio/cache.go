@@ -0,0 +1,282 @@
+package audio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestFile is the name of the narration cache manifest
+const ManifestFile = "manifest.json"
+
+// manifestVersion is bumped when cached narration can no longer be reused
+const manifestVersion = 1
+
+// Manifest describes the narration in a cache directory
+type Manifest struct {
+	Version int `json:"version"`
+	// Entries holds the cached narration by key; see NarrationKey
+	Entries map[string]ManifestEntry `json:"entries"`
+	// Slides lists the key of every slide narrated by the last run, in
+	// order, with an empty key for slides without narration
+	Slides []string `json:"slides,omitempty"`
+}
+
+// ManifestEntry is one cached narration
+type ManifestEntry struct {
+	File     string    `json:"file"`
+	Provider string    `json:"provider"`
+	Voice    string    `json:"voice,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Format   string    `json:"format,omitempty"`
+	Text     string    `json:"text"`
+	Duration float64   `json:"duration"`
+	Created  time.Time `json:"created"`
+}
+
+// Narration is the audio of one slide
+type Narration struct {
+	Path     string
+	Duration time.Duration
+	// Cached is set when the audio was reused rather than generated
+	Cached bool
+}
+
+// NarrationCache stores narration under a hash of what it was generated
+// from, so inserting, moving or removing slides reuses the audio of every
+// other slide, and editing a transcription regenerates only that slide. It
+// is safe for concurrent use.
+type NarrationCache struct {
+	dir string
+
+	mu       sync.Mutex
+	manifest Manifest
+}
+
+// OpenNarrationCache opens the cache in dir, reading its manifest if there is
+// one. The directory is created when narration is first stored.
+func OpenNarrationCache(dir string) (*NarrationCache, error) {
+	c := &NarrationCache{
+		dir:      dir,
+		manifest: Manifest{Version: manifestVersion, Entries: map[string]ManifestEntry{}},
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read narration manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid narration manifest %s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	// Narration from an older layout is regenerated
+	if manifest.Version == manifestVersion && manifest.Entries != nil {
+		c.manifest = manifest
+	}
+	return c, nil
+}
+
+// NormalizeText collapses the whitespace of a transcription, so reflowing it
+// does not invalidate its narration
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// NarrationKey identifies the narration of text by the provider and every
+// setting that changes how it sounds. The API key is left out.
+func NarrationKey(provider string, config ProviderConfig, text string) string {
+	data, _ := json.Marshal(struct {
+		Version                   int
+		Provider                  string
+		Voice, Model, URL, Format string
+		Speed                     float64
+		Text                      string
+	}{manifestVersion, strings.ToLower(provider), config.Voice, config.Model, config.URL, config.Format, config.Speed, NormalizeText(text)})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the cached narration for key, if its audio file still exists
+func (c *NarrationCache) Lookup(key string) (Narration, bool) {
+	c.mu.Lock()
+	entry, ok := c.manifest.Entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return Narration{}, false
+	}
+
+	path := filepath.Join(c.dir, entry.File)
+	if _, err := os.Stat(path); err != nil {
+		return Narration{}, false
+	}
+	return Narration{Path: path, Duration: seconds(entry.Duration), Cached: true}, true
+}
+
+// Find returns cached narration of text made with any provider and voice,
+// preferring narration the last run used, then the newest. It lets tools
+// that do not know the provider settings, such as exporters, match slides to
+// their audio by content rather than by position.
+func (c *NarrationCache) Find(text string) (Narration, bool) {
+	text = NormalizeText(text)
+	if text == "" {
+		return Narration{}, false
+	}
+
+	c.mu.Lock()
+	used := make(map[string]bool, len(c.manifest.Slides))
+	for _, key := range c.manifest.Slides {
+		used[key] = true
+	}
+	var keys []string
+	for key, entry := range c.manifest.Entries {
+		if entry.Text == text {
+			keys = append(keys, key)
+		}
+	}
+	entries := c.manifest.Entries
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if used[a] != used[b] {
+			return used[a]
+		}
+		if !entries[a].Created.Equal(entries[b].Created) {
+			return entries[a].Created.After(entries[b].Created)
+		}
+		return a < b
+	})
+	c.mu.Unlock()
+
+	for _, key := range keys {
+		if narration, ok := c.Lookup(key); ok {
+			return narration, true
+		}
+	}
+	return Narration{}, false
+}
+
+// Generate narrates text with gen, stores it under key and saves the
+// manifest. The file is written in the provider's format for config; see
+// Provider.FileFormat. Unless exact is set, the duration gen reports is
+// replaced with the one measured from the audio file, when it can be measured
+// rather than estimated.
+func (c *NarrationCache) Generate(key, provider string, config ProviderConfig, text string, gen Generator, exact bool) (Narration, error) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return Narration{}, fmt.Errorf("failed to create narration cache: %w", err)
+	}
+
+	// Generate next to the final file and move it in place, so an
+	// interrupted run never leaves a truncated file under a valid key
+	format := fileFormat(provider, config)
+	file := key + "." + format
+	path := filepath.Join(c.dir, file)
+	f, err := os.CreateTemp(c.dir, key+".*.partial."+format)
+	if err != nil {
+		return Narration{}, fmt.Errorf("failed to create narration file: %w", err)
+	}
+	f.Close()
+	partial := f.Name()
+	defer os.Remove(partial)
+
+	duration, err := gen.GenerateAudio(NormalizeText(text), partial)
+	if err != nil {
+		return Narration{}, err
+	}
+	if !exact {
+		if measured, ok := measureAudioDuration(partial); ok {
+			duration = measured
+		}
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return Narration{}, fmt.Errorf("failed to store narration: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifest.Entries[key] = ManifestEntry{
+		File:     file,
+		Provider: strings.ToLower(provider),
+		Voice:    config.Voice,
+		Model:    config.Model,
+		Format:   format,
+		Text:     NormalizeText(text),
+		Duration: duration.Seconds(),
+		Created:  time.Now().UTC().Truncate(time.Second),
+	}
+	if err := c.save(); err != nil {
+		return Narration{}, err
+	}
+	return Narration{Path: path, Duration: duration}, nil
+}
+
+// fileFormat returns the format narration by provider is stored in, mp3 for
+// providers that are not registered
+func fileFormat(provider string, config ProviderConfig) string {
+	if p, ok := LookupProvider(provider); ok {
+		return p.FileFormat(config)
+	}
+	return "mp3"
+}
+
+// SetSlides records the key of every slide, in order, and saves the manifest
+func (c *NarrationCache) SetSlides(keys []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifest.Slides = keys
+	return c.save()
+}
+
+// Slides returns the narration of every slide recorded by SetSlides, with an
+// empty Path for slides without narration
+func (c *NarrationCache) Slides() []Narration {
+	c.mu.Lock()
+	keys := c.manifest.Slides
+	c.mu.Unlock()
+
+	narrations := make([]Narration, len(keys))
+	for i, key := range keys {
+		if key == "" {
+			continue
+		}
+		if narration, ok := c.Lookup(key); ok {
+			narrations[i] = narration
+		}
+	}
+	return narrations
+}
+
+// save writes the manifest; the caller holds c.mu
+func (c *NarrationCache) save() error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create narration cache: %w", err)
+	}
+	data, err := json.MarshalIndent(c.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode narration manifest: %w", err)
+	}
+
+	path := filepath.Join(c.dir, ManifestFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write narration manifest: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write narration manifest: %w", err)
+	}
+	return nil
+}
+
+// seconds converts a manifest duration to a time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
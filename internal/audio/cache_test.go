@@ -0,0 +1,254 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingGenerator writes the text it is given and counts its calls
+type countingGenerator struct {
+	calls int
+}
+
+func (g *countingGenerator) GenerateAudio(text string, outputPath string) (time.Duration, error) {
+	g.calls++
+	if err := os.WriteFile(outputPath, []byte(text), 0644); err != nil {
+		return 0, err
+	}
+	return time.Duration(len(text)) * 100 * time.Millisecond, nil
+}
+
+func TestNarrationKey(t *testing.T) {
+	config := ProviderConfig{Voice: "nova", Model: "tts-1"}
+	key := NarrationKey("openai", config, "Hello  world,\n  again")
+
+	if got := NarrationKey("OpenAI", ProviderConfig{APIKey: "secret", Voice: "nova", Model: "tts-1"}, "Hello world, again"); got != key {
+		t.Error("Expected the key to ignore whitespace, provider case and the API key")
+	}
+
+	for name, other := range map[string]string{
+		"text":     NarrationKey("openai", config, "Hello world, again!"),
+		"provider": NarrationKey("elevenlabs", config, "Hello world, again"),
+		"voice":    NarrationKey("openai", ProviderConfig{Voice: "echo", Model: "tts-1"}, "Hello world, again"),
+		"speed":    NarrationKey("openai", ProviderConfig{Voice: "nova", Model: "tts-1", Speed: 1.5}, "Hello world, again"),
+	} {
+		if other == key {
+			t.Errorf("Expected a different %s to change the key", name)
+		}
+	}
+}
+
+func TestNarrationCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "presentation_audio")
+	config := ProviderConfig{Voice: "nova"}
+	gen := &countingGenerator{}
+
+	narrate := func(cache *NarrationCache, texts ...string) []string {
+		t.Helper()
+		keys := make([]string, len(texts))
+		for i, text := range texts {
+			keys[i] = NarrationKey("openai", config, text)
+			if _, ok := cache.Lookup(keys[i]); ok {
+				continue
+			}
+			if _, err := cache.Generate(keys[i], "openai", config, text, gen, true); err != nil {
+				t.Fatalf("Failed to generate narration: %v", err)
+			}
+		}
+		if err := cache.SetSlides(keys); err != nil {
+			t.Fatalf("Failed to save slides: %v", err)
+		}
+		return keys
+	}
+
+	cache, err := OpenNarrationCache(dir)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	narrate(cache, "First", "Second", "Third")
+	if gen.calls != 3 {
+		t.Fatalf("Expected 3 generations, got %d", gen.calls)
+	}
+
+	// A new run inserts a slide, reorders two and edits one
+	cache, err = OpenNarrationCache(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen cache: %v", err)
+	}
+	narrate(cache, "Inserted", "Third", "First", "Second, edited")
+	if gen.calls != 5 {
+		t.Errorf("Expected only the inserted and edited slides to be generated, got %d generations", gen.calls)
+	}
+
+	slides := cache.Slides()
+	if len(slides) != 4 {
+		t.Fatalf("Expected 4 slides, got %d", len(slides))
+	}
+	for i, want := range []string{"Inserted", "Third", "First", "Second, edited"} {
+		data, err := os.ReadFile(slides[i].Path)
+		if err != nil || string(data) != want {
+			t.Errorf("Slide %d: expected narration %q, got %q (%v)", i+1, want, data, err)
+		}
+		if slides[i].Duration != time.Duration(len(want))*100*time.Millisecond {
+			t.Errorf("Slide %d: expected the generated duration, got %v", i+1, slides[i].Duration)
+		}
+	}
+
+	// Deleted audio is regenerated rather than reused
+	os.Remove(slides[0].Path)
+	if _, ok := cache.Lookup(NarrationKey("openai", config, "Inserted")); ok {
+		t.Error("Expected narration with a missing file not to be found")
+	}
+
+	if entries, _ := filepath.Glob(filepath.Join(dir, "*.partial.mp3")); len(entries) > 0 {
+		t.Errorf("Expected no partial files, got %v", entries)
+	}
+}
+
+func TestNarrationCacheFormat(t *testing.T) {
+	tests := []struct {
+		provider string
+		config   ProviderConfig
+		ext      string
+	}{
+		{"piper", ProviderConfig{Model: "voice.onnx"}, ".wav"},
+		{"piper", ProviderConfig{Model: "voice.onnx", Format: "mp3"}, ".mp3"},
+		{"openai", ProviderConfig{Format: "FLAC"}, ".flac"},
+		{"elevenlabs", ProviderConfig{Format: "wav"}, ".mp3"},
+		{"unregistered", ProviderConfig{}, ".mp3"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		cache, err := OpenNarrationCache(dir)
+		if err != nil {
+			t.Fatalf("Failed to open cache: %v", err)
+		}
+		key := NarrationKey(tt.provider, tt.config, "Text")
+		narration, err := cache.Generate(key, tt.provider, tt.config, "Text", &countingGenerator{}, true)
+		if err != nil {
+			t.Fatalf("Failed to generate narration: %v", err)
+		}
+		if filepath.Ext(narration.Path) != tt.ext {
+			t.Errorf("%s %+v: expected a %s file, got %s", tt.provider, tt.config, tt.ext, narration.Path)
+		}
+
+		reopened, err := OpenNarrationCache(dir)
+		if err != nil {
+			t.Fatalf("Failed to reopen cache: %v", err)
+		}
+		if found, ok := reopened.Lookup(key); !ok || found.Path != narration.Path {
+			t.Errorf("%s: expected the cached file to be found again, got %q", tt.provider, found.Path)
+		}
+	}
+}
+
+func TestNarrationCacheFind(t *testing.T) {
+	cache, err := OpenNarrationCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+
+	generate := func(voice, text string) (string, Narration) {
+		t.Helper()
+		config := ProviderConfig{Voice: voice}
+		key := NarrationKey("openai", config, text)
+		narration, err := cache.Generate(key, "openai", config, text, &countingGenerator{}, true)
+		if err != nil {
+			t.Fatalf("Failed to generate narration: %v", err)
+		}
+		return key, narration
+	}
+	nova, _ := generate("nova", "Hello world")
+	_, echo := generate("echo", "Hello world")
+	if err := cache.SetSlides([]string{nova}); err != nil {
+		t.Fatalf("Failed to save slides: %v", err)
+	}
+
+	// The narration of the last run wins over other voices
+	found, ok := cache.Find("Hello\n  world")
+	if !ok || found.Path == echo.Path {
+		t.Errorf("Expected the narration used by the last run, got %q", found.Path)
+	}
+	if _, ok := cache.Find("Hello world, edited"); ok {
+		t.Error("Expected no narration for edited text")
+	}
+	if _, ok := cache.Find(""); ok {
+		t.Error("Expected no narration without text")
+	}
+}
+
+// wavGenerator writes a WAV file of a fixed length and reports a wrong duration
+type wavGenerator struct {
+	t        *testing.T
+	duration time.Duration
+}
+
+func (g *wavGenerator) GenerateAudio(text string, outputPath string) (time.Duration, error) {
+	writeWAV(g.t, outputPath, 16000, g.duration)
+	return time.Minute, nil
+}
+
+func TestNarrationCacheMeasuredDuration(t *testing.T) {
+	cache, err := OpenNarrationCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	config := ProviderConfig{Format: "wav"}
+
+	// WAV files are measured from their header, with or without ffmpeg
+	wav := &wavGenerator{t: t, duration: 2 * time.Second}
+	narration, err := cache.Generate(NarrationKey("openai", config, "Measured"), "openai", config, "Measured", wav, false)
+	if err != nil {
+		t.Fatalf("Failed to generate narration: %v", err)
+	}
+	if narration.Duration != 2*time.Second {
+		t.Errorf("Expected the duration of the WAV file, got %v", narration.Duration)
+	}
+
+	// Audio that cannot be measured keeps the duration the generator reported
+	// instead of an estimate from its size
+	narration, err = cache.Generate(NarrationKey("openai", config, "Unmeasured"), "openai", config, "Unmeasured", &countingGenerator{}, false)
+	if err != nil {
+		t.Fatalf("Failed to generate narration: %v", err)
+	}
+	if narration.Duration != time.Duration(len("Unmeasured"))*100*time.Millisecond {
+		t.Errorf("Expected the generated duration, got %v", narration.Duration)
+	}
+}
+
+func TestNarrationCacheFailedGeneration(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := OpenNarrationCache(dir)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+
+	key := NarrationKey("elevenlabs", ProviderConfig{}, "Text")
+	if _, err := cache.Generate(key, "elevenlabs", ProviderConfig{}, "Text", failingGenerator{}, true); err == nil {
+		t.Fatal("Expected the generator error")
+	}
+	if _, ok := cache.Lookup(key); ok {
+		t.Error("Expected failed narration not to be cached")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no files left behind, got %d", len(entries))
+	}
+}
+
+func TestOpenNarrationCacheInvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ManifestFile), []byte("{not json"), 0644)
+	if _, err := OpenNarrationCache(dir); err == nil {
+		t.Error("Expected an error for an invalid manifest")
+	}
+}
+
+type failingGenerator struct{}
+
+func (failingGenerator) GenerateAudio(text string, outputPath string) (time.Duration, error) {
+	return 0, fmt.Errorf("provider unavailable")
+}
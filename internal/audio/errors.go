@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is an unsuccessful response from a text-to-speech API
+type APIError struct {
+	// API names the service in the message, such as ElevenLabs
+	API        string
+	StatusCode int
+	Body       string
+	// RetryAfter is how long the server asked to wait before retrying, or zero
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.API, e.StatusCode, e.Body)
+}
+
+// newAPIError builds an APIError from a response whose body was read
+func newAPIError(api string, resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		API:        api,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as
+// an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// retryable reports whether a generation error is likely to go away on its
+// own: rate limits, server errors and network failures. It also returns how
+// long the server asked to wait, if it did.
+func retryable(err error) (bool, time.Duration) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode >= 500:
+			return true, apiErr.RetryAfter
+		}
+		return false, 0
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr), 0
+}
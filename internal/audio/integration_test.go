@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestAudioVideoMergerIntegration(t *testing.T) {
@@ -57,7 +58,7 @@ func TestAudioVideoMergerIntegration(t *testing.T) {
 
 			// Create test audio files
 			audioFiles := []string{}
-			durations := []int{5, 5, 5} // 3 slides, 5 seconds each
+			durations := []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second} // 3 slides, 5 seconds each
 
 			for i := 0; i < 3; i++ {
 				audioPath := filepath.Join(tmpDir, fmt.Sprintf("audio_%d.mp3", i))
@@ -185,7 +186,7 @@ func TestCreateTimedAudioTrack(t *testing.T) {
 	}
 
 	// Test with different slide durations
-	slideDurations := []int{5, 3, 4} // First and third longer than audio, second has no audio
+	slideDurations := []time.Duration{5 * time.Second, 3 * time.Second, 4 * time.Second} // First and third longer than audio, second has no audio
 	outputPath := filepath.Join(tmpDir, "concatenated.mp3")
 
 	err := merger.createTimedAudioTrack(audioFiles, slideDurations, outputPath)
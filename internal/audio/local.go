@@ -0,0 +1,219 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local text-to-speech engines
+const (
+	EnginePiper  = "piper"
+	EngineEspeak = "espeak"
+)
+
+// LocalConfig configures a text-to-speech engine installed on the machine
+type LocalConfig struct {
+	// Engine is EnginePiper or EngineEspeak
+	Engine string
+	// Binary is the engine executable; empty looks it up in PATH
+	Binary string
+	// Voice is an espeak-ng voice such as en-us, or a Piper speaker ID for
+	// models with several speakers
+	Voice string
+	// Model is the Piper voice model (.onnx); espeak-ng ignores it
+	Model string
+}
+
+// LocalGenerator narrates text with Piper or espeak-ng, without network
+// access. Both write WAV, which is converted with ffmpeg for any other
+// output format.
+type LocalGenerator struct {
+	config LocalConfig
+}
+
+// NewLocalGenerator creates a generator for a local engine
+func NewLocalGenerator(config LocalConfig) (*LocalGenerator, error) {
+	switch config.Engine {
+	case EnginePiper:
+		if config.Model == "" {
+			return nil, fmt.Errorf("piper needs a voice model (.onnx): set -model or model under ttsProviders.piper")
+		}
+	case EngineEspeak:
+	default:
+		return nil, fmt.Errorf("unknown local text-to-speech engine %q", config.Engine)
+	}
+	return &LocalGenerator{config: config}, nil
+}
+
+// GenerateAudio writes the narration of text to outputPath and returns its
+// exact duration, read from the WAV the engine produced
+func (g *LocalGenerator) GenerateAudio(text string, outputPath string) (time.Duration, error) {
+	binary, err := g.binary()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	wavPath := outputPath
+	if !strings.EqualFold(filepath.Ext(outputPath), ".wav") {
+		tempDir, err := os.MkdirTemp("", "rhesis_tts_*")
+		if err != nil {
+			return 0, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+		wavPath = filepath.Join(tempDir, "narration.wav")
+	}
+
+	cmd := exec.Command(binary, g.args(wavPath)...)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("%s failed: %w\nOutput: %s", g.config.Engine, err, string(output))
+	}
+
+	duration, err := WAVDuration(wavPath)
+	if err != nil {
+		return 0, fmt.Errorf("%s wrote an unreadable WAV file: %w", g.config.Engine, err)
+	}
+
+	if wavPath != outputPath {
+		if err := convertAudio(wavPath, outputPath); err != nil {
+			return 0, err
+		}
+	}
+	return duration, nil
+}
+
+// binary returns the engine executable
+func (g *LocalGenerator) binary() (string, error) {
+	if g.config.Binary != "" {
+		return g.config.Binary, nil
+	}
+	names := []string{"piper"}
+	if g.config.Engine == EngineEspeak {
+		names = []string{"espeak-ng", "espeak"}
+	}
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in PATH. Please install %s to narrate offline", names[0], names[0])
+}
+
+// args returns the engine arguments that read text from stdin and write it
+// spoken to wavPath
+func (g *LocalGenerator) args(wavPath string) []string {
+	if g.config.Engine == EnginePiper {
+		args := []string{"--model", g.config.Model, "--output_file", wavPath}
+		if g.config.Voice != "" {
+			args = append(args, "--speaker", g.config.Voice)
+		}
+		return args
+	}
+
+	args := []string{"-w", wavPath}
+	if g.config.Voice != "" {
+		args = append(args, "-v", g.config.Voice)
+	}
+	return append(args, "--stdin")
+}
+
+// convertAudio re-encodes a WAV file into the format of outputPath with ffmpeg
+func convertAudio(wavPath, outputPath string) error {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("ffmpeg not found in PATH. Please install ffmpeg to write %s, or write .wav files", filepath.Ext(outputPath))
+	}
+	cmd := exec.Command(ffmpegPath, "-y", "-i", wavPath, outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to convert narration: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// WAVDuration returns the duration of a WAV file from its header, without
+// decoding it
+func WAVDuration(path string) (time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("not a WAV file")
+	}
+
+	var byteRate uint32
+	r := bytes.NewReader(data[12:])
+	for {
+		var header struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return 0, fmt.Errorf("no data chunk")
+			}
+			return 0, err
+		}
+
+		switch string(header.ID[:]) {
+		case "fmt ":
+			var format struct {
+				AudioFormat, Channels uint16
+				SampleRate, ByteRate  uint32
+			}
+			if err := binary.Read(r, binary.LittleEndian, &format); err != nil {
+				return 0, fmt.Errorf("truncated fmt chunk")
+			}
+			if header.Size < 12 {
+				return 0, fmt.Errorf("truncated fmt chunk")
+			}
+			byteRate = format.ByteRate
+			header.Size -= 12
+		case "data":
+			if byteRate == 0 {
+				return 0, fmt.Errorf("data chunk before fmt chunk")
+			}
+			// Engines streaming to a pipe cannot know the size up front
+			size := int64(header.Size)
+			if remaining := int64(r.Len()); header.Size == 0 || header.Size == 0xFFFFFFFF || size > remaining {
+				size = remaining
+			}
+			return time.Duration(size * int64(time.Second) / int64(byteRate)), nil
+		}
+
+		// Chunks are padded to an even size
+		if _, err := r.Seek(int64(header.Size)+int64(header.Size%2), io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func init() {
+	for _, engine := range []string{EnginePiper, EngineEspeak} {
+		Register(Provider{
+			Name:      engine,
+			ModelFile: engine == EnginePiper,
+			Exact:     true,
+			// The engines write WAV; MP3 is converted with ffmpeg
+			Formats: []string{"wav", "mp3"},
+			New: func(config ProviderConfig) (Generator, error) {
+				return NewLocalGenerator(LocalConfig{
+					Engine: engine,
+					Voice:  config.Voice,
+					Model:  config.Model,
+				})
+			},
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeWAV writes a silent 16-bit mono WAV file lasting duration, with a
+// LIST chunk before the data as some encoders write
+func writeWAV(t *testing.T, path string, sampleRate int, duration time.Duration) {
+	t.Helper()
+	samples := make([]byte, int(duration.Seconds()*float64(sampleRate))*2)
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(4+8+16+8+4+8+len(samples)))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{uint32(16), uint16(1), uint16(1), uint32(sampleRate), uint32(sampleRate * 2), uint16(2), uint16(16)} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("LIST")
+	binary.Write(&b, binary.LittleEndian, uint32(3))
+	b.WriteString("abc\x00")
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(samples)))
+	b.Write(samples)
+
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWAVDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "narration.wav")
+	writeWAV(t, path, 22050, 1500*time.Millisecond)
+
+	duration, err := WAVDuration(path)
+	if err != nil {
+		t.Fatalf("Failed to read WAV duration: %v", err)
+	}
+	if duration != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s, got %v", duration)
+	}
+
+	notWAV := filepath.Join(tmpDir, "narration.mp3")
+	os.WriteFile(notWAV, []byte("ID3 not a wav file"), 0644)
+	if _, err := WAVDuration(notWAV); err == nil {
+		t.Error("Expected an error for a file that is not WAV")
+	}
+}
+
+func TestNewLocalGenerator(t *testing.T) {
+	if _, err := NewLocalGenerator(LocalConfig{Engine: EnginePiper}); err == nil {
+		t.Error("Expected piper without a model to fail")
+	}
+	if _, err := NewLocalGenerator(LocalConfig{Engine: "festival"}); err == nil {
+		t.Error("Expected an unknown engine to fail")
+	}
+
+	for _, name := range []string{EnginePiper, EngineEspeak} {
+		if _, ok := LookupProvider(name); !ok {
+			t.Errorf("Expected %s to be registered", name)
+		}
+	}
+}
+
+func TestLocalGeneratorArgs(t *testing.T) {
+	piper := &LocalGenerator{config: LocalConfig{Engine: EnginePiper, Model: "en_US-lessac-medium.onnx", Voice: "3"}}
+	if got := strings.Join(piper.args("out.wav"), " "); got != "--model en_US-lessac-medium.onnx --output_file out.wav --speaker 3" {
+		t.Errorf("Unexpected piper arguments: %s", got)
+	}
+
+	espeak := &LocalGenerator{config: LocalConfig{Engine: EngineEspeak, Voice: "en-us"}}
+	if got := strings.Join(espeak.args("out.wav"), " "); got != "-w out.wav -v en-us --stdin" {
+		t.Errorf("Unexpected espeak-ng arguments: %s", got)
+	}
+}
+
+func TestLocalGenerateAudio(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake engine is a shell script")
+	}
+
+	tmpDir := t.TempDir()
+	fixture := filepath.Join(tmpDir, "fixture.wav")
+	writeWAV(t, fixture, 16000, 2250*time.Millisecond)
+
+	// The fake engine copies the fixture to the file after -w and keeps the text it reads
+	engine := filepath.Join(tmpDir, "espeak-ng")
+	script := "#!/bin/sh\ncat > " + filepath.Join(tmpDir, "text.txt") + "\ncp " + fixture + " \"$2\"\n"
+	if err := os.WriteFile(engine, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	gen, err := NewLocalGenerator(LocalConfig{Engine: EngineEspeak, Binary: engine})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	outputPath := filepath.Join(tmpDir, "audio", "slide_01.wav")
+	duration, err := gen.GenerateAudio("Hello offline world", outputPath)
+	if err != nil {
+		t.Fatalf("Failed to generate audio: %v", err)
+	}
+	if duration != 2250*time.Millisecond {
+		t.Errorf("Expected the exact WAV duration of 2.25s, got %v", duration)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("Expected %s to be written: %v", outputPath, err)
+	}
+	if text, _ := os.ReadFile(filepath.Join(tmpDir, "text.txt")); string(text) != "Hello offline world" {
+		t.Errorf("Expected the text on stdin, got %q", text)
+	}
+}
@@ -42,8 +42,9 @@ func GetVideoDuration(videoPath string) (time.Duration, error) {
 	return 0, fmt.Errorf("could not extract duration from video file")
 }
 
-// MergeAudioWithVideo merges audio files with a video recording based on slide timings
-func (m *AudioVideoMerger) MergeAudioWithVideo(videoPath string, audioFiles []string, slideDurations []int, outputPath string) error {
+// MergeAudioWithVideo merges audio files with a video recording based on slide
+// timings. audioFiles holds one file per slide, "" for silent slides.
+func (m *AudioVideoMerger) MergeAudioWithVideo(videoPath string, audioFiles []string, slideDurations []time.Duration, outputPath string) error {
 	// Check if ffmpeg is available
 	if err := m.checkFFmpeg(); err != nil {
 		return fmt.Errorf("ffmpeg not available: %w", err)
@@ -70,6 +71,51 @@ func (m *AudioVideoMerger) MergeAudioWithVideo(videoPath string, audioFiles []st
 	return nil
 }
 
+// MergeAudioWithTimedVideo merges audio files with a video whose timeline
+// matches the slide durations exactly, as segmented and rendered recordings
+// do. Unlike MergeAudioWithVideo it does not guess a start offset or adjust
+// the video speed: every slide's narration starts with its slide.
+func (m *AudioVideoMerger) MergeAudioWithTimedVideo(videoPath string, audioFiles []string, slideDurations []time.Duration, outputPath string) error {
+	if err := m.checkFFmpeg(); err != nil {
+		return fmt.Errorf("ffmpeg not available: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "rhesis_merge_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	concatAudioPath := filepath.Join(tempDir, "concatenated_audio.mp3")
+	if err := m.createTimedAudioTrack(audioFiles, slideDurations, concatAudioPath); err != nil {
+		return fmt.Errorf("failed to create timed audio track: %w", err)
+	}
+
+	var total time.Duration
+	for _, d := range slideDurations {
+		total += d
+	}
+
+	cmd := exec.Command(m.ffmpegPath, timedMergeArgs(videoPath, concatAudioPath, outputPath, total)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg merge failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// timedMergeArgs returns the ffmpeg arguments that add an audio track to a
+// video of the given length without re-encoding the video
+func timedMergeArgs(videoPath, audioPath, outputPath string, total time.Duration) []string {
+	args := []string{"-y", "-i", videoPath, "-i", audioPath, "-map", "0:v:0", "-map", "1:a:0", "-c:v", "copy"}
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".webm":
+		args = append(args, "-c:a", "libopus", "-b:a", "128k")
+	default:
+		args = append(args, "-c:a", "aac", "-b:a", "192k")
+	}
+	return append(args, "-t", fmt.Sprintf("%.3f", total.Seconds()), outputPath)
+}
+
 // checkFFmpeg verifies that ffmpeg is available
 func (m *AudioVideoMerger) checkFFmpeg() error {
 	cmd := exec.Command(m.ffmpegPath, "-version")
@@ -80,7 +126,21 @@ func (m *AudioVideoMerger) checkFFmpeg() error {
 }
 
 // createTimedAudioTrack creates a single audio file with proper timing for each slide
-func (m *AudioVideoMerger) createTimedAudioTrack(audioFiles []string, slideDurations []int, outputPath string) error {
+func (m *AudioVideoMerger) createTimedAudioTrack(audioFiles []string, slideDurations []time.Duration, outputPath string) error {
+	args := timedAudioArgs(audioFiles, slideDurations, GetAudioDuration, outputPath)
+	cmd := exec.Command(m.ffmpegPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg audio concatenation failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// timedAudioArgs returns the ffmpeg arguments that join the audio of every
+// slide into one track, padding or trimming each file to its slide's
+// duration. Slides without an audio file ("") are silent.
+func timedAudioArgs(audioFiles []string, slideDurations []time.Duration, audioDuration func(string) (time.Duration, error), outputPath string) []string {
 	// Create a complex filter to concatenate audio with silence padding
 	var filterParts []string
 	var inputs []string
@@ -92,14 +152,14 @@ func (m *AudioVideoMerger) createTimedAudioTrack(audioFiles []string, slideDurat
 			inputs = append(inputs, "-i", audioFiles[i])
 
 			// Add silence padding if needed
-			audioDuration, err := GetAudioDuration(audioFiles[i])
+			fileDuration, err := audioDuration(audioFiles[i])
 			if err != nil {
 				// If we can't get duration, use the slide duration
-				audioDuration = time.Duration(duration) * time.Second
+				fileDuration = duration
 			}
 
-			audioSeconds := audioDuration.Seconds()
-			slideSeconds := float64(duration)
+			audioSeconds := fileDuration.Seconds()
+			slideSeconds := duration.Seconds()
 
 			fmt.Printf("Slide %d: audio=%.2fs, slide=%.2fs\n", i+1, audioSeconds, slideSeconds)
 
@@ -124,7 +184,7 @@ func (m *AudioVideoMerger) createTimedAudioTrack(audioFiles []string, slideDurat
 		} else {
 			// No audio for this slide, create silence
 			filterParts = append(filterParts,
-				fmt.Sprintf("anullsrc=duration=%d:sample_rate=44100:channel_layout=stereo[a%d]", duration, i))
+				fmt.Sprintf("anullsrc=duration=%.3f:sample_rate=44100:channel_layout=stereo[a%d]", duration.Seconds(), i))
 		}
 	}
 
@@ -144,15 +204,7 @@ func (m *AudioVideoMerger) createTimedAudioTrack(audioFiles []string, slideDurat
 	args = append(args, "-map", "[out]")
 	args = append(args, "-codec:a", "libmp3lame")
 	args = append(args, "-b:a", "192k")
-	args = append(args, outputPath)
-
-	cmd := exec.Command(m.ffmpegPath, args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("ffmpeg audio concatenation failed: %w\nOutput: %s", err, string(output))
-	}
-
-	return nil
+	return append(args, outputPath)
 }
 
 // mergeFiles merges the audio track with the video file
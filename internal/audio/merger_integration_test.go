@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMergerWithRealFFmpeg(t *testing.T) {
@@ -27,7 +28,7 @@ func TestMergerWithRealFFmpeg(t *testing.T) {
 		}
 
 		outputPath := filepath.Join(tmpDir, "concat.mp3")
-		err := merger.createTimedAudioTrack([]string{audioPath}, []int{3}, outputPath)
+		err := merger.createTimedAudioTrack([]string{audioPath}, []time.Duration{3 * time.Second}, outputPath)
 		if err != nil {
 			t.Errorf("Failed to create timed audio track: %v", err)
 		}
@@ -41,7 +42,7 @@ func TestMergerWithRealFFmpeg(t *testing.T) {
 	// Test 2: Handle empty audio list
 	t.Run("EmptyAudioList", func(t *testing.T) {
 		outputPath := filepath.Join(tmpDir, "silence.mp3")
-		err := merger.createTimedAudioTrack([]string{}, []int{5}, outputPath)
+		err := merger.createTimedAudioTrack([]string{}, []time.Duration{5 * time.Second}, outputPath)
 		if err != nil {
 			t.Errorf("Failed to create silence track: %v", err)
 		}
@@ -83,31 +84,31 @@ func TestFFmpegCommandGeneration(t *testing.T) {
 	tests := []struct {
 		name           string
 		audioFiles     []string
-		slideDurations []int
+		slideDurations []time.Duration
 		expectError    bool
 	}{
 		{
 			name:           "Single audio file",
 			audioFiles:     []string{"audio1.mp3"},
-			slideDurations: []int{10},
+			slideDurations: []time.Duration{10 * time.Second},
 			expectError:    false,
 		},
 		{
 			name:           "Multiple audio files",
 			audioFiles:     []string{"audio1.mp3", "audio2.mp3", "audio3.mp3"},
-			slideDurations: []int{5, 10, 15},
+			slideDurations: []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second},
 			expectError:    false,
 		},
 		{
 			name:           "Mixed audio and silence",
 			audioFiles:     []string{"audio1.mp3", "", "audio3.mp3"},
-			slideDurations: []int{5, 10, 15},
+			slideDurations: []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second},
 			expectError:    false,
 		},
 		{
 			name:           "All silence",
 			audioFiles:     []string{"", "", ""},
-			slideDurations: []int{5, 10, 15},
+			slideDurations: []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second},
 			expectError:    false,
 		},
 	}
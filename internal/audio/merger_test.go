@@ -4,7 +4,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewAudioVideoMerger(t *testing.T) {
@@ -47,7 +49,7 @@ func TestMergeAudioWithVideoNoFFmpeg(t *testing.T) {
 		t.Fatalf("Failed to create test video: %v", err)
 	}
 
-	err := merger.MergeAudioWithVideo(videoPath, []string{}, []int{10}, outputPath)
+	err := merger.MergeAudioWithVideo(videoPath, []string{}, []time.Duration{10 * time.Second}, outputPath)
 	if err == nil {
 		t.Error("Expected error when ffmpeg is not available")
 	}
@@ -62,25 +64,25 @@ func TestCreateTimedAudioTrackLogic(t *testing.T) {
 	tests := []struct {
 		name           string
 		audioFiles     []string
-		slideDurations []int
+		slideDurations []time.Duration
 		expectedParts  int // Expected number of filter parts
 	}{
 		{
 			name:           "All slides with audio",
 			audioFiles:     []string{"audio1.mp3", "audio2.mp3", "audio3.mp3"},
-			slideDurations: []int{10, 15, 20},
+			slideDurations: []time.Duration{10 * time.Second, 15 * time.Second, 20 * time.Second},
 			expectedParts:  3,
 		},
 		{
 			name:           "Some slides without audio",
 			audioFiles:     []string{"audio1.mp3", "", "audio3.mp3"},
-			slideDurations: []int{10, 15, 20},
+			slideDurations: []time.Duration{10 * time.Second, 15 * time.Second, 20 * time.Second},
 			expectedParts:  3,
 		},
 		{
 			name:           "No audio files",
 			audioFiles:     []string{},
-			slideDurations: []int{10, 15, 20},
+			slideDurations: []time.Duration{10 * time.Second, 15 * time.Second, 20 * time.Second},
 			expectedParts:  3,
 		},
 	}
@@ -110,3 +112,62 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestTimedMergeArgs(t *testing.T) {
+	tests := []struct {
+		output string
+		codec  string
+	}{
+		{"final.mp4", "aac"},
+		{"final.webm", "libopus"},
+	}
+
+	for _, tt := range tests {
+		args := strings.Join(timedMergeArgs("video"+filepath.Ext(tt.output), "audio.mp3", tt.output, 12500*time.Millisecond), " ")
+		if !contains(args, "-c:v copy") || !contains(args, "-c:a "+tt.codec) {
+			t.Errorf("%s: expected the video copied and %s audio, got %s", tt.output, tt.codec, args)
+		}
+		if !strings.HasSuffix(args, "-t 12.500 "+tt.output) {
+			t.Errorf("%s: expected the output cut to the slide durations, got %s", tt.output, args)
+		}
+		if contains(args, "-ss") || contains(args, "setpts") {
+			t.Errorf("%s: expected no offset or speed adjustment, got %s", tt.output, args)
+		}
+	}
+}
+
+func TestTimedAudioArgsSilentSlides(t *testing.T) {
+	durations := []time.Duration{3 * time.Second, 5 * time.Second, 4 * time.Second}
+	audioDuration := func(path string) (time.Duration, error) {
+		return 2 * time.Second, nil
+	}
+
+	args := timedAudioArgs([]string{"one.mp3", "", "three.wav"}, durations, audioDuration, "track.mp3")
+	joined := strings.Join(args, " ")
+	if !strings.HasPrefix(joined, "-y -i one.mp3 -i three.wav ") {
+		t.Errorf("Expected only the narrated slides as inputs, got %s", joined)
+	}
+	// The silent slide keeps its place, so the third file starts after 8s
+	for _, want := range []string{
+		"[0:a]apad=pad_dur=1.000[a0]",
+		"anullsrc=duration=5.000:sample_rate=44100:channel_layout=stereo[a1]",
+		"[1:a]apad=pad_dur=2.000[a2]",
+		"[a0][a1][a2]concat=n=3",
+	} {
+		if !contains(joined, want) {
+			t.Errorf("Expected %q in %s", want, joined)
+		}
+	}
+}
+
+func TestMergeAudioWithTimedVideoNoFFmpeg(t *testing.T) {
+	merger := &AudioVideoMerger{
+		ffmpegPath: "/nonexistent/ffmpeg",
+	}
+
+	tmpDir := t.TempDir()
+	err := merger.MergeAudioWithTimedVideo(filepath.Join(tmpDir, "test.mp4"), nil, []time.Duration{10 * time.Second}, filepath.Join(tmpDir, "output.mp4"))
+	if err == nil || !contains(err.Error(), "ffmpeg not available") {
+		t.Errorf("Expected error about ffmpeg not available, got: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultOpenAIBaseURL is the OpenAI API, which self-hosted servers mimic
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// openAIFormats are the response formats of /audio/speech that ffmpeg can
+// read without being told the sample layout, which rules out raw pcm
+var openAIFormats = map[string]bool{"mp3": true, "opus": true, "aac": true, "flac": true, "wav": true}
+
+// OpenAIConfig configures a generator for an OpenAI-compatible
+// /v1/audio/speech endpoint
+type OpenAIConfig struct {
+	// APIKey is sent as a bearer token; self-hosted servers may not need one
+	APIKey string
+	// BaseURL is the API root, such as http://localhost:8000/v1
+	BaseURL string
+	Model   string
+	Voice   string
+	// Speed is between 0.25 and 4; zero leaves it to the server
+	Speed float64
+	// ResponseFormat is mp3, opus, aac, flac or wav; empty requests
+	// the format of the output file
+	ResponseFormat string
+}
+
+// OpenAIGenerator narrates text with an OpenAI-compatible speech endpoint
+type OpenAIGenerator struct {
+	config     OpenAIConfig
+	httpClient *http.Client
+}
+
+// NewOpenAIGenerator creates a generator, filling in OpenAI's defaults
+func NewOpenAIGenerator(config OpenAIConfig) (*OpenAIGenerator, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultOpenAIBaseURL
+	}
+	if config.Model == "" {
+		config.Model = "tts-1"
+	}
+	if config.Voice == "" {
+		config.Voice = "alloy"
+	}
+	if config.Speed != 0 && (config.Speed < 0.25 || config.Speed > 4) {
+		return nil, fmt.Errorf("invalid speech speed %v: must be between 0.25 and 4", config.Speed)
+	}
+	config.ResponseFormat = strings.ToLower(config.ResponseFormat)
+	if config.ResponseFormat != "" && !openAIFormats[config.ResponseFormat] {
+		return nil, fmt.Errorf("invalid response format %q: expected mp3, opus, aac, flac or wav", config.ResponseFormat)
+	}
+
+	return &OpenAIGenerator{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}, nil
+}
+
+type speechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed,omitempty"`
+}
+
+// GenerateAudio writes the narration of text to outputPath. Audio the server
+// returns in another format than the file's is converted with ffmpeg.
+func (g *OpenAIGenerator) GenerateAudio(text string, outputPath string) (time.Duration, error) {
+	if g.config.APIKey == "" && g.config.BaseURL == DefaultOpenAIBaseURL {
+		return 0, fmt.Errorf("OpenAI API key not configured")
+	}
+
+	format := g.config.ResponseFormat
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(outputPath)), ".")
+		if !openAIFormats[format] {
+			format = "mp3"
+		}
+	}
+
+	jsonData, err := json.Marshal(speechRequest{
+		Model:          g.config.Model,
+		Input:          text,
+		Voice:          g.config.Voice,
+		ResponseFormat: format,
+		Speed:          g.config.Speed,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := strings.TrimSuffix(g.config.BaseURL, "/") + "/audio/speech"
+	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if g.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.config.APIKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, newAPIError("speech", resp, body)
+	}
+
+	audioData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Audio in the file's own format is written as is
+	rawPath := outputPath
+	if "."+format != strings.ToLower(filepath.Ext(outputPath)) {
+		tempDir, err := os.MkdirTemp("", "rhesis_tts_*")
+		if err != nil {
+			return 0, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+		rawPath = filepath.Join(tempDir, "narration."+format)
+	}
+	if err := os.WriteFile(rawPath, audioData, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	duration := estimateDuration(text)
+	if format == "wav" {
+		if exact, err := WAVDuration(rawPath); err == nil {
+			duration = exact
+		}
+	}
+
+	if rawPath != outputPath {
+		if err := convertAudio(rawPath, outputPath); err != nil {
+			return 0, err
+		}
+	}
+	return duration, nil
+}
+
+func init() {
+	Register(Provider{
+		Name:    "openai",
+		KeyEnv:  "OPENAI_API_KEY",
+		Formats: []string{"mp3", "opus", "aac", "flac", "wav"},
+		New: func(config ProviderConfig) (Generator, error) {
+			return NewOpenAIGenerator(OpenAIConfig{
+				APIKey:         config.APIKey,
+				BaseURL:        config.URL,
+				Model:          config.Model,
+				Voice:          config.Voice,
+				Speed:          config.Speed,
+				ResponseFormat: config.Format,
+			})
+		},
+	})
+}
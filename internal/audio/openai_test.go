@@ -0,0 +1,149 @@
+package audio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// speechServer stands in for an OpenAI-compatible /v1/audio/speech endpoint,
+// answering every request with body and recording the last request
+type speechServer struct {
+	*httptest.Server
+	path, auth string
+	request    speechRequest
+}
+
+func newSpeechServer(t *testing.T, status int, body []byte) *speechServer {
+	t.Helper()
+	s := &speechServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path, s.auth = r.URL.Path, r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&s.request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestNewOpenAIGenerator(t *testing.T) {
+	gen, err := NewOpenAIGenerator(OpenAIConfig{ResponseFormat: "MP3"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gen.config.BaseURL != DefaultOpenAIBaseURL || gen.config.Model != "tts-1" || gen.config.Voice != "alloy" || gen.config.ResponseFormat != "mp3" {
+		t.Errorf("Unexpected defaults: %+v", gen.config)
+	}
+
+	for _, bad := range []OpenAIConfig{{Speed: 0.1}, {Speed: 5}, {ResponseFormat: "pcm"}} {
+		if _, err := NewOpenAIGenerator(bad); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestOpenAIGenerateAudio(t *testing.T) {
+	server := newSpeechServer(t, http.StatusOK, []byte("mp3 audio"))
+
+	gen, err := NewOpenAIGenerator(OpenAIConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL + "/v1/",
+		Model:   "tts-1-hd",
+		Voice:   "nova",
+		Speed:   1.25,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "audio", "slide_01.mp3")
+	duration, err := gen.GenerateAudio("Hello from a compatible server", outputPath)
+	if err != nil {
+		t.Fatalf("Failed to generate audio: %v", err)
+	}
+	if duration < time.Second {
+		t.Errorf("Expected an estimated duration of at least 1s, got %v", duration)
+	}
+
+	if server.path != "/v1/audio/speech" || server.auth != "Bearer test-key" {
+		t.Errorf("Expected an authorized request to /v1/audio/speech, got %s with %q", server.path, server.auth)
+	}
+	want := speechRequest{Model: "tts-1-hd", Input: "Hello from a compatible server", Voice: "nova", ResponseFormat: "mp3", Speed: 1.25}
+	if server.request != want {
+		t.Errorf("Expected request %+v, got %+v", want, server.request)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != "mp3 audio" {
+		t.Errorf("Expected the response written as is, got %q", data)
+	}
+}
+
+func TestOpenAIGenerateAudioWAV(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixture.wav")
+	writeWAV(t, fixture, 24000, 1750*time.Millisecond)
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSpeechServer(t, http.StatusOK, data)
+
+	// Self-hosted servers are used without an API key
+	gen, err := NewOpenAIGenerator(OpenAIConfig{BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	duration, err := gen.GenerateAudio("Exact", filepath.Join(t.TempDir(), "slide_01.wav"))
+	if err != nil {
+		t.Fatalf("Failed to generate audio: %v", err)
+	}
+	if server.request.ResponseFormat != "wav" || server.auth != "" {
+		t.Errorf("Expected an unauthorized wav request, got %q with %q", server.request.ResponseFormat, server.auth)
+	}
+	if duration != 1750*time.Millisecond {
+		t.Errorf("Expected the exact WAV duration of 1.75s, got %v", duration)
+	}
+}
+
+func TestOpenAIGenerateAudioErrors(t *testing.T) {
+	gen, err := NewOpenAIGenerator(OpenAIConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := gen.GenerateAudio("Text", filepath.Join(t.TempDir(), "slide_01.mp3")); err == nil {
+		t.Error("Expected an error without an API key for api.openai.com")
+	}
+
+	server := newSpeechServer(t, http.StatusUnauthorized, []byte(`{"error":"invalid key"}`))
+	gen, err = NewOpenAIGenerator(OpenAIConfig{APIKey: "bad", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = gen.GenerateAudio("Text", filepath.Join(t.TempDir(), "slide_01.mp3"))
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid key") {
+		t.Errorf("Expected the status and body in the error, got %v", err)
+	}
+}
+
+func TestNewGeneratorOpenAI(t *testing.T) {
+	server := newSpeechServer(t, http.StatusOK, []byte("audio"))
+
+	t.Setenv("OPENAI_API_KEY", "env-key")
+	gen, err := NewGenerator("openai", ProviderConfig{URL: server.URL, Voice: "echo", Format: "mp3"})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if _, err := gen.GenerateAudio("Hello", filepath.Join(t.TempDir(), "slide_01.mp3")); err != nil {
+		t.Fatalf("Failed to generate audio: %v", err)
+	}
+	if server.auth != "Bearer env-key" || server.request.Voice != "echo" {
+		t.Errorf("Expected the key from the environment and voice echo, got %q and %q", server.auth, server.request.Voice)
+	}
+}
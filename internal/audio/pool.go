@@ -0,0 +1,251 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults of NarratorOptions
+const (
+	DefaultConcurrency = 4
+	defaultBaseDelay   = time.Second
+	defaultMaxDelay    = 30 * time.Second
+)
+
+// ErrNotCached is the error of slides whose narration is not in the cache
+// when generation is turned off
+var ErrNotCached = errors.New("no cached narration")
+
+// NarratorOptions control how narration is generated
+type NarratorOptions struct {
+	// Concurrency is the number of slides narrated at once; zero uses DefaultConcurrency
+	Concurrency int
+	// Rate limits requests to the provider per second, retries included;
+	// zero is unlimited. Burst requests may be sent at once, at least one.
+	Rate  float64
+	Burst int
+	// Retries is the number of times a failed request is retried
+	Retries int
+	// BaseDelay is the wait before the first retry, doubled for every
+	// further one up to MaxDelay. A longer Retry-After from the server wins.
+	BaseDelay, MaxDelay time.Duration
+	// CachedOnly uses cached narration only and never calls the provider
+	CachedOnly bool
+	// OnRetry, if set, is called with the index of the slide before waiting
+	// to retry it
+	OnRetry func(slide, attempt int, delay time.Duration, err error)
+}
+
+// Narrator narrates the slides of a presentation with one provider, reusing
+// cached narration
+type Narrator struct {
+	Cache     *NarrationCache
+	Provider  string
+	Config    ProviderConfig
+	Generator Generator
+	// Exact is set when Generator reports true durations; see Provider
+	Exact   bool
+	Options NarratorOptions
+
+	// sleep waits between retries; tests replace it
+	sleep func(time.Duration)
+}
+
+// NarrationResult is the outcome of narrating one slide
+type NarrationResult struct {
+	Narration
+	Key string
+	// Attempts is the number of requests made for the slide; zero for
+	// cached and silent slides
+	Attempts int
+	Err      error
+}
+
+// NarrateAll narrates texts, the transcriptions of the slides in order,
+// returning one result per slide. Slides without text get an empty result.
+// Slides sharing a transcription are narrated once.
+func (n *Narrator) NarrateAll(texts []string) []NarrationResult {
+	opts := n.Options
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultMaxDelay
+	}
+	sleep := n.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	limiter := newTokenBucket(opts.Rate, opts.Burst)
+
+	results := make([]NarrationResult, len(texts))
+	slides := map[string][]int{}
+	var order []string
+	for i, text := range texts {
+		if NormalizeText(text) == "" {
+			continue
+		}
+		key := NarrationKey(n.Provider, n.Config, text)
+		results[i].Key = key
+		if _, seen := slides[key]; !seen {
+			order = append(order, key)
+		}
+		slides[key] = append(slides[key], i)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.Concurrency, len(order)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				indices := slides[key]
+				result := n.narrate(key, indices[0], texts[indices[0]], opts, limiter, sleep)
+				for _, i := range indices {
+					results[i] = result
+				}
+			}
+		}()
+	}
+	for _, key := range order {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// narrate produces the narration of one slide, retrying transient failures
+func (n *Narrator) narrate(key string, slide int, text string, opts NarratorOptions, limiter *tokenBucket, sleep func(time.Duration)) NarrationResult {
+	result := NarrationResult{Key: key}
+	if narration, ok := n.Cache.Lookup(key); ok {
+		result.Narration = narration
+		return result
+	}
+	if opts.CachedOnly {
+		result.Err = ErrNotCached
+		return result
+	}
+
+	for {
+		limiter.wait()
+		result.Attempts++
+		narration, err := n.Cache.Generate(key, n.Provider, n.Config, text, n.Generator, n.Exact)
+		if err == nil {
+			result.Narration = narration
+			result.Err = nil
+			return result
+		}
+		result.Err = err
+
+		retry, retryAfter := retryable(err)
+		if !retry || result.Attempts > opts.Retries {
+			return result
+		}
+		delay := max(backoff(result.Attempts, opts.BaseDelay, opts.MaxDelay), retryAfter)
+		if opts.OnRetry != nil {
+			opts.OnRetry(slide, result.Attempts, delay, err)
+		}
+		sleep(delay)
+	}
+}
+
+// backoff returns the wait after the given failed attempt: base, doubled for
+// every further attempt, up to limit
+func backoff(attempt int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// NarrationSummary counts the outcomes of NarrateAll
+type NarrationSummary struct {
+	Cached, Generated, Failed, Silent int
+	// Retries is the number of requests beyond the first, over all slides
+	Retries int
+	// FailedSlides lists the failed slides, counting from 1
+	FailedSlides []int
+}
+
+// Summarize counts the outcomes of NarrateAll
+func Summarize(results []NarrationResult) NarrationSummary {
+	var s NarrationSummary
+	counted := map[string]bool{}
+	for i, r := range results {
+		switch {
+		case r.Key == "":
+			s.Silent++
+		case r.Err != nil:
+			s.Failed++
+			s.FailedSlides = append(s.FailedSlides, i+1)
+		case r.Cached:
+			s.Cached++
+		default:
+			s.Generated++
+		}
+		// Slides sharing narration share their requests
+		if r.Attempts > 1 && !counted[r.Key] {
+			s.Retries += r.Attempts - 1
+			counted[r.Key] = true
+		}
+	}
+	return s
+}
+
+func (s NarrationSummary) String() string {
+	summary := fmt.Sprintf("Narration: %d generated, %d cached, %d failed, %d without transcription",
+		s.Generated, s.Cached, s.Failed, s.Silent)
+	if s.Retries > 0 {
+		summary += fmt.Sprintf(" (%d retries)", s.Retries)
+	}
+	return summary
+}
+
+// tokenBucket limits the rate of requests, allowing bursts of up to its
+// capacity. A nil bucket does not limit.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket returns a bucket refilled at rate tokens per second, or nil
+// without a rate
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	capacity := float64(max(burst, 1))
+	return &tokenBucket{rate: rate, capacity: capacity, tokens: capacity, last: time.Now()}
+}
+
+// wait blocks until a token is available and takes it
+func (b *tokenBucket) wait() {
+	if b == nil {
+		return
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,259 @@
+package audio
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyGenerator fails with err for the first failures calls of every text,
+// then writes the text
+type flakyGenerator struct {
+	mu       sync.Mutex
+	err      error
+	failures int
+	calls    map[string]int
+
+	active, peak atomic.Int32
+	hold         time.Duration
+}
+
+func (g *flakyGenerator) GenerateAudio(text string, outputPath string) (time.Duration, error) {
+	if active := g.active.Add(1); active > g.peak.Load() {
+		g.peak.Store(active)
+	}
+	defer g.active.Add(-1)
+	time.Sleep(g.hold)
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]int{}
+	}
+	g.calls[text]++
+	call := g.calls[text]
+	g.mu.Unlock()
+
+	if call <= g.failures {
+		return 0, g.err
+	}
+	return time.Second, os.WriteFile(outputPath, []byte(text), 0644)
+}
+
+func newNarrator(t *testing.T, gen Generator, opts NarratorOptions) (*Narrator, *[]time.Duration) {
+	t.Helper()
+	cache, err := OpenNarrationCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	var sleeps []time.Duration
+	var mu sync.Mutex
+	n := &Narrator{
+		Cache:     cache,
+		Provider:  "openai",
+		Generator: gen,
+		Exact:     true,
+		Options:   opts,
+		sleep: func(d time.Duration) {
+			mu.Lock()
+			sleeps = append(sleeps, d)
+			mu.Unlock()
+		},
+	}
+	return n, &sleeps
+}
+
+func TestNarrateAllRetries(t *testing.T) {
+	gen := &flakyGenerator{
+		err:      &APIError{API: "speech", StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second},
+		failures: 3,
+	}
+	n, sleeps := newNarrator(t, gen, NarratorOptions{Concurrency: 1, Retries: 3, BaseDelay: time.Second, MaxDelay: 8 * time.Second})
+
+	results := n.NarrateAll([]string{"Rate limited"})
+	if results[0].Err != nil {
+		t.Fatalf("Expected the slide to succeed after retries, got %v", results[0].Err)
+	}
+	if results[0].Attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", results[0].Attempts)
+	}
+	// Backoff of 1s, 2s and 4s, with the 3s Retry-After winning over the first two
+	want := []time.Duration{3 * time.Second, 3 * time.Second, 4 * time.Second}
+	if len(*sleeps) != len(want) {
+		t.Fatalf("Expected waits %v, got %v", want, *sleeps)
+	}
+	for i := range want {
+		if (*sleeps)[i] != want[i] {
+			t.Errorf("Expected waits %v, got %v", want, *sleeps)
+			break
+		}
+	}
+}
+
+func TestNarrateAllGivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"retries exhausted", &APIError{API: "speech", StatusCode: http.StatusServiceUnavailable}, 3},
+		{"client error", &APIError{API: "speech", StatusCode: http.StatusBadRequest}, 1},
+		{"engine failure", errors.New("piper failed"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &flakyGenerator{err: tt.err, failures: 10}
+			var retried []int
+			n, _ := newNarrator(t, gen, NarratorOptions{Retries: 2, OnRetry: func(slide, attempt int, delay time.Duration, err error) {
+				retried = append(retried, attempt)
+			}})
+
+			results := n.NarrateAll([]string{"", "Failing"})
+			if !errors.Is(results[1].Err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, results[1].Err)
+			}
+			if results[1].Attempts != tt.attempts || len(retried) != tt.attempts-1 {
+				t.Errorf("Expected %d attempts, got %d with retries %v", tt.attempts, results[1].Attempts, retried)
+			}
+			if s := Summarize(results); s.Failed != 1 || s.Silent != 1 || len(s.FailedSlides) != 1 || s.FailedSlides[0] != 2 {
+				t.Errorf("Expected slide 2 to fail, got %+v", s)
+			}
+		})
+	}
+}
+
+func TestNarrateAllConcurrency(t *testing.T) {
+	gen := &flakyGenerator{hold: 20 * time.Millisecond}
+	n, _ := newNarrator(t, gen, NarratorOptions{Concurrency: 3})
+
+	texts := []string{"One", "Two", "Three", "Four", "Five", "Six", "Two", ""}
+	results := n.NarrateAll(texts)
+
+	if peak := gen.peak.Load(); peak < 2 || peak > 3 {
+		t.Errorf("Expected 2 to 3 slides narrated at once, got %d", peak)
+	}
+	if gen.calls["Two"] != 1 {
+		t.Errorf("Expected a repeated transcription to be narrated once, got %d calls", gen.calls["Two"])
+	}
+	for i, text := range texts[:7] {
+		data, err := os.ReadFile(results[i].Path)
+		if err != nil || string(data) != text {
+			t.Errorf("Slide %d: expected narration %q, got %q (%v)", i+1, text, data, err)
+		}
+	}
+
+	// A second run finds everything in the cache
+	results = n.NarrateAll(texts)
+	if s := Summarize(results); s.Cached != 7 || s.Generated != 0 || s.Silent != 1 {
+		t.Errorf("Expected 7 cached slides and 1 silent, got %+v", s)
+	}
+	if got := len(gen.calls); got != 6 {
+		t.Errorf("Expected no new requests, got %d texts requested", got)
+	}
+}
+
+func TestNarrateAllCachedOnly(t *testing.T) {
+	gen := &flakyGenerator{}
+	n, _ := newNarrator(t, gen, NarratorOptions{CachedOnly: true})
+
+	results := n.NarrateAll([]string{"Not cached"})
+	if !errors.Is(results[0].Err, ErrNotCached) || len(gen.calls) != 0 {
+		t.Errorf("Expected ErrNotCached without requests, got %v after %d requests", results[0].Err, len(gen.calls))
+	}
+}
+
+func TestNarrateAllRetryAfterHeader(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, `{"error":"rate limited"}`, http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	gen, err := NewOpenAIGenerator(OpenAIConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	n, sleeps := newNarrator(t, gen, NarratorOptions{Retries: 1, BaseDelay: time.Millisecond})
+	n.Exact = false
+
+	results := n.NarrateAll([]string{"Hello"})
+	if results[0].Err != nil || results[0].Attempts != 2 {
+		t.Fatalf("Expected success on the second attempt, got %d attempts and %v", results[0].Attempts, results[0].Err)
+	}
+	if len(*sleeps) != 1 || (*sleeps)[0] != 7*time.Second {
+		t.Errorf("Expected to wait the 7s of Retry-After, got %v", *sleeps)
+	}
+	if s := Summarize(results); s.Generated != 1 || s.Retries != 1 {
+		t.Errorf("Expected 1 generated slide and 1 retry, got %+v", s)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 6: 30 * time.Second} {
+		if got := backoff(attempt, time.Second, 30*time.Second); got != want {
+			t.Errorf("Attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"5":                             5 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("%q: expected %v, got %v", value, want, got)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{&APIError{StatusCode: http.StatusBadGateway}, true},
+		{&APIError{StatusCode: http.StatusUnauthorized}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{errors.New("espeak failed"), false},
+	}
+	for _, tt := range tests {
+		if got, _ := retryable(tt.err); got != tt.want {
+			t.Errorf("%v: expected retryable %v, got %v", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	if newTokenBucket(0, 5) != nil {
+		t.Error("Expected no limit without a rate")
+	}
+
+	bucket := newTokenBucket(100, 2)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		bucket.wait()
+	}
+	// Two requests in the burst, then four more at 100 per second
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected the bucket to hold back requests, took %v", elapsed)
+	}
+}
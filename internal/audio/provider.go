@@ -0,0 +1,124 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultProvider is the text-to-speech provider used when none is chosen
+const DefaultProvider = "elevenlabs"
+
+// ProviderConfig holds the settings a text-to-speech provider is created with.
+// Fields a provider does not use are ignored; empty fields take the
+// provider's defaults.
+type ProviderConfig struct {
+	APIKey string
+	Voice  string
+	Model  string
+	// URL replaces the provider's default endpoint
+	URL string
+	// Speed scales the speaking rate, where 1 is normal; zero keeps the default
+	Speed float64
+	// Format is the audio format requested from the provider, such as mp3
+	Format string
+}
+
+// Provider describes a text-to-speech backend that can narrate slides
+type Provider struct {
+	// Name selects the provider, as in -tts or the tts front matter key
+	Name string
+	// KeyEnv names the environment variable holding the API key, for
+	// providers that need one
+	KeyEnv string
+	// ModelFile is set when the model is a file, which scripts name relative
+	// to themselves
+	ModelFile bool
+	// Exact is set when generators report the true narration duration
+	// rather than an estimate
+	Exact bool
+	// Formats lists the audio formats the provider writes, such as mp3 or
+	// wav, its default first; empty means mp3 only
+	Formats []string
+	// New creates a generator from the provider settings
+	New func(config ProviderConfig) (Generator, error)
+}
+
+// FileFormat returns the audio format narration with config is stored in:
+// the requested Format if the provider writes it, else its default
+func (p Provider) FileFormat(config ProviderConfig) string {
+	format := strings.ToLower(config.Format)
+	for _, supported := range p.Formats {
+		if supported == format {
+			return format
+		}
+	}
+	if len(p.Formats) > 0 {
+		return p.Formats[0]
+	}
+	return "mp3"
+}
+
+// providers holds the registered providers by name
+var providers = map[string]Provider{}
+
+// Register makes a provider available to NewGenerator by its name, which is
+// matched case-insensitively. If Register is called twice with the same name,
+// or if the name is empty or New is nil, it panics.
+func Register(p Provider) {
+	name := strings.ToLower(p.Name)
+	if name == "" || p.New == nil {
+		panic("audio: Register needs a provider name and constructor")
+	}
+	if _, exists := providers[name]; exists {
+		panic(fmt.Sprintf("audio: provider %q registered twice", name))
+	}
+	p.Name = name
+	providers[name] = p
+}
+
+// LookupProvider returns the provider registered under name
+func LookupProvider(name string) (Provider, bool) {
+	p, ok := providers[strings.ToLower(name)]
+	return p, ok
+}
+
+// Providers returns the names of the registered providers, sorted
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewGenerator creates a generator for the named provider. Without an API key
+// in config, the provider's KeyEnv environment variable is used.
+func NewGenerator(name string, config ProviderConfig) (Generator, error) {
+	p, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown text-to-speech provider %q (available: %s)", name, strings.Join(Providers(), ", "))
+	}
+	if config.APIKey == "" && p.KeyEnv != "" {
+		config.APIKey = os.Getenv(p.KeyEnv)
+	}
+	return p.New(config)
+}
+
+func init() {
+	Register(Provider{
+		Name:    "elevenlabs",
+		KeyEnv:  "ELEVENLABS_API_KEY",
+		Formats: []string{"mp3"},
+		New: func(config ProviderConfig) (Generator, error) {
+			return NewElevenLabsGenerator(ElevenLabsConfig{
+				APIKey:  config.APIKey,
+				VoiceID: config.Voice,
+				ModelID: config.Model,
+				BaseURL: config.URL,
+			}), nil
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	if _, ok := LookupProvider("ElevenLabs"); !ok {
+		t.Error("Expected elevenlabs to be registered, regardless of case")
+	}
+	found := false
+	for _, name := range Providers() {
+		found = found || name == DefaultProvider
+	}
+	if !found {
+		t.Errorf("Expected %s among %v", DefaultProvider, Providers())
+	}
+
+	_, err := NewGenerator("nonexistent", ProviderConfig{})
+	if err == nil || !strings.Contains(err.Error(), "elevenlabs") {
+		t.Errorf("Expected an error listing the providers, got %v", err)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a provider twice to panic")
+		}
+	}()
+	Register(Provider{Name: DefaultProvider, New: func(ProviderConfig) (Generator, error) { return nil, nil }})
+}
+
+func TestNewGeneratorElevenLabs(t *testing.T) {
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("xi-api-key")
+		io.WriteString(w, "audio")
+	}))
+	defer server.Close()
+
+	t.Setenv("ELEVENLABS_API_KEY", "env-key")
+	gen, err := NewGenerator("elevenlabs", ProviderConfig{Voice: "voice-123", URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if _, err := gen.GenerateAudio("Hello", filepath.Join(t.TempDir(), "slide_01.mp3")); err != nil {
+		t.Fatalf("Failed to generate audio: %v", err)
+	}
+	if path != "/v1/text-to-speech/voice-123" || key != "env-key" {
+		t.Errorf("Expected the voice in the path and the key from the environment, got %s and %q", path, key)
+	}
+}
@@ -0,0 +1,755 @@
+// Package exporter writes scripts to formats other presentation tools can
+// open. Content that has no equivalent in the target format is reported as
+// warnings rather than failing the export.
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/d2renderer"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// CodeUnsupported is the diagnostic code of content the exporter cannot convert
+const CodeUnsupported = "unsupported-construct"
+
+// Geometry of the exported slides in EMU (914400 per inch): a 16:9 slide
+// with the title at the top and the content below it
+const (
+	slideWidth  = 12192000
+	slideHeight = 6858000
+	margin      = 457200
+	gap         = 304800
+)
+
+var (
+	titleArea = rect{margin, 304800, slideWidth - 2*margin, 1066800}
+	bodyArea  = rect{margin, 1524000, slideWidth - 2*margin, 4876800}
+)
+
+// PPTXOptions configure ExportPPTX
+type PPTXOptions struct {
+	// AudioFiles are the narration of each slide by slide index, as written
+	// by the audio step. Slides with an empty entry, or past the end of the
+	// list, get no audio.
+	AudioFiles []string
+
+	// AutoAdvance moves on to the next slide once its Duration has elapsed
+	AutoAdvance bool
+}
+
+// ExportPPTX writes the script as a PowerPoint deck to outputPath. Every slide
+// becomes a slide with its title and content; images and D2 diagrams are
+// embedded and the transcription is kept as the speaker notes. Narration
+// audio given in opts plays automatically when its slide is shown. Content
+// that could not be converted (mermaid diagrams, raw HTML, missing images,
+// ...) is returned as warnings.
+func ExportPPTX(s *script.Script, outputPath string, opts PPTXOptions) ([]script.Diagnostic, error) {
+	w := &pptxWriter{
+		script: s,
+		opts:   opts,
+		lang:   s.Language,
+		media:  make(map[string]string),
+		types:  make(map[string]string),
+	}
+	if w.lang == "" {
+		w.lang = "en-US"
+	}
+
+	for i, slide := range s.Slides {
+		if err := w.slide(i, slide); err != nil {
+			return nil, fmt.Errorf("slide %d (%s): %w", i+1, slide.Title, err)
+		}
+	}
+	w.presentation()
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deck: %w", err)
+	}
+	if err := w.writeTo(f); err != nil {
+		f.Close()
+		os.Remove(outputPath)
+		return nil, fmt.Errorf("failed to write deck: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write deck: %w", err)
+	}
+	return w.diagnostics, nil
+}
+
+// pptxPart is a file of the package
+type pptxPart struct {
+	name string
+	data []byte
+}
+
+// pptxWriter holds the state of a single ExportPPTX run
+type pptxWriter struct {
+	script *script.Script
+	opts   PPTXOptions
+	lang   string
+
+	parts []pptxPart
+	// overrides are the content types of the XML parts, by part name
+	overrides [][2]string
+	// types are the content types of the media files, by extension
+	types map[string]string
+	// media maps media sources (file paths or content hashes) to their part
+	// name, so that every file is stored once
+	media map[string]string
+
+	d2    *d2renderer.Renderer
+	d2Err error
+
+	diagnostics []script.Diagnostic
+}
+
+func (w *pptxWriter) warnf(slide int, format string, args ...interface{}) {
+	d := script.Diagnostic{
+		Severity: script.SeverityWarning,
+		Code:     CodeUnsupported,
+		Message:  fmt.Sprintf("slide %d: %s", slide+1, fmt.Sprintf(format, args...)),
+	}
+	if slide < len(w.script.Slides) {
+		d.File = w.script.Slides[slide].Source
+	}
+	w.diagnostics = append(w.diagnostics, d)
+}
+
+// add stores an XML part with its content type
+func (w *pptxWriter) add(name, contentType, data string) {
+	w.parts = append(w.parts, pptxPart{name: name, data: []byte(data)})
+	if contentType != "" {
+		w.overrides = append(w.overrides, [2]string{"/" + name, contentType})
+	}
+}
+
+// mediaTypes are the content types of the media files the exporter embeds
+var mediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".m4a":  "audio/mp4",
+}
+
+// addMedia stores a media file once and returns its part name. key identifies
+// the source so that a file used on several slides is only stored once.
+func (w *pptxWriter) addMedia(key, prefix, ext string, data []byte) string {
+	if key == "" {
+		sum := sha256.Sum256(data)
+		key = hex.EncodeToString(sum[:])
+	}
+	if name, ok := w.media[key]; ok {
+		return name
+	}
+
+	ext = strings.ToLower(ext)
+	name := fmt.Sprintf("ppt/media/%s%d%s", prefix, len(w.media)+1, ext)
+	w.media[key] = name
+	w.types[strings.TrimPrefix(ext, ".")] = mediaTypes[ext]
+	w.parts = append(w.parts, pptxPart{name: name, data: data})
+	return name
+}
+
+// diagram renders D2 code to SVG, creating the renderer on first use
+func (w *pptxWriter) diagram(code string) (string, error) {
+	if w.d2 == nil && w.d2Err == nil {
+		w.d2, w.d2Err = d2renderer.NewRenderer()
+	}
+	if w.d2Err != nil {
+		return "", w.d2Err
+	}
+	return w.d2.RenderToSVG(code)
+}
+
+// presentation adds the parts shared by all slides: the presentation itself,
+// its masters, layouts and theme, and the document properties
+func (w *pptxWriter) presentation() {
+	n := len(w.script.Slides)
+
+	var rels, ids strings.Builder
+	rels.WriteString(xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId1" Type="%s" Target="slideMasters/slideMaster1.xml"/>`, relSlideMaster)
+	fmt.Fprintf(&rels, `<Relationship Id="rId2" Type="%s" Target="notesMasters/notesMaster1.xml"/>`, relNotesMaster)
+	fmt.Fprintf(&rels, `<Relationship Id="rId3" Type="%s" Target="theme/theme1.xml"/>`, relTheme)
+	fmt.Fprintf(&rels, `<Relationship Id="rId4" Type="%s" Target="presProps.xml"/>`, relPresProps)
+	fmt.Fprintf(&rels, `<Relationship Id="rId5" Type="%s" Target="tableStyles.xml"/>`, relTableStyles)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s" Target="slides/slide%d.xml"/>`, i+10, relSlide, i+1)
+		fmt.Fprintf(&ids, `<p:sldId id="%d" r:id="rId%d"/>`, i+256, i+10)
+	}
+	rels.WriteString(`</Relationships>`)
+
+	presentation := xmlHeader + `<p:presentation ` + pptxNamespaces + ` saveSubsetFonts="1">` +
+		`<p:sldMasterIdLst><p:sldMasterId id="2147483648" r:id="rId1"/></p:sldMasterIdLst>` +
+		`<p:notesMasterIdLst><p:notesMasterId r:id="rId2"/></p:notesMasterIdLst>`
+	if n > 0 {
+		presentation += `<p:sldIdLst>` + ids.String() + `</p:sldIdLst>`
+	}
+	presentation += fmt.Sprintf(`<p:sldSz cx="%d" cy="%d"/><p:notesSz cx="6858000" cy="9144000"/></p:presentation>`, slideWidth, slideHeight)
+
+	w.add("ppt/presentation.xml", ctPresentation, presentation)
+	w.add("ppt/_rels/presentation.xml.rels", "", rels.String())
+	w.add("ppt/slideMasters/slideMaster1.xml", ctSlideMaster, slideMasterXML)
+	w.add("ppt/slideMasters/_rels/slideMaster1.xml.rels", "", slideMasterRelsXML())
+	for i := range slideLayouts {
+		w.add(fmt.Sprintf("ppt/slideLayouts/slideLayout%d.xml", i+1), ctSlideLayout, slideLayoutXML(i+1))
+		w.add(fmt.Sprintf("ppt/slideLayouts/_rels/slideLayout%d.xml.rels", i+1), "", slideLayoutRelsXML)
+	}
+	w.add("ppt/notesMasters/notesMaster1.xml", ctNotesMaster, notesMasterXML)
+	w.add("ppt/notesMasters/_rels/notesMaster1.xml.rels", "", notesMasterRelsXML)
+	w.add("ppt/theme/theme1.xml", ctTheme, themeXML)
+	w.add("ppt/theme/theme2.xml", ctTheme, themeXML)
+	w.add("ppt/presProps.xml", ctPresProps, presPropsXML)
+	w.add("ppt/tableStyles.xml", ctTableStyles, tableStylesXML)
+
+	s := w.script
+	core := xmlHeader + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + escape(s.Title) + `</dc:title>`
+	if s.Author != "" {
+		core += `<dc:creator>` + escape(s.Author) + `</dc:creator>`
+	}
+	if len(s.Tags) > 0 {
+		core += `<cp:keywords>` + escape(strings.Join(s.Tags, "; ")) + `</cp:keywords>`
+	}
+	if s.Language != "" {
+		core += `<dc:language>` + escape(s.Language) + `</dc:language>`
+	}
+	core += `</cp:coreProperties>`
+	w.add("docProps/core.xml", ctCore, core)
+	w.add("docProps/app.xml", ctApp, xmlHeader+`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">`+
+		fmt.Sprintf(`<Application>rhesis</Application><Slides>%d</Slides><Notes>%d</Notes></Properties>`, n, n))
+
+	w.add("_rels/.rels", "", xmlHeader+`<Relationships xmlns="`+nsPackageRels+`">`+
+		`<Relationship Id="rId1" Type="`+relOfficeDocument+`" Target="ppt/presentation.xml"/>`+
+		`<Relationship Id="rId2" Type="`+relCoreProperties+`" Target="docProps/core.xml"/>`+
+		`<Relationship Id="rId3" Type="`+relAppProperties+`" Target="docProps/app.xml"/>`+
+		`</Relationships>`)
+}
+
+// writeTo writes the package as a zip archive, content types first
+func (w *pptxWriter) writeTo(f *os.File) error {
+	var types strings.Builder
+	types.WriteString(xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	types.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	types.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	for _, ext := range sortedKeys(w.types) {
+		fmt.Fprintf(&types, `<Default Extension="%s" ContentType="%s"/>`, ext, w.types[ext])
+	}
+	for _, o := range w.overrides {
+		fmt.Fprintf(&types, `<Override PartName="%s" ContentType="%s"/>`, o[0], o[1])
+	}
+	types.WriteString(`</Types>`)
+
+	z := zip.NewWriter(f)
+	parts := append([]pptxPart{{name: "[Content_Types].xml", data: []byte(types.String())}}, w.parts...)
+	for _, part := range parts {
+		pw, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := pw.Write(part.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// rect is a position and size on the slide, in EMU
+type rect struct {
+	x, y, w, h int64
+}
+
+// slideBuilder collects the shapes and relationships of a slide
+type slideBuilder struct {
+	w     *pptxWriter
+	index int
+	slide script.Slide
+
+	layout int
+	shapes strings.Builder
+	rels   []string
+	nextID int
+}
+
+// rel adds a relationship of the slide and returns its id
+func (b *slideBuilder) rel(kind, target string, external bool) string {
+	id := fmt.Sprintf("rId%d", len(b.rels)+1)
+	mode := ""
+	if external {
+		mode = ` TargetMode="External"`
+	}
+	b.rels = append(b.rels, fmt.Sprintf(`<Relationship Id="%s" Type="%s" Target="%s"%s/>`, id, kind, escape(target), mode))
+	return id
+}
+
+// mediaRel stores media and adds a relationship to it
+func (b *slideBuilder) mediaRel(kind, key, prefix, ext string, data []byte) string {
+	name := b.w.addMedia(key, prefix, ext, data)
+	return b.rel(kind, "../media/"+path.Base(name), false)
+}
+
+func (b *slideBuilder) id() int {
+	b.nextID++
+	return b.nextID
+}
+
+func (b *slideBuilder) warnf(format string, args ...interface{}) {
+	b.w.warnf(b.index, format, args...)
+}
+
+func (w *pptxWriter) slide(index int, slide script.Slide) error {
+	b := &slideBuilder{w: w, index: index, slide: slide, layout: layoutContent, nextID: 1}
+	b.rel(relSlideLayout, "", false) // target set once the layout is known
+
+	columns := []string{slide.Content}
+	if slide.Layout == script.LayoutTwoColumn {
+		columns = slide.Columns()
+	}
+	var contents []slideContent
+	for _, column := range columns {
+		contents = append(contents, b.convert(column))
+	}
+
+	var image []visual
+	if slide.Image != "" {
+		if v, ok := b.picture(slide.ResolvePath(slide.Image), slide.Image, slide.Title); ok {
+			image = append(image, v)
+		}
+	}
+
+	content := contents[0]
+	switch slide.Layout {
+	case script.LayoutTitle, script.LayoutSection:
+		content.visuals = append(image, content.visuals...)
+		titleBox, textBox := rect{margin, 1600200, bodyArea.w, 1752600}, rect{margin, 3505200, bodyArea.w, 1752600}
+		ph, align, size := `type="ctrTitle"`, "ctr", 4400
+		textPh := `type="subTitle" idx="1"`
+		b.layout = layoutTitle
+		if slide.Layout == script.LayoutSection {
+			ph, align, size, textPh = `type="title"`, "l", 4000, `type="body" idx="1"`
+			b.layout = layoutSection
+		}
+		if len(content.visuals) > 0 {
+			titleBox = titleArea
+			textBox = bodyArea
+		}
+		b.title(ph, titleBox, align, size, "")
+		b.place(textBox, content, textPh, listStyle(align, false, ""))
+
+	case script.LayoutTwoColumn:
+		b.layout = layoutTwoColumn
+		b.title(`type="title"`, titleArea, "", 0, "")
+		contents[len(contents)-1].visuals = append(contents[len(contents)-1].visuals, image...)
+		width := (bodyArea.w - gap*int64(len(contents)-1)) / int64(len(contents))
+		for i, column := range contents {
+			ph := ""
+			if i < 2 {
+				ph = fmt.Sprintf(`sz="half" idx="%d"`, i+1)
+			}
+			b.place(rect{bodyArea.x + int64(i)*(width+gap), bodyArea.y, width, bodyArea.h}, column, ph, "")
+		}
+
+	case script.LayoutImageLeft, script.LayoutImageRight:
+		b.title(`type="title"`, titleArea, "", 0, "")
+		visuals := append(image, content.visuals...)
+		content.visuals = nil
+		half := (bodyArea.w - gap) / 2
+		media, text := rect{bodyArea.x, bodyArea.y, half, bodyArea.h}, rect{bodyArea.x + half + gap, bodyArea.y, half, bodyArea.h}
+		if slide.Layout == script.LayoutImageRight {
+			media, text = text, media
+		}
+		b.visuals(media, visuals)
+		b.place(text, content, `idx="1"`, "")
+
+	case script.LayoutFullBleed:
+		if len(image) > 0 {
+			b.cover(image[0])
+			b.overlay(rect{0, 3886200, slideWidth, slideHeight - 3886200})
+			b.title(`type="title"`, rect{margin, 4038600, bodyArea.w, 914400}, "", 0, "FFFFFF")
+			b.place(rect{margin, 4953000, bodyArea.w, 1600200}, content, `idx="1"`, listStyle("", false, "FFFFFF"))
+			break
+		}
+		b.title(`type="title"`, titleArea, "", 0, "")
+		b.place(bodyArea, content, `idx="1"`, "")
+
+	case script.LayoutQuote:
+		content.visuals = append(content.visuals, image...)
+		b.place(rect{914400, 1371600, slideWidth - 2*914400, 3657600}, content, `idx="1"`, listStyle("ctr", true, ""))
+		b.title(`type="title"`, rect{914400, 5181600, slideWidth - 2*914400, 762000}, "r", 2000, "")
+
+	default:
+		content.visuals = append(content.visuals, image...)
+		b.title(`type="title"`, titleArea, "", 0, "")
+		b.place(bodyArea, content, `idx="1"`, "")
+	}
+
+	audioID := b.audio()
+
+	number := index + 1
+	b.rels[0] = fmt.Sprintf(`<Relationship Id="rId1" Type="%s" Target="../slideLayouts/slideLayout%d.xml"/>`, relSlideLayout, b.layout)
+	b.rel(relNotesSlide, fmt.Sprintf("../notesSlides/notesSlide%d.xml", number), false)
+
+	var x strings.Builder
+	x.WriteString(xmlHeader + `<p:sld ` + pptxNamespaces + `><p:cSld><p:spTree>` + groupProperties)
+	x.WriteString(b.shapes.String())
+	x.WriteString(`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr>`)
+	if w.opts.AutoAdvance && slide.Duration > 0 {
+		fmt.Fprintf(&x, `<p:transition spd="fast" advTm="%d"/>`, slide.Duration.Milliseconds())
+	}
+	if audioID != 0 {
+		x.WriteString(audioTiming(audioID, slide))
+	}
+	x.WriteString(`</p:sld>`)
+
+	w.add(fmt.Sprintf("ppt/slides/slide%d.xml", number), ctSlide, x.String())
+	w.add(fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", number), "", relsXML(b.rels))
+	w.add(fmt.Sprintf("ppt/notesSlides/notesSlide%d.xml", number), ctNotesSlide, notesXML(slide.Transcription, slide.Notes, w.lang))
+	w.add(fmt.Sprintf("ppt/notesSlides/_rels/notesSlide%d.xml.rels", number), "", relsXML([]string{
+		fmt.Sprintf(`<Relationship Id="rId1" Type="%s" Target="../notesMasters/notesMaster1.xml"/>`, relNotesMaster),
+		fmt.Sprintf(`<Relationship Id="rId2" Type="%s" Target="../slides/slide%d.xml"/>`, relSlide, number),
+	}))
+	return nil
+}
+
+func relsXML(rels []string) string {
+	return xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">` + strings.Join(rels, "") + `</Relationships>`
+}
+
+// listStyle overrides the alignment, emphasis and color of the first levels
+// of a text box
+func listStyle(align string, italic bool, color string) string {
+	if align == "" && !italic && color == "" {
+		return ""
+	}
+	attrs := ""
+	if align != "" {
+		attrs = ` algn="` + align + `"`
+	}
+	run := ""
+	if italic {
+		run = ` i="1" sz="2800"`
+	}
+	fill := ""
+	if color != "" {
+		fill = `<a:solidFill><a:srgbClr val="` + color + `"/></a:solidFill>`
+	}
+
+	var b strings.Builder
+	b.WriteString(`<a:lstStyle>`)
+	for level := 1; level <= 3; level++ {
+		fmt.Fprintf(&b, `<a:lvl%dpPr%s><a:defRPr%s>%s</a:defRPr></a:lvl%dpPr>`, level, attrs, run, fill, level)
+	}
+	b.WriteString(`</a:lstStyle>`)
+	return b.String()
+}
+
+// title adds the title placeholder. Size is in hundredths of a point, 0 for
+// the size of the master.
+func (b *slideBuilder) title(ph string, r rect, align string, size int, color string) {
+	attrs := ""
+	if size > 0 {
+		attrs = fmt.Sprintf(` sz="%d"`, size)
+	}
+	fill := ""
+	if color != "" {
+		fill = `<a:solidFill><a:srgbClr val="` + color + `"/></a:solidFill>`
+	}
+	p := `<a:p>`
+	if align != "" {
+		p += `<a:pPr algn="` + align + `"/>`
+	}
+	p += fmt.Sprintf(`<a:r><a:rPr lang="%s"%s dirty="0">%s</a:rPr><a:t>%s</a:t></a:r></a:p>`, b.w.lang, attrs, fill, escape(b.slide.Title))
+	b.textBox("Title", ph, r, `<a:bodyPr anchor="b"><a:normAutofit/></a:bodyPr>`, "", []string{p})
+}
+
+// textBox adds a text shape. ph holds the attributes of its placeholder, or is
+// empty for a plain text box.
+func (b *slideBuilder) textBox(name, ph string, r rect, bodyPr, lstStyle string, paragraphs []string) {
+	id := b.id()
+	fmt.Fprintf(&b.shapes, `<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s %d"/>`, id, name, id)
+	if ph != "" {
+		fmt.Fprintf(&b.shapes, `<p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph %s/></p:nvPr>`, ph)
+	} else {
+		b.shapes.WriteString(`<p:cNvSpPr txBox="1"/><p:nvPr/>`)
+	}
+	fmt.Fprintf(&b.shapes, `</p:nvSpPr><p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm>`+
+		`<a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>`, r.x, r.y, r.w, r.h)
+	if lstStyle == "" {
+		lstStyle = `<a:lstStyle/>`
+	}
+	b.shapes.WriteString(`<p:txBody>` + bodyPr + lstStyle)
+	if len(paragraphs) == 0 {
+		paragraphs = []string{`<a:p><a:endParaRPr lang="` + b.w.lang + `"/></a:p>`}
+	}
+	for _, p := range paragraphs {
+		b.shapes.WriteString(p)
+	}
+	b.shapes.WriteString(`</p:txBody></p:sp>`)
+}
+
+// place lays out text and visuals in r: side by side in wide areas, text
+// above the visuals in narrow ones
+func (b *slideBuilder) place(r rect, content slideContent, ph, lstStyle string) {
+	bodyPr := `<a:bodyPr wrap="square"><a:normAutofit/></a:bodyPr>`
+	switch {
+	case len(content.visuals) == 0:
+		if len(content.paragraphs) > 0 {
+			b.textBox("Content", ph, r, bodyPr, lstStyle, content.paragraphs)
+		}
+	case len(content.paragraphs) == 0:
+		b.visuals(r, content.visuals)
+	case r.w < slideWidth/2:
+		text := rect{r.x, r.y, r.w, (r.h - gap) / 2}
+		b.textBox("Content", ph, text, bodyPr, lstStyle, content.paragraphs)
+		b.visuals(rect{r.x, r.y + text.h + gap, r.w, r.h - text.h - gap}, content.visuals)
+	default:
+		text := rect{r.x, r.y, (r.w - gap) * 11 / 20, r.h}
+		b.textBox("Content", ph, text, bodyPr, lstStyle, content.paragraphs)
+		b.visuals(rect{r.x + text.w + gap, r.y, r.w - text.w - gap, r.h}, content.visuals)
+	}
+}
+
+// visuals stacks pictures and tables in r, each one as large as it fits
+func (b *slideBuilder) visuals(r rect, visuals []visual) {
+	if len(visuals) == 0 {
+		return
+	}
+	n := int64(len(visuals))
+	slot := (r.h - gap*(n-1)) / n
+	for i, v := range visuals {
+		area := rect{r.x, r.y + int64(i)*(slot+gap), r.w, slot}
+		if v.table != nil {
+			b.table(area, v)
+			continue
+		}
+		scale := float64(area.w) / v.width
+		if s := float64(area.h) / v.height; s < scale {
+			scale = s
+		}
+		w, h := int64(v.width*scale), int64(v.height*scale)
+		b.pictureShape(rect{area.x + (area.w-w)/2, area.y + (area.h-h)/2, w, h}, v, "")
+	}
+}
+
+// cover fills the slide with a picture, cropping what overflows
+func (b *slideBuilder) cover(v visual) {
+	crop := ""
+	imageRatio, slideRatio := v.width/v.height, float64(slideWidth)/float64(slideHeight)
+	if imageRatio > slideRatio {
+		side := int((1 - slideRatio/imageRatio) / 2 * 100000)
+		crop = fmt.Sprintf(`<a:srcRect l="%d" r="%d"/>`, side, side)
+	} else if imageRatio < slideRatio {
+		side := int((1 - imageRatio/slideRatio) / 2 * 100000)
+		crop = fmt.Sprintf(`<a:srcRect t="%d" b="%d"/>`, side, side)
+	}
+	b.pictureShape(rect{0, 0, slideWidth, slideHeight}, v, crop)
+}
+
+// overlay darkens r so that text stays readable over a picture
+func (b *slideBuilder) overlay(r rect) {
+	id := b.id()
+	fmt.Fprintf(&b.shapes, `<p:sp><p:nvSpPr><p:cNvPr id="%d" name="Overlay %d"/><p:cNvSpPr/><p:nvPr/></p:nvSpPr>`+
+		`<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom>`+
+		`<a:solidFill><a:srgbClr val="000000"><a:alpha val="55000"/></a:srgbClr></a:solidFill><a:ln><a:noFill/></a:ln></p:spPr></p:sp>`,
+		id, id, r.x, r.y, r.w, r.h)
+}
+
+func (b *slideBuilder) pictureShape(r rect, v visual, crop string) {
+	id := b.id()
+	blip := fmt.Sprintf(`<a:blip r:embed="%s"/>`, v.rID)
+	if v.svgRID != "" {
+		// PowerPoint 2016 and later draw the SVG; older versions show the fallback
+		blip = fmt.Sprintf(`<a:blip r:embed="%s"><a:extLst><a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}">`+
+			`<asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="%s"/></a:ext></a:extLst></a:blip>`,
+			v.rID, v.svgRID)
+	}
+	fmt.Fprintf(&b.shapes, `<p:pic><p:nvPicPr><p:cNvPr id="%d" name="Picture %d" descr="%s"/>`+
+		`<p:cNvPicPr><a:picLocks noChangeAspect="1"/></p:cNvPicPr><p:nvPr/></p:nvPicPr>`+
+		`<p:blipFill>%s%s<a:stretch><a:fillRect/></a:stretch></p:blipFill>`+
+		`<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr></p:pic>`,
+		id, id, escape(v.description), blip, crop, r.x, r.y, r.w, r.h)
+}
+
+// tableRowHeight is the height of a table row with a single line of text
+const tableRowHeight = 370840
+
+func (b *slideBuilder) table(r rect, v visual) {
+	columns := 0
+	for _, row := range v.table {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return
+	}
+	h := int64(len(v.table)) * tableRowHeight
+	if h > r.h {
+		h = r.h
+	}
+
+	id := b.id()
+	fmt.Fprintf(&b.shapes, `<p:graphicFrame><p:nvGraphicFramePr><p:cNvPr id="%d" name="Table %d"/>`+
+		`<p:cNvGraphicFramePr><a:graphicFrameLocks noGrp="1"/></p:cNvGraphicFramePr><p:nvPr/></p:nvGraphicFramePr>`+
+		`<p:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></p:xfrm>`+
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/table">`+
+		`<a:tbl><a:tblPr firstRow="1" bandRow="1"><a:tableStyleId>{5C22544A-7EE6-4342-B048-85BDC9FD1C3A}</a:tableStyleId></a:tblPr><a:tblGrid>`,
+		id, id, r.x, r.y, r.w, h)
+	for i := 0; i < columns; i++ {
+		fmt.Fprintf(&b.shapes, `<a:gridCol w="%d"/>`, r.w/int64(columns))
+	}
+	b.shapes.WriteString(`</a:tblGrid>`)
+	for _, row := range v.table {
+		fmt.Fprintf(&b.shapes, `<a:tr h="%d">`, tableRowHeight)
+		for i := 0; i < columns; i++ {
+			cell := `<a:p><a:endParaRPr lang="` + b.w.lang + `"/></a:p>`
+			if i < len(row) {
+				cell = row[i]
+			}
+			b.shapes.WriteString(`<a:tc><a:txBody><a:bodyPr/><a:lstStyle/>` + cell + `</a:txBody><a:tcPr/></a:tc>`)
+		}
+		b.shapes.WriteString(`</a:tr>`)
+	}
+	b.shapes.WriteString(`</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`)
+}
+
+// audio embeds the narration of the slide, if any, as a media shape off the
+// slide and returns its shape id, or 0 without narration
+func (b *slideBuilder) audio() int {
+	files := b.w.opts.AudioFiles
+	if b.index >= len(files) || files[b.index] == "" {
+		return 0
+	}
+	file := files[b.index]
+	data, err := os.ReadFile(file)
+	if err != nil {
+		b.warnf("narration %s could not be read: %v", file, err)
+		return 0
+	}
+	ext := strings.ToLower(filepath.Ext(file))
+	if _, ok := mediaTypes[ext]; !ok || !strings.HasPrefix(mediaTypes[ext], "audio/") {
+		b.warnf("narration %s is not an MP3, WAV or M4A file", file)
+		return 0
+	}
+
+	name := b.w.addMedia(file, "media", ext, data)
+	link := b.rel(relAudio, "../media/"+path.Base(name), false)
+	embed := b.rel(relMedia, "../media/"+path.Base(name), false)
+	icon := b.mediaRel(relImage, "", "image", ".png", audioIcon())
+
+	id := b.id()
+	fmt.Fprintf(&b.shapes, `<p:pic><p:nvPicPr><p:cNvPr id="%d" name="Narration %d"/>`+
+		`<p:cNvPicPr><a:picLocks noChangeAspect="1"/></p:cNvPicPr>`+
+		`<p:nvPr><a:audioFile r:link="%s"/><p:extLst><p:ext uri="{DAA4B4D4-6D71-4841-9C94-3DE1FCFB2247}">`+
+		`<p14:media xmlns:p14="http://schemas.microsoft.com/office/powerpoint/2010/main" r:embed="%s"/></p:ext></p:extLst></p:nvPr></p:nvPicPr>`+
+		`<p:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></p:blipFill>`+
+		`<p:spPr><a:xfrm><a:off x="%d" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr></p:pic>`,
+		id, id, link, embed, icon, slideWidth+gap, margin, margin)
+	return id
+}
+
+// audioTiming starts the narration shape when the slide is shown, the way
+// PowerPoint does for audio set to play automatically
+func audioTiming(shapeID int, slide script.Slide) string {
+	duration := slide.NarrationDuration
+	if duration <= 0 {
+		duration = slide.Duration
+	}
+	return fmt.Sprintf(`<p:timing><p:tnLst><p:par><p:cTn id="1" dur="indefinite" restart="never" nodeType="tmRoot"><p:childTnLst>`+
+		`<p:seq concurrent="1" nextAc="seek"><p:cTn id="2" dur="indefinite" nodeType="mainSeq"><p:childTnLst>`+
+		`<p:par><p:cTn id="3" fill="hold"><p:stCondLst><p:cond delay="indefinite"/><p:cond evt="onBegin" delay="0"><p:tn val="2"/></p:cond></p:stCondLst><p:childTnLst>`+
+		`<p:par><p:cTn id="4" fill="hold"><p:stCondLst><p:cond delay="0"/></p:stCondLst><p:childTnLst>`+
+		`<p:par><p:cTn id="5" presetID="1" presetClass="mediacall" presetSubtype="0" fill="hold" nodeType="afterEffect"><p:stCondLst><p:cond delay="0"/></p:stCondLst><p:childTnLst>`+
+		`<p:cmd type="call" cmd="playFrom(0.0)"><p:cBhvr><p:cTn id="6" dur="%d" fill="hold"/><p:tgtEl><p:spTgt spid="%d"/></p:tgtEl></p:cBhvr></p:cmd>`+
+		`</p:childTnLst></p:cTn></p:par></p:childTnLst></p:cTn></p:par></p:childTnLst></p:cTn></p:par>`+
+		`</p:childTnLst></p:cTn>`+
+		`<p:prevCondLst><p:cond evt="onPrev" delay="0"><p:tgtEl><p:sldTgt/></p:tgtEl></p:cond></p:prevCondLst>`+
+		`<p:nextCondLst><p:cond evt="onNext" delay="0"><p:tgtEl><p:sldTgt/></p:tgtEl></p:cond></p:nextCondLst></p:seq>`+
+		`<p:audio><p:cMediaNode vol="80000" showWhenStopped="0"><p:cTn id="7" fill="hold" display="0">`+
+		`<p:stCondLst><p:cond delay="indefinite"/></p:stCondLst>`+
+		`<p:endCondLst><p:cond evt="onStopAudio" delay="0"><p:tgtEl><p:sldTgt/></p:tgtEl></p:cond></p:endCondLst></p:cTn>`+
+		`<p:tgtEl><p:spTgt spid="%d"/></p:tgtEl></p:cMediaNode></p:audio>`+
+		`</p:childTnLst></p:cTn></p:par></p:tnLst></p:timing>`,
+		duration.Milliseconds(), shapeID, shapeID)
+}
+
+// notesSeparator is the paragraph between the transcription and the private
+// notes on a notes page
+const notesSeparator = "Notes:"
+
+// notesXML is the notes page of a slide, with the transcription as its text
+// followed by the private notes under a separator. Blank lines separate
+// paragraphs, as in Markdown.
+func notesXML(transcription, notes, lang string) string {
+	var paragraphs strings.Builder
+	paragraph := func(text string) {
+		fmt.Fprintf(&paragraphs, `<a:p><a:r><a:rPr lang="%s" dirty="0"/><a:t>%s</a:t></a:r></a:p>`, lang, escape(text))
+	}
+	split := func(text string) []string {
+		var result []string
+		for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+			if p = strings.Join(strings.Fields(p), " "); p != "" {
+				result = append(result, p)
+			}
+		}
+		return result
+	}
+	for _, p := range split(transcription) {
+		paragraph(p)
+	}
+	if notes := split(notes); len(notes) > 0 {
+		paragraph(notesSeparator)
+		for _, p := range notes {
+			paragraph(p)
+		}
+	}
+	if paragraphs.Len() == 0 {
+		paragraphs.WriteString(`<a:p><a:endParaRPr lang="` + lang + `"/></a:p>`)
+	}
+	return xmlHeader + `<p:notes ` + pptxNamespaces + `><p:cSld><p:spTree>` + groupProperties +
+		`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Slide Image"/><p:cNvSpPr><a:spLocks noGrp="1" noRot="1" noChangeAspect="1"/></p:cNvSpPr>` +
+		`<p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr><p:spPr/></p:sp>` +
+		`<p:sp><p:nvSpPr><p:cNvPr id="3" name="Notes"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr>` +
+		`<p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:spPr/>` +
+		`<p:txBody><a:bodyPr/><a:lstStyle/>` + paragraphs.String() + `</p:txBody></p:sp>` +
+		`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:notes>`
+}
+
+// audioIcon is the picture of the narration shape, a square in the accent color of the theme
+func audioIcon() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.Set(x, y, color.RGBA{0x3E, 0x7B, 0xFA, 0xFF})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,477 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+	_ "golang.org/x/image/webp"
+)
+
+// slideContent is Markdown converted to DrawingML: the paragraphs of a text
+// box and the pictures and tables laid out next to it
+type slideContent struct {
+	paragraphs []string
+	visuals    []visual
+}
+
+// visual is a picture or a table
+type visual struct {
+	// rID is the relationship of the picture; svgRID that of the SVG drawn
+	// instead of it by the PowerPoint versions that support SVG
+	rID, svgRID   string
+	width, height float64
+	description   string
+
+	// table holds the paragraphs of every cell, row by row
+	table [][]string
+}
+
+// markdownParser parses slide content with the GitHub extensions the
+// generator supports
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser()
+
+// bodyIndent is the indentation of one list level, in EMU
+const bodyIndent = 342900
+
+// paragraphStyle is the formatting of the paragraphs of a block
+type paragraphStyle struct {
+	// depth is the number of enclosing lists
+	depth int
+	// bullet is the bullet of the next paragraph, empty for none
+	bullet string
+	size   int
+	bold   bool
+	italic bool
+}
+
+// runStyle is the formatting of a run of text
+type runStyle struct {
+	size   int
+	bold   bool
+	italic bool
+	strike bool
+	mono   bool
+	link   string
+}
+
+// converter turns the Markdown of a slide into slideContent
+type converter struct {
+	b       *slideBuilder
+	source  []byte
+	content slideContent
+}
+
+// convert converts Markdown slide content
+func (b *slideBuilder) convert(markdown string) slideContent {
+	c := &converter{b: b, source: []byte(markdown)}
+	c.blocks(markdownParser.Parse(text.NewReader(c.source)), paragraphStyle{})
+	return c.content
+}
+
+func (c *converter) blocks(parent ast.Node, style paragraphStyle) {
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		c.block(n, style)
+		// Only the first paragraph of a list item has a bullet
+		style.bullet = ""
+	}
+}
+
+// headingSizes are the font sizes of headings by level, in hundredths of a point
+var headingSizes = map[int]int{1: 3200, 2: 2800, 3: 2400, 4: 2200}
+
+func (c *converter) block(n ast.Node, style paragraphStyle) {
+	switch n := n.(type) {
+	case *ast.Heading:
+		style.bold = true
+		style.size = headingSizes[n.Level]
+		c.paragraph(n, style)
+	case *ast.Paragraph, *ast.TextBlock:
+		c.paragraph(n, style)
+	case *ast.List:
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			itemStyle := style
+			itemStyle.depth = style.depth + 1
+			itemStyle.bullet = `<a:buFont typeface="Arial"/><a:buChar char="•"/>`
+			if n.IsOrdered() {
+				itemStyle.bullet = fmt.Sprintf(`<a:buFont typeface="+mj-lt"/><a:buAutoNum type="arabicPeriod" startAt="%d"/>`, number)
+				number++
+			}
+			c.blocks(item, itemStyle)
+		}
+	case *ast.FencedCodeBlock:
+		code := c.lines(n)
+		switch language := string(n.Language(c.source)); language {
+		case "d2":
+			svg, err := c.b.w.diagram(code)
+			if err != nil {
+				c.b.warnf("D2 diagram could not be rendered and is exported as code: %v", err)
+				break
+			}
+			if v, ok := c.b.svgPicture([]byte(svg), "", "D2 diagram"); ok {
+				c.content.visuals = append(c.content.visuals, v)
+				return
+			}
+		case "mermaid":
+			c.b.warnf("mermaid diagrams are exported as code; render the diagram to an image to include it")
+		}
+		c.code(code, style)
+	case *ast.CodeBlock:
+		c.code(c.lines(n), style)
+	case *ast.Blockquote:
+		style.italic = true
+		c.blocks(n, style)
+	case *ast.ThematicBreak:
+	case *ast.HTMLBlock:
+		c.b.warnf("HTML blocks are not exported")
+	case *east.Table:
+		c.table(n)
+	default:
+		c.blocks(n, style)
+	}
+}
+
+// lines returns the text of a code block
+func (c *converter) lines(n ast.Node) string {
+	var b strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		b.Write(segment.Value(c.source))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// code adds a paragraph in a monospace font for every line of a code block
+func (c *converter) code(code string, style paragraphStyle) {
+	for _, line := range strings.Split(code, "\n") {
+		run := ""
+		if line != "" {
+			run = c.run(line, runStyle{size: 1600, mono: true})
+		}
+		c.content.paragraphs = append(c.content.paragraphs, c.paragraphXML(style, run))
+		style.bullet = ""
+	}
+}
+
+func (c *converter) paragraph(n ast.Node, style paragraphStyle) {
+	runs := c.inlines(n, runStyle{size: style.size, bold: style.bold, italic: style.italic})
+	if strings.TrimSpace(plainText(n, c.source)) == "" && !hasCheckBox(n) {
+		// Paragraphs made only of images
+		return
+	}
+	c.content.paragraphs = append(c.content.paragraphs, c.paragraphXML(style, runs))
+}
+
+func (c *converter) paragraphXML(style paragraphStyle, runs string) string {
+	var b strings.Builder
+	b.WriteString(`<a:p><a:pPr`)
+	if style.depth > 0 {
+		level := style.depth - 1
+		fmt.Fprintf(&b, ` marL="%d" lvl="%d"`, (level+1)*bodyIndent, level)
+		if style.bullet != "" {
+			fmt.Fprintf(&b, ` indent="%d"`, -bodyIndent)
+		}
+	} else {
+		b.WriteString(` marL="0" indent="0"`)
+	}
+	b.WriteString(`>`)
+	if style.bullet != "" {
+		b.WriteString(style.bullet)
+	} else {
+		b.WriteString(`<a:buNone/>`)
+	}
+	b.WriteString(`</a:pPr>`)
+	if runs == "" {
+		fmt.Fprintf(&b, `<a:endParaRPr lang="%s"/>`, c.b.w.lang)
+	}
+	b.WriteString(runs)
+	b.WriteString(`</a:p>`)
+	return b.String()
+}
+
+// inlines returns the runs of the inline children of n
+func (c *converter) inlines(parent ast.Node, style runStyle) string {
+	var b strings.Builder
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		switch n := n.(type) {
+		case *ast.Text:
+			b.WriteString(c.run(string(n.Segment.Value(c.source)), style))
+			// Slides are rendered with hard wraps, so every line break counts
+			if n.HardLineBreak() || n.SoftLineBreak() {
+				fmt.Fprintf(&b, `<a:br><a:rPr lang="%s"/></a:br>`, c.b.w.lang)
+			}
+		case *ast.String:
+			b.WriteString(c.run(string(n.Value), style))
+		case *ast.CodeSpan:
+			mono := style
+			mono.mono = true
+			b.WriteString(c.inlines(n, mono))
+		case *ast.Emphasis:
+			emphasis := style
+			if n.Level >= 2 {
+				emphasis.bold = true
+			} else {
+				emphasis.italic = true
+			}
+			b.WriteString(c.inlines(n, emphasis))
+		case *ast.Link:
+			link := style
+			link.link = c.link(string(n.Destination))
+			b.WriteString(c.inlines(n, link))
+		case *ast.AutoLink:
+			link := style
+			link.link = c.link(string(n.URL(c.source)))
+			b.WriteString(c.run(string(n.Label(c.source)), link))
+		case *ast.Image:
+			c.image(n)
+		case *east.Strikethrough:
+			strike := style
+			strike.strike = true
+			b.WriteString(c.inlines(n, strike))
+		case *east.TaskCheckBox:
+			box := "☐ "
+			if n.IsChecked {
+				box = "☑ "
+			}
+			b.WriteString(c.run(box, style))
+		case *ast.RawHTML:
+			c.b.warnf("inline HTML is not exported")
+		default:
+			b.WriteString(c.inlines(n, style))
+		}
+	}
+	return b.String()
+}
+
+// link returns the relationship of a hyperlink, or "" for links that only
+// make sense in the generated HTML, like anchors and relative paths
+func (c *converter) link(destination string) string {
+	if !strings.Contains(destination, "://") && !strings.HasPrefix(destination, "mailto:") {
+		return ""
+	}
+	return c.b.rel(relHyperlink, destination, true)
+}
+
+// run is a run of text in the given style
+func (c *converter) run(s string, style runStyle) string {
+	if s == "" {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<a:r><a:rPr lang="%s"`, c.b.w.lang)
+	if style.size > 0 {
+		fmt.Fprintf(&b, ` sz="%d"`, style.size)
+	}
+	if style.bold {
+		b.WriteString(` b="1"`)
+	}
+	if style.italic {
+		b.WriteString(` i="1"`)
+	}
+	if style.strike {
+		b.WriteString(` strike="sngStrike"`)
+	}
+	b.WriteString(` dirty="0">`)
+	if style.mono {
+		b.WriteString(`<a:latin typeface="Courier New"/><a:cs typeface="Courier New"/>`)
+	}
+	if style.link != "" {
+		fmt.Fprintf(&b, `<a:hlinkClick r:id="%s"/>`, style.link)
+	}
+	fmt.Fprintf(&b, `</a:rPr><a:t>%s</a:t></a:r>`, escape(s))
+	return b.String()
+}
+
+// table converts a Markdown table; its cells keep their inline formatting
+func (c *converter) table(t *east.Table) {
+	var rows [][]string
+	for row := t.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			style := runStyle{}
+			if _, ok := row.(*east.TableHeader); ok {
+				style.bold = true
+			}
+			align := ""
+			if cell, ok := cell.(*east.TableCell); ok {
+				switch cell.Alignment {
+				case east.AlignCenter:
+					align = ` algn="ctr"`
+				case east.AlignRight:
+					align = ` algn="r"`
+				}
+			}
+			runs := c.inlines(cell, style)
+			if runs == "" {
+				runs = fmt.Sprintf(`<a:endParaRPr lang="%s"/>`, c.b.w.lang)
+			}
+			cells = append(cells, `<a:p><a:pPr`+align+`/>`+runs+`</a:p>`)
+		}
+		rows = append(rows, cells)
+	}
+	c.content.visuals = append(c.content.visuals, visual{table: rows})
+}
+
+// image embeds an image of the slide content
+func (c *converter) image(n *ast.Image) {
+	destination := string(n.Destination)
+	if strings.Contains(destination, "://") || strings.HasPrefix(destination, "data:") {
+		c.b.warnf("image %s is not a local file and is not embedded", destination)
+		return
+	}
+	if v, ok := c.b.picture(c.b.slide.ResolvePath(destination), destination, plainText(n, c.source)); ok {
+		c.content.visuals = append(c.content.visuals, v)
+	}
+}
+
+// plainText returns the text of the inline children of n
+func plainText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			switch n := n.(type) {
+			case *ast.Text:
+				b.Write(n.Segment.Value(source))
+			case *ast.String:
+				b.Write(n.Value)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+func hasCheckBox(n ast.Node) bool {
+	_, ok := n.FirstChild().(*east.TaskCheckBox)
+	return ok
+}
+
+// picture embeds the image file at path, which the script calls name
+func (b *slideBuilder) picture(path, name, description string) (visual, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.warnf("image %s could not be read: %v", name, err)
+		return visual{}, false
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".svg" {
+		return b.svgPicture(data, path, description)
+	}
+	if _, ok := mediaTypes[ext]; !ok {
+		b.warnf("image %s is not a PNG, JPEG, GIF, WebP or SVG image", name)
+		return visual{}, false
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width == 0 || config.Height == 0 {
+		b.warnf("image %s could not be decoded: %v", name, err)
+		return visual{}, false
+	}
+
+	return visual{
+		rID:         b.mediaRel(relImage, path, "image", ext, data),
+		width:       float64(config.Width),
+		height:      float64(config.Height),
+		description: description,
+	}, true
+}
+
+// svgPicture embeds an SVG image with a blank PNG of the same proportions as
+// the fallback that PowerPoint requires. key identifies the source file, or
+// is empty for generated images.
+func (b *slideBuilder) svgPicture(data []byte, key, description string) (visual, bool) {
+	width, height, ok := svgSize(data)
+	if !ok {
+		b.warnf("SVG image %s has no size", description)
+		return visual{}, false
+	}
+
+	// A small fallback keeps the deck light; only its proportions matter
+	scale := 64 / width
+	fallback := image.NewNRGBA(image.Rect(0, 0, 64, int(height*scale)+1))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, fallback); err != nil {
+		b.warnf("SVG image %s could not be embedded: %v", description, err)
+		return visual{}, false
+	}
+
+	return visual{
+		rID:         b.mediaRel(relImage, "", "image", ".png", buf.Bytes()),
+		svgRID:      b.mediaRel(relImage, key, "image", ".svg", data),
+		width:       width,
+		height:      height,
+		description: description,
+	}, true
+}
+
+// svgSize returns the size of an SVG image from the width and height of its
+// root element, or from its viewBox
+func svgSize(data []byte) (float64, float64, bool) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, false
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return 0, 0, false
+		}
+
+		var width, height, boxWidth, boxHeight float64
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				width = svgLength(attr.Value)
+			case "height":
+				height = svgLength(attr.Value)
+			case "viewBox":
+				if fields := strings.Fields(strings.ReplaceAll(attr.Value, ",", " ")); len(fields) == 4 {
+					boxWidth, boxHeight = svgLength(fields[2]), svgLength(fields[3])
+				}
+			}
+		}
+		if width <= 0 || height <= 0 {
+			width, height = boxWidth, boxHeight
+		}
+		return width, height, width > 0 && height > 0
+	}
+}
+
+// svgLength parses an SVG length in pixels; relative lengths are ignored
+func svgLength(value string) float64 {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	length, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return length
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
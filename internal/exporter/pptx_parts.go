@@ -0,0 +1,163 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Static parts of the PowerPoint package written by ExportPPTX: a single
+// slide master with the title, content, two-column and section layouts, a
+// notes master and an Office theme.
+
+const (
+	nsDrawing      = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsPresentation = "http://schemas.openxmlformats.org/presentationml/2006/main"
+	nsRelationship = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRels  = "http://schemas.openxmlformats.org/package/2006/relationships"
+
+	relOfficeDocument = nsRelationship + "/officeDocument"
+	relCoreProperties = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+	relAppProperties  = nsRelationship + "/extended-properties"
+	relSlideMaster    = nsRelationship + "/slideMaster"
+	relSlideLayout    = nsRelationship + "/slideLayout"
+	relSlide          = nsRelationship + "/slide"
+	relNotesMaster    = nsRelationship + "/notesMaster"
+	relNotesSlide     = nsRelationship + "/notesSlide"
+	relTheme          = nsRelationship + "/theme"
+	relPresProps      = nsRelationship + "/presProps"
+	relTableStyles    = nsRelationship + "/tableStyles"
+	relImage          = nsRelationship + "/image"
+	relHyperlink      = nsRelationship + "/hyperlink"
+	relAudio          = nsRelationship + "/audio"
+	relMedia          = "http://schemas.microsoft.com/office/2007/relationships/media"
+
+	ctPresentation = "application/vnd.openxmlformats-officedocument.presentationml.presentation.main+xml"
+	ctSlide        = "application/vnd.openxmlformats-officedocument.presentationml.slide+xml"
+	ctSlideMaster  = "application/vnd.openxmlformats-officedocument.presentationml.slideMaster+xml"
+	ctSlideLayout  = "application/vnd.openxmlformats-officedocument.presentationml.slideLayout+xml"
+	ctNotesMaster  = "application/vnd.openxmlformats-officedocument.presentationml.notesMaster+xml"
+	ctNotesSlide   = "application/vnd.openxmlformats-officedocument.presentationml.notesSlide+xml"
+	ctTheme        = "application/vnd.openxmlformats-officedocument.theme+xml"
+	ctPresProps    = "application/vnd.openxmlformats-officedocument.presentationml.presProps+xml"
+	ctTableStyles  = "application/vnd.openxmlformats-officedocument.presentationml.tableStyles+xml"
+	ctCore         = "application/vnd.openxmlformats-package.core-properties+xml"
+	ctApp          = "application/vnd.openxmlformats-officedocument.extended-properties+xml"
+)
+
+// pptxNamespaces are declared on the root element of every presentation part
+const pptxNamespaces = `xmlns:a="` + nsDrawing + `" xmlns:r="` + nsRelationship + `" xmlns:p="` + nsPresentation + `"`
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// groupProperties opens every shape tree
+const groupProperties = `<p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr>` +
+	`<p:grpSpPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="0" cy="0"/><a:chOff x="0" y="0"/><a:chExt cx="0" cy="0"/></a:xfrm></p:grpSpPr>`
+
+const colorMap = `bg1="lt1" tx1="dk1" bg2="lt2" tx2="dk2" accent1="accent1" accent2="accent2" accent3="accent3" ` +
+	`accent4="accent4" accent5="accent5" accent6="accent6" hlink="hlink" folHlink="folHlink"`
+
+const slideMasterXML = xmlHeader + `<p:sldMaster ` + pptxNamespaces + `><p:cSld>` +
+	`<p:bg><p:bgRef idx="1001"><a:schemeClr val="bg1"/></p:bgRef></p:bg><p:spTree>` + groupProperties +
+	`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Title Placeholder"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr>` +
+	`<p:spPr><a:xfrm><a:off x="457200" y="304800"/><a:ext cx="11277600" cy="1066800"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>` +
+	`<p:txBody><a:bodyPr anchor="b"/><a:lstStyle/><a:p><a:endParaRPr lang="en-US"/></a:p></p:txBody></p:sp>` +
+	`<p:sp><p:nvSpPr><p:cNvPr id="3" name="Text Placeholder"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr>` +
+	`<p:spPr><a:xfrm><a:off x="457200" y="1524000"/><a:ext cx="11277600" cy="4876800"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>` +
+	`<p:txBody><a:bodyPr/><a:lstStyle/><a:p><a:endParaRPr lang="en-US"/></a:p></p:txBody></p:sp>` +
+	`</p:spTree></p:cSld><p:clrMap ` + colorMap + `/>` +
+	`<p:sldLayoutIdLst>` +
+	`<p:sldLayoutId id="2147483649" r:id="rId1"/><p:sldLayoutId id="2147483650" r:id="rId2"/>` +
+	`<p:sldLayoutId id="2147483651" r:id="rId3"/><p:sldLayoutId id="2147483652" r:id="rId4"/>` +
+	`</p:sldLayoutIdLst>` +
+	`<p:txStyles>` +
+	`<p:titleStyle><a:lvl1pPr algn="l"><a:defRPr sz="3600" b="1"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill><a:latin typeface="+mj-lt"/></a:defRPr></a:lvl1pPr></p:titleStyle>` +
+	`<p:bodyStyle><a:lvl1pPr><a:spcBef><a:spcPts val="600"/></a:spcBef><a:defRPr sz="2000"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill><a:latin typeface="+mn-lt"/></a:defRPr></a:lvl1pPr></p:bodyStyle>` +
+	`<p:otherStyle><a:lvl1pPr><a:defRPr sz="1800"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill><a:latin typeface="+mn-lt"/></a:defRPr></a:lvl1pPr></p:otherStyle>` +
+	`</p:txStyles></p:sldMaster>`
+
+// Slide layouts, numbered as in the master's sldLayoutIdLst. Slides pick the
+// one PowerPoint would use for their rhesis layout, so that the deck can be
+// re-laid out and imported back.
+const (
+	layoutTitle     = 1
+	layoutContent   = 2
+	layoutTwoColumn = 3
+	layoutSection   = 4
+)
+
+// slideLayouts are the type, name and placeholders of each slide layout
+var slideLayouts = []struct {
+	kind, name, placeholders string
+}{
+	layoutTitle - 1:     {"title", "Title Slide", layoutPlaceholder(2, "Title", `type="ctrTitle"`) + layoutPlaceholder(3, "Subtitle", `type="subTitle" idx="1"`)},
+	layoutContent - 1:   {"obj", "Title and Content", layoutPlaceholder(2, "Title", `type="title"`) + layoutPlaceholder(3, "Content", `idx="1"`)},
+	layoutTwoColumn - 1: {"twoObj", "Two Content", layoutPlaceholder(2, "Title", `type="title"`) + layoutPlaceholder(3, "Left Content", `sz="half" idx="1"`) + layoutPlaceholder(4, "Right Content", `sz="half" idx="2"`)},
+	layoutSection - 1:   {"secHead", "Section Header", layoutPlaceholder(2, "Title", `type="title"`) + layoutPlaceholder(3, "Text", `type="body" idx="1"`)},
+}
+
+func layoutPlaceholder(id int, name, ph string) string {
+	return fmt.Sprintf(`<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph %s/></p:nvPr></p:nvSpPr>`+
+		`<p:spPr/><p:txBody><a:bodyPr/><a:lstStyle/><a:p><a:endParaRPr lang="en-US"/></a:p></p:txBody></p:sp>`, id, name, ph)
+}
+
+func slideLayoutXML(layout int) string {
+	l := slideLayouts[layout-1]
+	return xmlHeader + `<p:sldLayout ` + pptxNamespaces + ` type="` + l.kind + `" preserve="1">` +
+		`<p:cSld name="` + l.name + `"><p:spTree>` + groupProperties + l.placeholders +
+		`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:sldLayout>`
+}
+
+func slideMasterRelsXML() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">`)
+	for i := range slideLayouts {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="%s" Target="../slideLayouts/slideLayout%d.xml"/>`, i+1, relSlideLayout, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="%s" Target="../theme/theme1.xml"/>`, len(slideLayouts)+1, relTheme)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+const slideLayoutRelsXML = xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">` +
+	`<Relationship Id="rId1" Type="` + relSlideMaster + `" Target="../slideMasters/slideMaster1.xml"/>` +
+	`</Relationships>`
+
+const notesMasterXML = xmlHeader + `<p:notesMaster ` + pptxNamespaces + `><p:cSld>` +
+	`<p:bg><p:bgRef idx="1001"><a:schemeClr val="bg1"/></p:bgRef></p:bg><p:spTree>` + groupProperties +
+	`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Slide Image Placeholder"/><p:cNvSpPr><a:spLocks noGrp="1" noRot="1" noChangeAspect="1"/></p:cNvSpPr><p:nvPr><p:ph type="sldImg" idx="2"/></p:nvPr></p:nvSpPr>` +
+	`<p:spPr><a:xfrm><a:off x="381000" y="685800"/><a:ext cx="6096000" cy="3429000"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr></p:sp>` +
+	`<p:sp><p:nvSpPr><p:cNvPr id="3" name="Notes Placeholder"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr><p:nvPr><p:ph type="body" sz="quarter" idx="3"/></p:nvPr></p:nvSpPr>` +
+	`<p:spPr><a:xfrm><a:off x="685800" y="4343400"/><a:ext cx="5486400" cy="4114800"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>` +
+	`<p:txBody><a:bodyPr/><a:lstStyle/><a:p><a:endParaRPr lang="en-US"/></a:p></p:txBody></p:sp>` +
+	`</p:spTree></p:cSld><p:clrMap ` + colorMap + `/>` +
+	`<p:notesStyle><a:lvl1pPr><a:defRPr sz="1200"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill><a:latin typeface="+mn-lt"/></a:defRPr></a:lvl1pPr></p:notesStyle>` +
+	`</p:notesMaster>`
+
+const notesMasterRelsXML = xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">` +
+	`<Relationship Id="rId1" Type="` + relTheme + `" Target="../theme/theme2.xml"/>` +
+	`</Relationships>`
+
+const presPropsXML = xmlHeader + `<p:presentationPr ` + pptxNamespaces + `/>`
+
+const tableStylesXML = xmlHeader + `<a:tblStyleLst xmlns:a="` + nsDrawing + `" def="{5C22544A-7EE6-4342-B048-85BDC9FD1C3A}"/>`
+
+// themeXML is the Office theme shared by the slide and notes masters
+const themeXML = xmlHeader + `<a:theme xmlns:a="` + nsDrawing + `" name="Rhesis"><a:themeElements>` +
+	`<a:clrScheme name="Rhesis">` +
+	`<a:dk1><a:srgbClr val="1F2933"/></a:dk1><a:lt1><a:srgbClr val="FFFFFF"/></a:lt1>` +
+	`<a:dk2><a:srgbClr val="323F4B"/></a:dk2><a:lt2><a:srgbClr val="F5F7FA"/></a:lt2>` +
+	`<a:accent1><a:srgbClr val="3E7BFA"/></a:accent1><a:accent2><a:srgbClr val="F08C00"/></a:accent2>` +
+	`<a:accent3><a:srgbClr val="2F9E44"/></a:accent3><a:accent4><a:srgbClr val="AE3EC9"/></a:accent4>` +
+	`<a:accent5><a:srgbClr val="E03131"/></a:accent5><a:accent6><a:srgbClr val="1098AD"/></a:accent6>` +
+	`<a:hlink><a:srgbClr val="3E7BFA"/></a:hlink><a:folHlink><a:srgbClr val="7048E8"/></a:folHlink>` +
+	`</a:clrScheme>` +
+	`<a:fontScheme name="Rhesis">` +
+	`<a:majorFont><a:latin typeface="Calibri Light"/><a:ea typeface=""/><a:cs typeface=""/></a:majorFont>` +
+	`<a:minorFont><a:latin typeface="Calibri"/><a:ea typeface=""/><a:cs typeface=""/></a:minorFont>` +
+	`</a:fontScheme>` +
+	`<a:fmtScheme name="Rhesis">` +
+	`<a:fillStyleLst><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:fillStyleLst>` +
+	`<a:lnStyleLst><a:ln w="6350"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln><a:ln w="12700"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln><a:ln w="19050"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln></a:lnStyleLst>` +
+	`<a:effectStyleLst><a:effectStyle><a:effectLst/></a:effectStyle><a:effectStyle><a:effectLst/></a:effectStyle><a:effectStyle><a:effectLst/></a:effectStyle></a:effectStyleLst>` +
+	`<a:bgFillStyleLst><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:bgFillStyleLst>` +
+	`</a:fmtScheme></a:themeElements><a:objectDefaults/><a:extraClrSchemeLst/></a:theme>`
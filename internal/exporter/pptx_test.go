@@ -0,0 +1,279 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/importer"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// readParts returns the parts of a package by name
+func readParts(t *testing.T, path string) map[string]string {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open deck: %v", err)
+	}
+	defer archive.Close()
+
+	parts := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open part %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("Failed to read part %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(data)
+	}
+	return parts
+}
+
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportPPTX(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "chart.png"), 400, 300)
+	audioFile := filepath.Join(dir, "slide_02.mp3")
+	if err := os.WriteFile(audioFile, []byte("ID3 narration"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	scriptPath := filepath.Join(dir, "talk.md")
+	content := `---
+author: Ana
+tags: [finance, q3]
+---
+
+# Quarterly Review
+
+## Welcome
+Layout: title
+
+The numbers behind the quarter
+---
+Hello and welcome.
+
+## Results
+Duration: 8s
+Image: chart.png
+Layout: image-right
+
+- Revenue is **up** 12%
+  - Driven by [new customers](https://example.com/customers)
+- Costs are ` + "`flat`" + `
+
+| Region | Growth |
+| --- | --- |
+| North | 10% |
+---
+Revenue grew this quarter.
+
+Costs stayed flat.
+
+Notes:
+Mention the March contract.
+
+## Comparison
+Layout: two-column
+
+Before
+|||
+After
+
+` + "```mermaid\ngraph TD; A-->B\n```" + `
+`
+	if err := os.WriteFile(scriptPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := script.ParseScript(scriptPath)
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+	s.Slides[1].NarrationDuration = 7500 * time.Millisecond
+
+	deck := filepath.Join(dir, "talk.pptx")
+	warnings, err := ExportPPTX(s, deck, PPTXOptions{AudioFiles: []string{"", audioFile}, AutoAdvance: true})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "slide 3: mermaid") {
+		t.Errorf("Expected a mermaid warning for slide 3, got %v", warnings)
+	}
+
+	parts := readParts(t, deck)
+	for name, data := range parts {
+		if !strings.HasSuffix(name, ".xml") && !strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		decoder := xml.NewDecoder(strings.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Part %s is not well-formed: %v", name, err)
+			}
+		}
+	}
+
+	slide := parts["ppt/slides/slide2.xml"]
+	if !strings.Contains(slide, `<p:transition spd="fast" advTm="8000"/>`) {
+		t.Errorf("Expected slide 2 to advance after 8000ms, got %s", slide)
+	}
+	if !strings.Contains(slide, `<a:audioFile r:link=`) || !strings.Contains(slide, `cmd="playFrom(0.0)"><p:cBhvr><p:cTn id="6" dur="7500"`) {
+		t.Errorf("Expected slide 2 to play its narration automatically, got %s", slide)
+	}
+	if !strings.Contains(slide, `<a:tbl>`) || !strings.Contains(slide, `<a:hlinkClick r:id=`) {
+		t.Errorf("Expected slide 2 to have a table and a link, got %s", slide)
+	}
+	rels := parts["ppt/slides/_rels/slide2.xml.rels"]
+	for _, want := range []string{"relationships/audio", "2007/relationships/media", "https://example.com/customers", "notesSlide2.xml"} {
+		if !strings.Contains(rels, want) {
+			t.Errorf("Expected slide 2 relationships to contain %q, got %s", want, rels)
+		}
+	}
+	notes := parts["ppt/notesSlides/notesSlide2.xml"]
+	if !strings.Contains(notes, `<a:t>Costs stayed flat.</a:t></a:r></a:p><a:p><a:r><a:rPr lang="en-US" dirty="0"/><a:t>Notes:</a:t></a:r></a:p>`) ||
+		!strings.Contains(notes, `<a:t>Mention the March contract.</a:t>`) {
+		t.Errorf("Expected slide 2 notes to list the private notes after the transcription, got %s", notes)
+	}
+	if strings.Contains(parts["ppt/notesSlides/notesSlide1.xml"], "Notes:") {
+		t.Error("Expected no notes separator on slide 1")
+	}
+	if strings.Contains(parts["ppt/slides/slide1.xml"], "<p:timing>") {
+		t.Error("Expected no narration on slide 1")
+	}
+	if !strings.Contains(parts["[Content_Types].xml"], `<Default Extension="mp3" ContentType="audio/mpeg"/>`) {
+		t.Errorf("Expected a content type for MP3, got %s", parts["[Content_Types].xml"])
+	}
+
+	// The deck reads back as the same presentation
+	imported, err := importer.ImportPPTX(deck, filepath.Join(dir, "imported.md"), filepath.Join(dir, "assets"))
+	if err != nil {
+		t.Fatalf("Failed to import the exported deck: %v", err)
+	}
+	if imported.Title != "Quarterly Review" || imported.Author != "Ana" || strings.Join(imported.Tags, ",") != "finance,q3" {
+		t.Errorf("Expected the metadata to survive, got %q %q %v", imported.Title, imported.Author, imported.Tags)
+	}
+	if len(imported.Slides) != 3 {
+		t.Fatalf("Expected 3 slides, got %d", len(imported.Slides))
+	}
+
+	tests := []struct {
+		title, layout, transcription string
+		content                      []string
+	}{
+		{"Welcome", script.LayoutTitle, "Hello and welcome.", []string{"The numbers behind the quarter"}},
+		{"Results", script.LayoutImageRight, "Revenue grew this quarter.\nCosts stayed flat.",
+			[]string{"- Revenue is **up** 12%", "  - Driven by [new customers](https://example.com/customers)", "- Costs are flat", "| North | 10% |"}},
+		{"Comparison", script.LayoutTwoColumn, "", []string{"Before", script.ColumnSeparator, "After", "graph TD; A-->B"}},
+	}
+	for i, tt := range tests {
+		got := imported.Slides[i]
+		if got.Title != tt.title {
+			t.Errorf("Slide %d: expected title %q, got %q", i+1, tt.title, got.Title)
+		}
+		if got.Layout != tt.layout {
+			t.Errorf("Slide %d: expected layout %q, got %q", i+1, tt.layout, got.Layout)
+		}
+		if got.Transcription != tt.transcription {
+			t.Errorf("Slide %d: expected transcription %q, got %q", i+1, tt.transcription, got.Transcription)
+		}
+		for _, want := range tt.content {
+			if !strings.Contains(got.Content, want) {
+				t.Errorf("Slide %d: expected content to contain %q, got %q", i+1, want, got.Content)
+			}
+		}
+	}
+	if imported.Slides[1].Notes != "Mention the March contract." {
+		t.Errorf("Expected the private notes of slide 2 to survive, got %q", imported.Slides[1].Notes)
+	}
+	if imported.Slides[1].Image == "" {
+		t.Error("Expected the image of slide 2 to be imported")
+	}
+}
+
+func TestExportPPTXLineBreaks(t *testing.T) {
+	dir := t.TempDir()
+	s := &script.Script{
+		Title: "Deck",
+		Slides: []script.Slide{{
+			Title:    "Lines",
+			Content:  "• First line\n• Second line",
+			Duration: 5 * time.Second,
+			Source:   filepath.Join(dir, "deck.md"),
+		}},
+		BaseDir: dir,
+	}
+
+	deck := filepath.Join(dir, "deck.pptx")
+	if _, err := ExportPPTX(s, deck, PPTXOptions{}); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	slide := readParts(t, deck)["ppt/slides/slide1.xml"]
+	if !strings.Contains(slide, " line</a:t></a:r><a:br>") || strings.Count(slide, "<a:br>") != 1 {
+		t.Errorf("Expected a line break between the two lines, got %s", slide)
+	}
+
+	imported, err := importer.ImportPPTX(deck, filepath.Join(dir, "imported.md"), filepath.Join(dir, "assets"))
+	if err != nil {
+		t.Fatalf("Failed to import the exported deck: %v", err)
+	}
+	if got := imported.Slides[0].Content; got != "• First line\n• Second line" {
+		t.Errorf("Expected two lines, got %q", got)
+	}
+}
+
+func TestExportPPTXMissingImage(t *testing.T) {
+	dir := t.TempDir()
+	s := &script.Script{
+		Title: "Deck",
+		Slides: []script.Slide{{
+			Title:    "Missing",
+			Content:  "![diagram](missing.png)\n\n![remote](https://example.com/a.png)",
+			Duration: 5 * time.Second,
+			Source:   filepath.Join(dir, "deck.md"),
+		}},
+		BaseDir: dir,
+	}
+
+	warnings, err := ExportPPTX(s, filepath.Join(dir, "deck.pptx"), PPTXOptions{})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	for i, want := range []string{"image missing.png could not be read", "https://example.com/a.png is not a local file"} {
+		if !strings.Contains(warnings[i].Message, want) {
+			t.Errorf("Expected warning %d to contain %q, got %q", i+1, want, warnings[i].Message)
+		}
+	}
+
+	parts := readParts(t, filepath.Join(dir, "deck.pptx"))
+	if strings.Contains(parts["ppt/slides/slide1.xml"], "<p:transition") {
+		t.Error("Expected no auto-advance without AutoAdvance")
+	}
+}
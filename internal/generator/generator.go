@@ -6,10 +6,13 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/jmcarbo/rhesis/internal/d2renderer"
+	"github.com/jmcarbo/rhesis/internal/imageembed"
 	"github.com/jmcarbo/rhesis/internal/script"
 	"github.com/jmcarbo/rhesis/internal/styles"
 	"github.com/yuin/goldmark"
@@ -24,6 +27,7 @@ type HTMLGenerator struct {
 	template     *template.Template
 	styleManager *styles.StyleManager
 	markdown     goldmark.Markdown
+	speakerNotes bool
 }
 
 func NewHTMLGenerator() *HTMLGenerator {
@@ -34,6 +38,10 @@ func NewHTMLGenerator() *HTMLGenerator {
 		"safeHTML": func(s string) template.HTML {
 			return template.HTML(s)
 		},
+		// seconds formats a duration as a decimal number of seconds for data attributes
+		"seconds": func(d time.Duration) string {
+			return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+		},
 	})
 
 	// Create D2 extension
@@ -58,6 +66,7 @@ func NewHTMLGenerator() *HTMLGenerator {
 			),
 		),
 		&mermaid.Extender{},
+		imageembed.NewImageEmbedExtension(),
 	}
 
 	// Add D2 extension if available
@@ -83,6 +92,13 @@ func NewHTMLGenerator() *HTMLGenerator {
 	}
 }
 
+// SetSpeakerNotes sets whether the slides' private notes are embedded for the
+// presenter view. They are left out by default: anyone with the HTML file can
+// read embedded notes in its source.
+func (h *HTMLGenerator) SetSpeakerNotes(include bool) {
+	h.speakerNotes = include
+}
+
 func (h *HTMLGenerator) GeneratePresentation(s *script.Script, outputPath string, theme string, includeTranscription bool) error {
 	return h.GeneratePresentationWithAudio(s, outputPath, theme, includeTranscription, nil)
 }
@@ -97,6 +113,16 @@ func (h *HTMLGenerator) GeneratePresentationWithOptions(s *script.Script, output
 		return fmt.Errorf("failed to get style: %w", err)
 	}
 
+	viewport, err := s.Viewport()
+	if err != nil {
+		return err
+	}
+
+	slides, err := h.processSlidesWithAudio(s.Slides, audioFiles)
+	if err != nil {
+		return err
+	}
+
 	data := struct {
 		Script               *script.Script
 		Slides               []SlideData
@@ -104,9 +130,13 @@ func (h *HTMLGenerator) GeneratePresentationWithOptions(s *script.Script, output
 		IncludeTranscription bool
 		HasAudio             bool
 		BackgroundMode       bool
+		PresenterSlides      []PresenterSlide
+		Viewport             script.Viewport
 	}{
 		Script:               s,
-		Slides:               h.processSlidesWithAudio(s.Slides, audioFiles),
+		Viewport:             viewport,
+		Slides:               slides,
+		PresenterSlides:      h.presenterSlides(s.Slides),
 		Style:                template.CSS(styleCSS),
 		IncludeTranscription: includeTranscription,
 		HasAudio:             len(audioFiles) > 0,
@@ -127,68 +157,107 @@ type SlideData struct {
 	Index             int
 	ImageSrc          string
 	ContentHTML       template.HTML
+	ColumnsHTML       []template.HTML
 	TranscriptionHTML template.HTML
 	AudioSrc          string
+	// Cues holds the comma-separated cue positions as fractions of the narration
+	Cues string
+	// Narration is the length of the slide's audio in milliseconds, 0 without audio
+	Narration int64
+}
+
+// PresenterSlide holds what the presenter view shows for a slide besides the slide itself.
+// It is embedded as JSON, which keeps notes out of the audience view but not out
+// of the file's source; notes are empty unless SetSpeakerNotes enabled them.
+type PresenterSlide struct {
+	Title     string `json:"title"`
+	Notes     string `json:"notes"`
+	Narration string `json:"narration"`
 }
 
-func (h *HTMLGenerator) processSlides(slides []script.Slide) []SlideData {
+func (h *HTMLGenerator) presenterSlides(slides []script.Slide) []PresenterSlide {
+	result := make([]PresenterSlide, len(slides))
+	for i, slide := range slides {
+		result[i] = PresenterSlide{
+			Title:     slide.Title,
+			Narration: string(h.renderMarkdown(slide.Transcription)),
+		}
+		if h.speakerNotes {
+			result[i].Notes = string(h.renderMarkdown(slide.Notes))
+		}
+	}
+	return result
+}
+
+func (h *HTMLGenerator) processSlides(slides []script.Slide) ([]SlideData, error) {
 	return h.processSlidesWithAudio(slides, nil)
 }
 
-func (h *HTMLGenerator) processSlidesWithAudio(slides []script.Slide, audioFiles []string) []SlideData {
+// processSlidesWithAudio renders the slides. Local images are resolved against the
+// file each slide comes from and embedded; missing files are errors.
+func (h *HTMLGenerator) processSlidesWithAudio(slides []script.Slide, audioFiles []string) ([]SlideData, error) {
 	result := make([]SlideData, len(slides))
 	for i, slide := range slides {
+		source := &imageembed.Source{}
+		if slide.Source != "" {
+			source.Dir = filepath.Dir(slide.Source)
+		}
 		result[i] = SlideData{
 			Slide:             slide,
 			Index:             i,
-			ContentHTML:       h.renderMarkdown(slide.Content),
+			ContentHTML:       h.renderSlideMarkdown(slide.Content, source),
 			TranscriptionHTML: h.renderMarkdown(slide.Transcription),
 		}
+		if slide.Layout == script.LayoutTwoColumn {
+			for _, column := range slide.Columns() {
+				result[i].ColumnsHTML = append(result[i].ColumnsHTML, h.renderSlideMarkdown(column, source))
+			}
+		}
+		if fractions := slide.CueFractions(); len(fractions) > 0 {
+			cues := make([]string, len(fractions))
+			for j, fraction := range fractions {
+				cues[j] = strconv.FormatFloat(fraction, 'f', 4, 64)
+			}
+			result[i].Cues = strings.Join(cues, ",")
+			result[i].Narration = slide.NarrationDuration.Milliseconds()
+		}
+		if len(source.Missing) > 0 {
+			return nil, fmt.Errorf("slide %d (%s): image %q not found", i+1, slide.Title, source.Missing[0])
+		}
 		if slide.Image != "" {
-			result[i].ImageSrc = h.imageToBase64(slide.Image)
+			src, err := h.imageToBase64(slide.ResolvePath(slide.Image))
+			if err != nil {
+				return nil, fmt.Errorf("slide %d (%s): %w", i+1, slide.Title, err)
+			}
+			result[i].ImageSrc = src
 		}
 		if i < len(audioFiles) && audioFiles[i] != "" {
 			result[i].AudioSrc = h.audioToBase64(audioFiles[i])
 		}
 	}
-	return result
+	return result, nil
 }
 
 func (h *HTMLGenerator) renderMarkdown(content string) template.HTML {
+	return h.renderSlideMarkdown(content, nil)
+}
+
+// renderSlideMarkdown renders Markdown, embedding local images relative to source when given
+func (h *HTMLGenerator) renderSlideMarkdown(content string, source *imageembed.Source) template.HTML {
 	var buf bytes.Buffer
-	if err := h.markdown.Convert([]byte(content), &buf); err != nil {
+	var opts []parser.ParseOption
+	if source != nil {
+		opts = append(opts, parser.WithContext(imageembed.NewContext(source)))
+	}
+	if err := h.markdown.Convert([]byte(content), &buf, opts...); err != nil {
 		// If markdown rendering fails, return the original content escaped
 		return template.HTML(template.HTMLEscapeString(content))
 	}
 	return template.HTML(buf.String())
 }
 
-func (h *HTMLGenerator) imageToBase64(imagePath string) string {
-	data, err := os.ReadFile(imagePath)
-	if err != nil {
-		return ""
-	}
-
-	if len(data) == 0 {
-		return ""
-	}
-
-	ext := strings.ToLower(filepath.Ext(imagePath))
-	var mimeType string
-	switch ext {
-	case ".jpg", ".jpeg":
-		mimeType = "image/jpeg"
-	case ".png":
-		mimeType = "image/png"
-	case ".gif":
-		mimeType = "image/gif"
-	case ".webp":
-		mimeType = "image/webp"
-	default:
-		mimeType = "image/png"
-	}
-
-	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Encode(data))
+func (h *HTMLGenerator) imageToBase64(imagePath string) (string, error) {
+	return imageembed.DataURI(imagePath)
 }
 
 func (h *HTMLGenerator) audioToBase64(audioPath string) string {
@@ -201,7 +270,23 @@ func (h *HTMLGenerator) audioToBase64(audioPath string) string {
 		return ""
 	}
 
-	return fmt.Sprintf("data:audio/mpeg;base64,%s", base64Encode(data))
+	mimeType, ok := audioTypes[strings.ToLower(filepath.Ext(audioPath))]
+	if !ok {
+		mimeType = "audio/mpeg"
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Encode(data))
+}
+
+// audioTypes are the MIME types of narration files by extension; anything
+// else is embedded as MP3
+var audioTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".opus": "audio/ogg",
+	".ogg":  "audio/ogg",
+	".aac":  "audio/aac",
+	".m4a":  "audio/mp4",
+	".flac": "audio/flac",
 }
 
 func base64Encode(data []byte) string {
@@ -238,11 +323,14 @@ func base64Encode(data []byte) string {
 }
 
 const htmlTemplate = `<!DOCTYPE html>
-<html lang="en">
+<html lang="{{if .Script.Language}}{{.Script.Language}}{{else}}en{{end}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{.Script.Title}}</title>
+    {{if .Script.Author}}<meta name="author" content="{{.Script.Author}}">{{end}}
+    {{if .Script.Date}}<meta name="date" content="{{.Script.Date}}">{{end}}
+    {{if .Script.Tags}}<meta name="keywords" content="{{range $i, $tag := .Script.Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}">{{end}}
     <style>
         {{.Style}}
         {{if not .IncludeTranscription}}
@@ -264,18 +352,118 @@ const htmlTemplate = `<!DOCTYPE html>
             }
         }
         {{end}}
+
+        /* Fragments revealed step by step by narration cues */
+        .slide .fragment {
+            opacity: 0;
+            transition: opacity 0.4s ease;
+        }
+
+        .slide .fragment.visible {
+            opacity: 1;
+        }
+
+        /* Presenter view (the same file opened with #presenter) */
+        .presenter-view {
+            display: none;
+        }
+
+        body.presenter-mode .presentation-container,
+        body.presenter-mode .slide-counter,
+        body.presenter-mode .controls,
+        body.presenter-mode .progress-bar {
+            display: none;
+        }
+
+        body.presenter-mode .presenter-view {
+            display: grid;
+            grid-template-columns: 3fr 2fr;
+            grid-template-rows: auto 1fr auto;
+            grid-template-areas:
+                "current next"
+                "current notes"
+                "timer timer";
+            gap: 20px;
+            height: 100vh;
+            padding: 20px;
+            box-sizing: border-box;
+        }
+
+        .presenter-current { grid-area: current; }
+        .presenter-next { grid-area: next; }
+        .presenter-notes { grid-area: notes; overflow-y: auto; }
+
+        .presenter-frame {
+            position: relative;
+            overflow: hidden;
+            border: 1px solid rgba(128, 128, 128, 0.4);
+            border-radius: 8px;
+            padding: 20px;
+        }
+
+        .presenter-current .presenter-frame { height: calc(100% - 40px); zoom: 0.8; }
+        .presenter-next .presenter-frame { height: 30vh; zoom: 0.45; }
+
+        .presenter-frame .slide {
+            display: block;
+            animation: none;
+        }
+
+        .presenter-frame .slide .fragment {
+            opacity: 1;
+        }
+
+        .presenter-label {
+            font-size: 0.8em;
+            text-transform: uppercase;
+            letter-spacing: 1px;
+            opacity: 0.6;
+            margin: 10px 0 6px;
+        }
+
+        .presenter-timer {
+            grid-area: timer;
+            display: flex;
+            gap: 40px;
+            font-size: 1.4em;
+            font-variant-numeric: tabular-nums;
+        }
     </style>
 </head>
-<body>
+<body data-orientation="{{.Viewport.Orientation}}" style="--viewport-width: {{.Viewport.Width}}px; --viewport-height: {{.Viewport.Height}}px;">
     <div class="progress-bar" id="progressBar"></div>
     
     <div class="presentation-container">
         <div class="slide-area">
             {{range .Slides}}
-            <div class="slide" data-duration="{{.Duration}}" data-index="{{.Index}}" {{if .AudioSrc}}data-audio="{{safeURL .AudioSrc}}"{{end}}>
+            <div class="slide{{if .Layout}} layout-{{.Layout}}{{end}}" data-duration="{{seconds .Duration}}" data-index="{{.Index}}"{{if .Layout}} data-layout="{{.Layout}}"{{end}}{{if .Cues}} data-cues="{{.Cues}}"{{end}}{{if .Narration}} data-narration="{{.Narration}}"{{end}} {{if .AudioSrc}}data-audio="{{safeURL .AudioSrc}}"{{end}}>
+                {{if eq .Layout "two-column"}}
+                <h1>{{.Title}}</h1>
+                <div class="slide-columns">
+                    {{range .ColumnsHTML}}<div class="slide-column">{{.}}</div>{{end}}
+                </div>
+                {{if .ImageSrc}}<img src="{{safeURL .ImageSrc}}" alt="{{.Title}}">{{end}}
+                {{else if or (eq .Layout "image-left") (eq .Layout "image-right")}}
+                <h1>{{.Title}}</h1>
+                <div class="slide-columns">
+                    <div class="slide-column slide-media">{{if .ImageSrc}}<img src="{{safeURL .ImageSrc}}" alt="{{.Title}}">{{end}}</div>
+                    <div class="slide-column slide-content">{{.ContentHTML}}</div>
+                </div>
+                {{else if eq .Layout "full-bleed"}}
+                {{if .ImageSrc}}<img class="slide-background" src="{{safeURL .ImageSrc}}" alt="{{.Title}}">{{end}}
+                <div class="slide-overlay">
+                    <h1>{{.Title}}</h1>
+                    {{if .Content}}<div class="slide-content">{{.ContentHTML}}</div>{{end}}
+                </div>
+                {{else if eq .Layout "quote"}}
+                <blockquote class="slide-quote">{{.ContentHTML}}</blockquote>
+                <div class="slide-quote-source">{{.Title}}</div>
+                {{if .ImageSrc}}<img src="{{safeURL .ImageSrc}}" alt="{{.Title}}">{{end}}
+                {{else}}
                 <h1>{{.Title}}</h1>
                 {{if .Content}}<div class="slide-content">{{.ContentHTML}}</div>{{end}}
                 {{if .ImageSrc}}<img src="{{safeURL .ImageSrc}}" alt="{{.Title}}">{{end}}
+                {{end}}
             </div>
             {{end}}
         </div>
@@ -302,6 +490,29 @@ const htmlTemplate = `<!DOCTYPE html>
         <button class="btn" id="prevBtn" onclick="previousSlide()">Previous</button>
         <button class="btn" id="playBtn" onclick="togglePlayback()">Play</button>
         <button class="btn" id="nextBtn" onclick="nextSlide()">Next</button>
+        <button class="btn" id="presenterBtn" onclick="openPresenterView()">Presenter</button>
+    </div>
+
+    <div class="presenter-view" id="presenterView">
+        <div class="presenter-current">
+            <div class="presenter-label">Current slide</div>
+            <div class="presenter-frame" id="presenterCurrent"></div>
+        </div>
+        <div class="presenter-next">
+            <div class="presenter-label">Next slide</div>
+            <div class="presenter-frame" id="presenterNext"></div>
+        </div>
+        <div class="presenter-notes">
+            <div class="presenter-label">Notes</div>
+            <div id="presenterNotes"></div>
+            <div class="presenter-label">Narration</div>
+            <div id="presenterNarration"></div>
+        </div>
+        <div class="presenter-timer">
+            <span>Slide <strong id="presenterSlideNumber">1</strong> / {{len .Slides}}</span>
+            <span>Elapsed <strong id="presenterElapsed">00:00</strong></span>
+            <span>Remaining <strong id="presenterRemaining">00:00</strong></span>
+        </div>
     </div>
 
     <script>
@@ -311,6 +522,7 @@ const htmlTemplate = `<!DOCTYPE html>
         let startTime = null;
         let totalDuration = 0;
         let currentAudio = null;
+        let cueTimers = [];
         
         // Expose variables to window for player to monitor
         window.isPlaying = false;
@@ -324,14 +536,67 @@ const htmlTemplate = `<!DOCTYPE html>
         
         // Calculate total duration
         slides.forEach(slide => {
-            totalDuration += parseInt(slide.dataset.duration) * 1000;
+            totalDuration += parseFloat(slide.dataset.duration) * 1000;
         });
         
         // Expose totalDuration to window for player
         window.totalDuration = totalDuration;
         
-        function showSlide(index) {
+        // Slides with cue markers in their narration reveal their list items
+        // (or, without lists, their content blocks) one cue at a time
+        function initFragments() {
+            slides.forEach(slide => {
+                if (!slide.dataset.cues) return;
+                let fragments = slide.querySelectorAll('.slide-content li, .slide-column li, .slide-quote li');
+                if (fragments.length === 0) {
+                    fragments = slide.querySelectorAll('.slide-content > *, .slide-column > *, .slide-quote > *');
+                }
+                fragments.forEach(fragment => fragment.classList.add('fragment'));
+            });
+        }
+        
+        function resetFragments(slide, visible) {
+            slide.querySelectorAll('.fragment').forEach(fragment => {
+                fragment.classList.toggle('visible', visible);
+            });
+        }
+        
+        function revealNextFragment(slide) {
+            const fragment = slide.querySelector('.fragment:not(.visible)');
+            if (!fragment) return false;
+            fragment.classList.add('visible');
+            return true;
+        }
+        
+        function clearCueTimers() {
+            cueTimers.forEach(timer => clearTimeout(timer));
+            cueTimers = [];
+        }
+        
+        // Schedule fragment reveals at the cue positions of the narration. Cues are
+        // fractions of the narration text; they are timed against the generated audio
+        // when there is one, and against the slide duration otherwise.
+        function scheduleCues(index) {
+            clearCueTimers();
+            const slide = slides[index];
+            if (!slide.dataset.cues) return;
+            
+            const length = slide.dataset.narration ? parseInt(slide.dataset.narration) : parseFloat(slide.dataset.duration) * 1000;
+            const cues = slide.dataset.cues.split(',').map(parseFloat);
+            cues.forEach((cue, i) => {
+                cueTimers.push(setTimeout(() => {
+                    revealNextFragment(slide);
+                    // The last cue reveals whatever is left
+                    if (i === cues.length - 1) {
+                        while (revealNextFragment(slide)) {}
+                    }
+                }, cue * length));
+            });
+        }
+        
+        function showSlide(index, revealAll) {
             slides.forEach(slide => slide.classList.remove('active'));
+            clearCueTimers();
             
             const transcriptionContent = document.getElementById('transcriptionContent');
             if (transcriptionContent) {
@@ -346,7 +611,14 @@ const htmlTemplate = `<!DOCTYPE html>
             }
             
             if (index >= 0 && index < slides.length) {
+                if (presenterChannel) {
+                    presenterChannel.postMessage({ type: 'slide', index: index, playing: isPlaying });
+                }
                 slides[index].classList.add('active');
+                resetFragments(slides[index], !!revealAll);
+                if (isPlaying) {
+                    scheduleCues(index);
+                }
                 if (transcriptionContent && transcriptionSlides[index]) {
                     transcriptionSlides[index].style.display = 'block';
                 }
@@ -371,6 +643,10 @@ const htmlTemplate = `<!DOCTYPE html>
         }
         
         function nextSlide() {
+            // When presenting by hand, step through the fragments first
+            if (!isPlaying && revealNextFragment(slides[currentSlideIndex])) {
+                return;
+            }
             if (currentSlideIndex < slides.length - 1) {
                 showSlide(currentSlideIndex + 1);
             }
@@ -378,7 +654,7 @@ const htmlTemplate = `<!DOCTYPE html>
         
         function previousSlide() {
             if (currentSlideIndex > 0) {
-                showSlide(currentSlideIndex - 1);
+                showSlide(currentSlideIndex - 1, true);
             }
         }
         
@@ -403,34 +679,41 @@ const htmlTemplate = `<!DOCTYPE html>
             // Play audio for the current slide if available (skip in background mode)
             const isBackgroundMode = {{.BackgroundMode}};
             const currentSlide = slides[currentSlideIndex];
+            resetFragments(currentSlide, false);
+            scheduleCues(currentSlideIndex);
             if (currentSlide.dataset.audio && !isBackgroundMode) {
                 currentAudio = new Audio(currentSlide.dataset.audio);
                 currentAudio.play().catch(e => console.error('Failed to play audio for first slide:', e));
             }
             
-            function advanceSlide() {
-                if (!isPlaying) return;
-                
-                const currentSlide = slides[currentSlideIndex];
-                const duration = parseInt(currentSlide.dataset.duration) * 1000;
-                
-                console.log('Playing slide', currentSlideIndex + 1, 'of', slides.length, 'for', duration, 'ms');
-                
-                slideTimer = setTimeout(() => {
-                    if (currentSlideIndex < slides.length - 1) {
-                        console.log('Advancing to next slide');
-                        nextSlide();
-                        advanceSlide();
-                    } else {
-                        console.log('Reached last slide, stopping presentation');
-                        stopPresentation();
-                    }
-                }, duration);
-                
-                updateProgress();
+            scheduleAdvance();
+            updateProgress();
+        }
+        
+        // scheduleAdvance (re)starts the timer that moves on from the current
+        // slide after its duration; every jump during playback restarts it
+        function scheduleAdvance() {
+            if (slideTimer) {
+                clearTimeout(slideTimer);
+                slideTimer = null;
             }
+            if (!isPlaying) return;
             
-            advanceSlide();
+            const currentSlide = slides[currentSlideIndex];
+            const duration = parseFloat(currentSlide.dataset.duration) * 1000;
+            
+            console.log('Playing slide', currentSlideIndex + 1, 'of', slides.length, 'for', duration, 'ms');
+            
+            slideTimer = setTimeout(() => {
+                if (currentSlideIndex < slides.length - 1) {
+                    console.log('Advancing to next slide');
+                    nextSlide();
+                    scheduleAdvance();
+                } else {
+                    console.log('Reached last slide, stopping presentation');
+                    stopPresentation();
+                }
+            }, duration);
         }
         
         function stopPresentation() {
@@ -441,6 +724,7 @@ const htmlTemplate = `<!DOCTYPE html>
                 clearTimeout(slideTimer);
                 slideTimer = null;
             }
+            clearCueTimers();
             
             // Stop any playing audio
             if (currentAudio) {
@@ -467,25 +751,142 @@ const htmlTemplate = `<!DOCTYPE html>
         
         // Keyboard controls
         document.addEventListener('keydown', (e) => {
+            if (isPresenterView) {
+                handlePresenterKey(e);
+                return;
+            }
             switch(e.key) {
                 case 'ArrowRight':
                 case ' ':
                     e.preventDefault();
                     nextSlide();
+                    scheduleAdvance();
                     break;
                 case 'ArrowLeft':
                     e.preventDefault();
                     previousSlide();
+                    scheduleAdvance();
                     break;
                 case 'Enter':
                     e.preventDefault();
                     togglePlayback();
                     break;
+                case 'p':
+                case 'P':
+                    e.preventDefault();
+                    openPresenterView();
+                    break;
             }
         });
         
+        // Presenter view: the same file opened with #presenter shows the current and
+        // next slide, speaker notes and timers. Both windows stay in sync through a
+        // BroadcastChannel; the audience window owns playback and audio.
+        function openPresenterView() {
+            const url = location.href.split('#')[0] + '#presenter';
+            window.open(url, 'rhesis-presenter', 'width=1280,height=800');
+        }
+        
+        function handlePresenterKey(e) {
+            if (!presenterChannel) return;
+            switch(e.key) {
+                case 'ArrowRight':
+                case ' ':
+                    e.preventDefault();
+                    presenterChannel.postMessage({ type: 'goto', index: Math.min(presenterIndex + 1, slides.length - 1) });
+                    break;
+                case 'ArrowLeft':
+                    e.preventDefault();
+                    presenterChannel.postMessage({ type: 'goto', index: Math.max(presenterIndex - 1, 0) });
+                    break;
+                case 'Enter':
+                    e.preventDefault();
+                    presenterChannel.postMessage({ type: 'toggle' });
+                    break;
+            }
+        }
+        
+        function formatClock(ms) {
+            const totalSeconds = Math.max(0, Math.round(ms / 1000));
+            const minutes = Math.floor(totalSeconds / 60);
+            const seconds = totalSeconds % 60;
+            return String(minutes).padStart(2, '0') + ':' + String(seconds).padStart(2, '0');
+        }
+        
+        function renderPresenterSlide(frame, index) {
+            frame.innerHTML = '';
+            if (index < 0 || index >= slides.length) return;
+            const clone = slides[index].cloneNode(true);
+            clone.classList.add('active');
+            frame.appendChild(clone);
+        }
+        
+        function updatePresenterView(index) {
+            presenterIndex = index;
+            renderPresenterSlide(document.getElementById('presenterCurrent'), index);
+            renderPresenterSlide(document.getElementById('presenterNext'), index + 1);
+            const data = presenterSlides[index] || {};
+            document.getElementById('presenterNotes').innerHTML = data.notes || '';
+            document.getElementById('presenterNarration').innerHTML = data.narration || '';
+            document.getElementById('presenterSlideNumber').textContent = index + 1;
+        }
+        
+        function initPresenterView() {
+            document.body.classList.add('presenter-mode');
+            updatePresenterView(0);
+            
+            const openedAt = Date.now();
+            setInterval(() => {
+                const elapsed = Date.now() - (presenterStartTime || openedAt);
+                document.getElementById('presenterElapsed').textContent = formatClock(elapsed);
+                document.getElementById('presenterRemaining').textContent = formatClock(totalDuration - elapsed);
+            }, 250);
+            
+            if (presenterChannel) {
+                presenterChannel.onmessage = (event) => {
+                    const msg = event.data;
+                    if (msg.type === 'slide') {
+                        if (msg.playing && !presenterStartTime) {
+                            presenterStartTime = Date.now();
+                        }
+                        updatePresenterView(msg.index);
+                    }
+                };
+                presenterChannel.postMessage({ type: 'hello' });
+            }
+        }
+        
+        const presenterSlides = {{.PresenterSlides}};
+        const isPresenterView = location.hash === '#presenter';
+        const presenterChannel = ('BroadcastChannel' in window) ? new BroadcastChannel('rhesis:' + location.pathname) : null;
+        let presenterIndex = 0;
+        let presenterStartTime = null;
+        
+        if (presenterChannel && !isPresenterView) {
+            presenterChannel.onmessage = (event) => {
+                const msg = event.data;
+                switch (msg.type) {
+                    case 'hello':
+                        presenterChannel.postMessage({ type: 'slide', index: currentSlideIndex, playing: isPlaying });
+                        break;
+                    case 'goto':
+                        showSlide(msg.index);
+                        scheduleAdvance();
+                        break;
+                    case 'toggle':
+                        togglePlayback();
+                        break;
+                }
+            };
+        }
+        
         // Initialize
-        showSlide(0);
+        initFragments();
+        if (isPresenterView) {
+            initPresenterView();
+        } else {
+            showSlide(0);
+        }
         
         // Auto-start indicator
         window.addEventListener('load', () => {
@@ -2,8 +2,11 @@ package generator
 
 import (
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jmcarbo/rhesis/internal/script"
 )
@@ -26,13 +29,13 @@ func TestGeneratePresentation(t *testing.T) {
 				Title:         "Slide 1",
 				Content:       "Content 1",
 				Transcription: "Transcription 1",
-				Duration:      10,
+				Duration:      10 * time.Second,
 			},
 			{
 				Title:         "Slide 2",
 				Content:       "Content 2",
 				Transcription: "Transcription 2",
-				Duration:      15,
+				Duration:      15 * time.Second,
 			},
 		},
 	}
@@ -117,7 +120,7 @@ func TestGeneratePresentationWithImage(t *testing.T) {
 				Title:         "Slide with Image",
 				Image:         tmpImageFile.Name(),
 				Transcription: "This slide has an image",
-				Duration:      10,
+				Duration:      10 * time.Second,
 			},
 		},
 	}
@@ -181,6 +184,24 @@ func TestBase64Encode(t *testing.T) {
 	}
 }
 
+func TestAudioToBase64(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewHTMLGenerator()
+	for name, prefix := range map[string]string{
+		"narration.mp3": "data:audio/mpeg;base64,",
+		"narration.wav": "data:audio/wav;base64,",
+		"narration.bin": "data:audio/mpeg;base64,",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := gen.audioToBase64(path); got != prefix+"YXVkaW8=" {
+			t.Errorf("%s: expected %s..., got %s", name, prefix, got)
+		}
+	}
+}
+
 func TestProcessSlides(t *testing.T) {
 	generator := NewHTMLGenerator()
 	slides := []script.Slide{
@@ -188,7 +209,10 @@ func TestProcessSlides(t *testing.T) {
 		{Title: "Slide 2", Content: "Content 2"},
 	}
 
-	result := generator.processSlides(slides)
+	result, err := generator.processSlides(slides)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	if len(result) != 2 {
 		t.Errorf("Expected 2 processed slides, got %d", len(result))
@@ -216,3 +240,303 @@ func TestGeneratePresentationInvalidPath(t *testing.T) {
 		t.Error("Expected error for invalid path")
 	}
 }
+
+func TestGeneratePresentationLayouts(t *testing.T) {
+	testScript := &script.Script{
+		Title: "Layouts",
+		Slides: []script.Slide{
+			{Title: "Cover", Content: "Subtitle", Layout: script.LayoutTitle, Duration: 5 * time.Second},
+			{Title: "Compare", Content: "Left column\n|||\nRight column", Layout: script.LayoutTwoColumn, Duration: 5 * time.Second},
+			{Title: "Wisdom", Content: "Simplicity is prerequisite for reliability.", Layout: script.LayoutQuote, Duration: 5 * time.Second},
+			{Title: "Plain", Content: "Default layout", Duration: 5 * time.Second},
+		},
+	}
+
+	generator := NewHTMLGenerator()
+	tmpFile, err := os.CreateTemp("", "test*.html")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	if err := generator.GeneratePresentation(testScript, tmpFile.Name(), "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		`class="slide layout-title"`,
+		`class="slide layout-two-column"`,
+		`<div class="slide-column"><p>Left column</p>`,
+		`<div class="slide-column"><p>Right column</p>`,
+		`<blockquote class="slide-quote">`,
+		`<div class="slide-quote-source">Wisdom</div>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(html, e) {
+			t.Errorf("Expected HTML to contain %s", e)
+		}
+	}
+
+	if strings.Contains(html, "|||") {
+		t.Error("Expected column separator to be removed from output")
+	}
+}
+
+func TestGeneratePresentationPresenterView(t *testing.T) {
+	testScript := &script.Script{
+		Title: "Presenter",
+		Slides: []script.Slide{
+			{Title: "Slide 1", Content: "Visible content", Transcription: "Spoken words", Notes: "Secret reminder", Duration: 5 * time.Second},
+			{Title: "Slide 2", Content: "More content", Duration: 5 * time.Second},
+		},
+	}
+
+	generator := NewHTMLGenerator()
+	tmpFile, err := os.CreateTemp("", "test*.html")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	if err := generator.GeneratePresentation(testScript, tmpFile.Name(), "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	html := string(content)
+
+	if !strings.Contains(html, `id="presenterView"`) {
+		t.Error("Expected presenter view markup in HTML")
+	}
+	if !strings.Contains(html, "BroadcastChannel") {
+		t.Error("Expected presenter view to sync through BroadcastChannel")
+	}
+	if !regexp.MustCompile(`case 'goto':\s*showSlide\(msg\.index\);\s*scheduleAdvance\(\);`).MatchString(html) {
+		t.Error("Expected jumps from the presenter view to restart the slide timer")
+	}
+	if strings.Contains(html, "Secret reminder") {
+		t.Error("Expected notes to stay out of the file unless enabled")
+	}
+
+	generator.SetSpeakerNotes(true)
+	if err := generator.GeneratePresentation(testScript, tmpFile.Name(), "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err = os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	html = string(content)
+	if !strings.Contains(html, `"notes":"\u003cp\u003eSecret reminder`) {
+		t.Error("Expected notes to be embedded as presenter data")
+	}
+	if strings.Contains(html, "<p>Secret reminder</p>") {
+		t.Error("Expected notes to stay out of the audience markup")
+	}
+}
+
+func TestGeneratePresentationViewport(t *testing.T) {
+	testScript := &script.Script{
+		Title:  "Shorts",
+		Aspect: "9:16",
+		Slides: []script.Slide{{Title: "Slide 1", Content: "Vertical", Duration: 5 * time.Second}},
+	}
+
+	generator := NewHTMLGenerator()
+	outputPath := filepath.Join(t.TempDir(), "shorts.html")
+	if err := generator.GeneratePresentation(testScript, outputPath, "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if !strings.Contains(string(content), `<body data-orientation="portrait" style="--viewport-width: 1080px; --viewport-height: 1920px;">`) {
+		t.Error("Expected the body to carry the portrait viewport")
+	}
+
+	testScript.Resolution = "1920x1080"
+	if err := generator.GeneratePresentation(testScript, outputPath, "modern", false); err == nil {
+		t.Error("Expected an error for a resolution that does not match the aspect ratio")
+	}
+}
+
+func TestGeneratePresentationEmbedsAssets(t *testing.T) {
+	dir := t.TempDir()
+	deckDir := filepath.Join(dir, "decks")
+	if err := os.MkdirAll(filepath.Join(deckDir, "img"), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	images := map[string]string{
+		"cover.png":   "png",
+		"inline.png":  "png",
+		"diagram.svg": `<svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+	}
+	for name, data := range images {
+		if err := os.WriteFile(filepath.Join(deckDir, "img", name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write image: %v", err)
+		}
+	}
+
+	testScript := &script.Script{
+		Title: "Assets",
+		Slides: []script.Slide{
+			{
+				Title:    "Pictures",
+				Content:  "![Inline](img/inline.png)\n\n![Diagram](img/diagram.svg)\n\n![Remote](https://example.com/remote.png)",
+				Image:    "img/cover.png",
+				Duration: 5 * time.Second,
+				Source:   filepath.Join(deckDir, "foo.md"),
+			},
+		},
+	}
+
+	outputPath := filepath.Join(dir, "presentation.html")
+	generator := NewHTMLGenerator()
+	if err := generator.GeneratePresentation(testScript, outputPath, "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	html := string(content)
+
+	if strings.Contains(html, `src="img/`) {
+		t.Error("Expected no relative image references in self-contained output")
+	}
+	if !strings.Contains(html, `<img src="data:image/png;base64,cG5n" alt="Inline"`) {
+		t.Error("Expected inline PNG image to be embedded")
+	}
+	if !strings.Contains(html, `<img src="data:image/svg+xml;base64,`) {
+		t.Error("Expected inline SVG image to be embedded with the SVG MIME type")
+	}
+	if !strings.Contains(html, `src="https://example.com/remote.png"`) {
+		t.Error("Expected remote image to be left untouched")
+	}
+}
+
+func TestGeneratePresentationMissingAssets(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		slide script.Slide
+	}{
+		{
+			name:  "missing slide image",
+			slide: script.Slide{Title: "Broken", Image: "missing.png", Source: filepath.Join(dir, "deck.md")},
+		},
+		{
+			name:  "missing inline image",
+			slide: script.Slide{Title: "Broken", Content: "![Gone](missing.png)", Source: filepath.Join(dir, "deck.md")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testScript := &script.Script{Title: "Missing", Slides: []script.Slide{tt.slide}}
+			generator := NewHTMLGenerator()
+			err := generator.GeneratePresentation(testScript, filepath.Join(dir, "out.html"), "modern", false)
+			if err == nil || !strings.Contains(err.Error(), "missing.png") {
+				t.Errorf("Expected missing image error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGeneratePresentationCues(t *testing.T) {
+	testScript := &script.Script{
+		Title: "Cues",
+		Slides: []script.Slide{
+			{
+				Title:             "Agenda",
+				Content:           "- One\n- Two",
+				Transcription:     "One. Two.",
+				Cues:              []int{0, 5},
+				Duration:          5 * time.Second,
+				NarrationDuration: 4500 * time.Millisecond,
+			},
+			{Title: "Plain", Content: "Nothing to reveal", Transcription: "Plain.", Duration: 5 * time.Second},
+		},
+	}
+
+	tmpFile, err := os.CreateTemp("", "test*.html")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	generator := NewHTMLGenerator()
+	if err := generator.GeneratePresentation(testScript, tmpFile.Name(), "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	html := string(content)
+
+	if !strings.Contains(html, `data-cues="0.0000,0.5556" data-narration="4500"`) {
+		t.Error("Expected cue fractions and narration length on the slide")
+	}
+	if strings.Count(html, "data-cues=") != 1 {
+		t.Error("Expected only the slide with cues to carry data-cues")
+	}
+	for _, fn := range []string{"function initFragments()", "function scheduleCues(index)", "function revealNextFragment(slide)"} {
+		if !strings.Contains(html, fn) {
+			t.Errorf("Expected %s in generated script", fn)
+		}
+	}
+}
+
+func TestGeneratePresentationSubSecondDurations(t *testing.T) {
+	testScript := &script.Script{
+		Title: "Timing",
+		Slides: []script.Slide{
+			{Title: "Quick", Content: "Fast", Duration: 2500 * time.Millisecond},
+			{Title: "Long", Content: "Slow", Duration: 90 * time.Second},
+		},
+	}
+
+	tmpFile, err := os.CreateTemp("", "test*.html")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	generator := NewHTMLGenerator()
+	if err := generator.GeneratePresentation(testScript, tmpFile.Name(), "modern", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	html := string(content)
+
+	for _, attr := range []string{`data-duration="2.5"`, `data-duration="90"`} {
+		if !strings.Contains(html, attr) {
+			t.Errorf("Expected %s in generated HTML", attr)
+		}
+	}
+	if strings.Contains(html, "parseInt(slide.dataset.duration)") {
+		t.Error("Expected slide durations to be parsed as decimals")
+	}
+}
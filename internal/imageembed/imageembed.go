@@ -0,0 +1,135 @@
+// Package imageembed provides a goldmark extension that embeds local images
+// referenced from Markdown as data URIs, so generated HTML is self-contained.
+package imageembed
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// sourceKey holds the *Source of the Markdown being converted
+var sourceKey = parser.NewContextKey()
+
+// Source describes where the Markdown being converted comes from
+type Source struct {
+	// Dir is the directory relative image paths are resolved against
+	Dir string
+	// Missing collects the image references that could not be embedded
+	Missing []string
+}
+
+// NewContext returns a parser context that embeds images relative to src.
+// Pass it to goldmark with parser.WithContext.
+func NewContext(src *Source) parser.Context {
+	ctx := parser.NewContext()
+	ctx.Set(sourceKey, src)
+	return ctx
+}
+
+// ImageEmbed is a goldmark extension that rewrites local image destinations to data URIs
+type ImageEmbed struct{}
+
+// NewImageEmbedExtension creates a new image embedding extension for goldmark
+func NewImageEmbedExtension() goldmark.Extender {
+	return &ImageEmbed{}
+}
+
+// Extend implements goldmark.Extender
+func (e *ImageEmbed) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithASTTransformers(
+			util.Prioritized(&imageTransformer{}, 200),
+		),
+	)
+}
+
+// imageTransformer replaces the destination of local images with their data URI
+type imageTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *imageTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	src, ok := pc.Get(sourceKey).(*Source)
+	if !ok {
+		// Without a source there is nothing to resolve paths against
+		return
+	}
+
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		image, ok := n.(*ast.Image)
+		if !ok || !IsLocal(string(image.Destination)) {
+			return ast.WalkContinue, nil
+		}
+
+		ref := string(image.Destination)
+		path := ref
+		if src.Dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(src.Dir, path)
+		}
+
+		dataURI, err := DataURI(path)
+		if err != nil {
+			src.Missing = append(src.Missing, ref)
+			return ast.WalkContinue, nil
+		}
+		image.Destination = []byte(dataURI)
+		return ast.WalkContinue, nil
+	})
+}
+
+// IsLocal reports whether an image reference points to a file on disk
+// rather than to a URL, a data URI or an anchor
+func IsLocal(ref string) bool {
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "//") {
+		return false
+	}
+	if strings.HasPrefix(ref, "data:") || strings.Contains(ref, "://") {
+		return false
+	}
+	return true
+}
+
+// MIMEType returns the MIME type of an image based on its extension
+func MIMEType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	case ".svg":
+		return "image/svg+xml"
+	default:
+		return "image/png"
+	}
+}
+
+// DataURI reads an image file and returns it as a base64 data URI
+func DataURI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("image %q not found", path)
+		}
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+
+	if len(data) == 0 {
+		return "", fmt.Errorf("image %q is empty", path)
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", MIMEType(path), base64.StdEncoding.EncodeToString(data)), nil
+}
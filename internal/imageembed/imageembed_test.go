@@ -0,0 +1,91 @@
+package imageembed
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestIsLocal(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected bool
+	}{
+		{"images/a.png", true},
+		{"/abs/a.svg", true},
+		{"https://example.com/a.png", false},
+		{"//cdn.example.com/a.png", false},
+		{"data:image/png;base64,AAAA", false},
+		{"#anchor", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsLocal(tt.ref); got != tt.expected {
+			t.Errorf("IsLocal(%q): expected %v, got %v", tt.ref, tt.expected, got)
+		}
+	}
+}
+
+func TestMIMEType(t *testing.T) {
+	tests := map[string]string{
+		"a.png":  "image/png",
+		"a.JPG":  "image/jpeg",
+		"a.jpeg": "image/jpeg",
+		"a.gif":  "image/gif",
+		"a.webp": "image/webp",
+		"a.svg":  "image/svg+xml",
+		"a.bin":  "image/png",
+	}
+
+	for path, expected := range tests {
+		if got := MIMEType(path); got != expected {
+			t.Errorf("MIMEType(%q): expected %s, got %s", path, expected, got)
+		}
+	}
+}
+
+func TestImageEmbedExtension(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.svg"), []byte("<svg/>"), 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(NewImageEmbedExtension()))
+	source := &Source{Dir: dir}
+
+	var buf bytes.Buffer
+	input := "![Logo](logo.svg) ![Gone](gone.png) ![Remote](https://example.com/a.png)"
+	if err := md.Convert([]byte(input), &buf, parser.WithContext(NewContext(source))); err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+
+	html := buf.String()
+	if !strings.Contains(html, `src="data:image/svg+xml;base64,PHN2Zy8+"`) {
+		t.Errorf("Expected SVG data URI, got %s", html)
+	}
+	if !strings.Contains(html, `src="https://example.com/a.png"`) {
+		t.Errorf("Expected remote image untouched, got %s", html)
+	}
+	if len(source.Missing) != 1 || source.Missing[0] != "gone.png" {
+		t.Errorf("Expected gone.png to be reported missing, got %v", source.Missing)
+	}
+}
+
+func TestImageEmbedWithoutSource(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(NewImageEmbedExtension()))
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte("![Logo](logo.png)"), &buf); err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `src="logo.png"`) {
+		t.Errorf("Expected image left untouched without a source, got %s", buf.String())
+	}
+}
@@ -0,0 +1,473 @@
+// Package importer converts slide decks made with other presentation tools
+// (Marp, reveal.js, PowerPoint) into rhesis scripts. Constructs that have no
+// rhesis equivalent are reported as warnings on the imported script.
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+	"github.com/jmcarbo/rhesis/internal/styles"
+)
+
+// Format identifies the tool a deck was written for
+type Format string
+
+const (
+	FormatMarp   Format = "marp"
+	FormatReveal Format = "reveal"
+)
+
+// CodeUnsupported is the diagnostic code of constructs the importer cannot convert
+const CodeUnsupported = "unsupported-construct"
+
+// defaultSlideTime is the duration given to every imported slide
+const defaultSlideTime = 10 * time.Second
+
+// Formats returns the names of the supported source formats
+func Formats() []string {
+	return []string{string(FormatMarp), string(FormatReveal), string(FormatPPTX)}
+}
+
+// Import reads the Markdown deck at path and converts it to a script. Speaker
+// notes become the transcription of their slide and the front matter becomes
+// script metadata. Everything that could not be converted is listed in the
+// script's Diagnostics as warnings. PowerPoint decks extract their pictures and
+// are imported with ImportPPTX instead.
+func Import(path string, format Format) (*script.Script, error) {
+	if format == FormatPPTX {
+		return nil, fmt.Errorf("PowerPoint decks are imported with ImportPPTX")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open deck: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve deck path: %w", err)
+	}
+
+	im := &importer{
+		path: absPath,
+		script: &script.Script{
+			DefaultTime: defaultSlideTime,
+			BaseDir:     filepath.Dir(absPath),
+		},
+	}
+
+	var convert func([]line)
+	switch format {
+	case FormatMarp:
+		im.ignoredKeys = map[string]bool{"marp": true}
+		convert = im.importMarp
+	case FormatReveal:
+		convert = im.importReveal
+	default:
+		return nil, fmt.Errorf("unknown format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	body, err := im.frontMatter(splitLines(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	convert(body)
+
+	sort.SliceStable(im.script.Diagnostics, func(i, j int) bool {
+		return im.script.Diagnostics[i].Line < im.script.Diagnostics[j].Line
+	})
+
+	if im.script.Title == "" {
+		im.script.Title = im.firstHeading
+	}
+	if im.script.Title == "" {
+		im.script.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return im.script, nil
+}
+
+// line is a line of the deck with its 1-based line number
+type line struct {
+	num  int
+	text string
+}
+
+func splitLines(data string) []line {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []line
+	for i, text := range strings.Split(data, "\n") {
+		lines = append(lines, line{num: i + 1, text: text})
+	}
+	return lines
+}
+
+// rawSlide is a slide of the source deck before conversion
+type rawSlide struct {
+	line    int
+	content []line
+	// notes are the speaker notes, narrated as the transcription
+	notes []string
+	// private are remarks for the presenter only, kept as the slide's Notes
+	private []string
+	layout  string
+	image   string
+}
+
+// importer holds the state of a single Import run
+type importer struct {
+	path   string
+	script *script.Script
+
+	// firstHeading is the text of the first level-1 heading of the deck, the
+	// title of the script when the front matter has none
+	firstHeading string
+
+	// ignoredKeys are front matter keys of the source format that carry no
+	// information for a script, like Marp's `marp: true`
+	ignoredKeys map[string]bool
+}
+
+func (im *importer) warnf(lineNum int, format string, args ...interface{}) {
+	im.script.Diagnostics = append(im.script.Diagnostics, script.Diagnostic{
+		Severity: script.SeverityWarning,
+		File:     im.path,
+		Line:     lineNum,
+		Column:   1,
+		Code:     CodeUnsupported,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// frontMatter applies the YAML front matter, if any, and returns the remaining lines
+func (im *importer) frontMatter(lines []line) ([]line, error) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0].text) != "---" {
+		return lines, nil
+	}
+
+	for i := 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i].text)
+		if trimmed != "---" && trimmed != "..." {
+			continue
+		}
+
+		var data strings.Builder
+		for _, l := range lines[1:i] {
+			data.WriteString(l.text)
+			data.WriteString("\n")
+		}
+		var values map[string]interface{}
+		if err := yaml.Unmarshal([]byte(data.String()), &values); err != nil {
+			return nil, fmt.Errorf("invalid front matter: %w", err)
+		}
+
+		for _, key := range sortedKeys(values) {
+			im.applyMetadata(lines[0].num, key, values[key])
+		}
+		return lines[i+1:], nil
+	}
+
+	// No closing delimiter: it was a slide separator after all
+	return lines, nil
+}
+
+// applyMetadata maps a front matter key of the source deck to script metadata
+func (im *importer) applyMetadata(lineNum int, key string, value interface{}) {
+	s := im.script
+	switch key {
+	case "title":
+		s.Title = fmt.Sprint(value)
+	case "author":
+		s.Author = fmt.Sprint(value)
+	case "date":
+		if t, ok := value.(time.Time); ok {
+			s.Date = t.Format("2006-01-02")
+		} else {
+			s.Date = fmt.Sprint(value)
+		}
+	case "lang", "language":
+		s.Language = fmt.Sprint(value)
+	case "tags", "keywords":
+		s.Tags = stringList(value)
+	case "theme":
+		theme := fmt.Sprint(value)
+		if isRhesisTheme(theme) {
+			s.Theme = theme
+		} else {
+			im.warnf(lineNum, "theme %q has no rhesis equivalent; use -style to pick one of %s",
+				theme, strings.Join(rhesisThemes(), ", "))
+		}
+	default:
+		if im.ignoredKeys[key] {
+			return
+		}
+		if _, ok := formatKeys[key]; ok {
+			im.warnf(lineNum, "front matter key %q is not supported", key)
+			return
+		}
+		if s.Extra == nil {
+			s.Extra = make(map[string]interface{})
+		}
+		s.Extra[key] = value
+	}
+}
+
+// formatKeys are front matter keys that configure Marp or reveal.js rendering.
+// They are reported instead of being kept as free-form metadata.
+var formatKeys = map[string]struct{}{
+	// Marp
+	"paginate": {}, "header": {}, "footer": {}, "class": {}, "size": {}, "math": {},
+	"style": {}, "headingDivider": {}, "backgroundColor": {}, "backgroundImage": {},
+	"backgroundPosition": {}, "backgroundRepeat": {}, "backgroundSize": {}, "color": {},
+	// reveal.js / reveal-md
+	"revealOptions": {}, "highlightTheme": {}, "separator": {}, "verticalSeparator": {},
+	"notesSeparator": {}, "css": {}, "scripts": {}, "preprocessor": {},
+}
+
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	case string:
+		var result []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+		return result
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func rhesisThemes() []string {
+	themes := styles.NewStyleManager().GetAvailableThemes()
+	sort.Strings(themes)
+	return themes
+}
+
+func isRhesisTheme(name string) bool {
+	for _, theme := range rhesisThemes() {
+		if theme == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fencePattern matches the opening or closing line of a fenced code block
+var fencePattern = regexp.MustCompile("^\\s*(```|~~~)")
+
+// headingPattern matches an ATX heading
+var headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// splitSlides splits lines at separator lines outside fenced code blocks
+func splitSlides(lines []line, isSeparator func(string) bool) [][]line {
+	var slides [][]line
+	var current []line
+	inFence := false
+	for _, l := range lines {
+		if fencePattern.MatchString(l.text) {
+			inFence = !inFence
+		}
+		if !inFence && isSeparator(strings.TrimSpace(l.text)) {
+			slides = append(slides, current)
+			current = nil
+			continue
+		}
+		current = append(current, l)
+	}
+	return append(slides, current)
+}
+
+// comment is an HTML comment found in a slide
+type comment struct {
+	line int
+	text string
+}
+
+// extractComments removes HTML comments outside fenced code blocks from the
+// lines and returns them. Comments may span several lines.
+func extractComments(lines []line) ([]line, []comment) {
+	var kept []line
+	var comments []comment
+	var open *comment
+	inFence := false
+
+	for _, l := range lines {
+		if open == nil && fencePattern.MatchString(l.text) {
+			inFence = !inFence
+		}
+		if inFence {
+			kept = append(kept, l)
+			continue
+		}
+
+		text := l.text
+		var rest strings.Builder
+		stripped := false
+		for text != "" {
+			if open != nil {
+				end := strings.Index(text, "-->")
+				if end < 0 {
+					open.text += text + "\n"
+					text = ""
+					break
+				}
+				open.text += text[:end]
+				stripped = true
+				comments = append(comments, comment{line: open.line, text: strings.TrimSpace(open.text)})
+				open = nil
+				text = text[end+len("-->"):]
+				continue
+			}
+			start := strings.Index(text, "<!--")
+			if start < 0 {
+				rest.WriteString(text)
+				break
+			}
+			rest.WriteString(text[:start])
+			open = &comment{line: l.num}
+			stripped = true
+			text = text[start+len("<!--"):]
+		}
+
+		// Drop lines that held nothing but comments
+		if stripped || open != nil {
+			if strings.TrimSpace(rest.String()) == "" {
+				continue
+			}
+			kept = append(kept, line{num: l.num, text: strings.TrimRight(rest.String(), " \t")})
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if open != nil {
+		comments = append(comments, comment{line: open.line, text: strings.TrimSpace(open.text)})
+	}
+	return kept, comments
+}
+
+// buildSlide converts the content of a source slide to a script slide: its
+// first heading becomes the title and level-2 headings in the content are
+// demoted, since every level-2 heading starts a new slide in a script.
+func (im *importer) buildSlide(raw rawSlide) script.Slide {
+	slide := script.Slide{
+		Layout:   raw.layout,
+		Image:    raw.image,
+		Duration: im.script.DefaultTime,
+		Source:   im.path,
+	}
+
+	var content []string
+	var lineNums []int
+	inFence := false
+	for _, l := range raw.content {
+		if fencePattern.MatchString(l.text) {
+			inFence = !inFence
+		}
+		if inFence && (strings.TrimSpace(l.text) == "---" || strings.HasPrefix(l.text, "## ")) {
+			im.warnf(l.num, "line %q in a code block would end the slide content in a script", l.text)
+		}
+		if !inFence {
+			if m := headingPattern.FindStringSubmatch(l.text); m != nil {
+				if slide.Title == "" {
+					slide.Title = m[2]
+					if len(m[1]) == 1 && im.firstHeading == "" {
+						im.firstHeading = m[2]
+					}
+					continue
+				}
+				if len(m[1]) == 2 {
+					im.warnf(l.num, "level-2 heading %q demoted to level 3; in a script it would start a new slide", m[2])
+					l.text = "#" + l.text
+				}
+			}
+		}
+		content = append(content, l.text)
+		lineNums = append(lineNums, l.num)
+	}
+
+	im.reportHTML(strings.Join(content, "\n"), lineNums)
+	slide.Content = strings.TrimSpace(strings.Join(content, "\n"))
+	if slide.Title == "" {
+		slide.Title = fmt.Sprintf("Slide %d", len(im.script.Slides)+1)
+	}
+
+	var notes []string
+	for _, note := range raw.notes {
+		if note = strings.TrimSpace(note); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	slide.Transcription = strings.Join(notes, "\n\n")
+	slide.Notes = strings.TrimSpace(strings.Join(raw.private, "\n\n"))
+	return slide
+}
+
+// reportHTML warns about every HTML block and inline tag in the content of a
+// slide; slides are rendered without raw HTML, so it would be dropped.
+// lineNums holds the deck line of every line of source.
+func (im *importer) reportHTML(source string, lineNums []int) {
+	src := []byte(source)
+	lineAt := func(offset int) int {
+		i := strings.Count(source[:offset], "\n")
+		if i < len(lineNums) {
+			return lineNums[i]
+		}
+		return 0
+	}
+
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var segments *text.Segments
+		switch node := n.(type) {
+		case *ast.HTMLBlock:
+			segments = node.Lines()
+		case *ast.RawHTML:
+			segments = node.Segments
+		default:
+			return ast.WalkContinue, nil
+		}
+		if segments.Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		first := segments.At(0)
+		html := strings.TrimSpace(string(first.Value(src)))
+		if segments.Len() > 1 {
+			html += " ..."
+		}
+		im.warnf(lineAt(first.Start), "HTML %q is not rendered in slides and will be dropped", html)
+		return ast.WalkContinue, nil
+	})
+}
+
+// isBlank reports whether a source slide has no content at all, like the
+// empty slide before a leading separator
+func (raw rawSlide) isBlank() bool {
+	for _, l := range raw.content {
+		if strings.TrimSpace(l.text) != "" {
+			return false
+		}
+	}
+	return len(raw.notes) == 0 && raw.image == ""
+}
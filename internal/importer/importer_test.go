@@ -0,0 +1,358 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+func writeDeck(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deck.md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write deck: %v", err)
+	}
+	return path
+}
+
+func warnings(s *script.Script) []string {
+	var messages []string
+	for _, d := range s.Diagnostics {
+		messages = append(messages, d.Message)
+	}
+	return messages
+}
+
+func expectWarnings(t *testing.T, s *script.Script, expected ...string) {
+	t.Helper()
+	messages := warnings(s)
+	if len(messages) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %d: %q", len(expected), len(messages), messages)
+	}
+	for i, want := range expected {
+		if !strings.Contains(messages[i], want) {
+			t.Errorf("Expected warning %d to contain %q, got %q", i, want, messages[i])
+		}
+		if s.Diagnostics[i].Code != CodeUnsupported || s.Diagnostics[i].Severity != script.SeverityWarning {
+			t.Errorf("Expected an %s warning, got %+v", CodeUnsupported, s.Diagnostics[i])
+		}
+	}
+}
+
+func TestImportMarp(t *testing.T) {
+	path := writeDeck(t, `---
+marp: true
+theme: gaia
+paginate: true
+author: Jane Doe
+date: 2024-05-01
+keywords: go, training
+---
+
+<!-- _class: lead -->
+
+# Go Basics
+
+Getting started
+
+<!-- Welcome everyone. -->
+
+---
+
+<!-- class: quote -->
+
+## Agenda
+
+![bg left:40%](img/cover.png)
+
+- Types
+- Functions
+
+<!--
+First the types,
+then functions.
+-->
+
+---
+
+## Sizing
+
+![w:200 Diagram](img/diagram.png)
+
+## Details
+
+<!-- _backgroundColor: #fff -->
+`)
+
+	s, err := Import(path, FormatMarp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.Title != "Go Basics" {
+		t.Errorf("Expected title from the first heading, got %q", s.Title)
+	}
+	if s.Author != "Jane Doe" || s.Date != "2024-05-01" || strings.Join(s.Tags, ",") != "go,training" {
+		t.Errorf("Expected front matter metadata, got author %q, date %q, tags %v", s.Author, s.Date, s.Tags)
+	}
+	if s.Theme != "" {
+		t.Errorf("Expected Marp theme not to be kept, got %q", s.Theme)
+	}
+	if _, ok := s.Extra["marp"]; ok {
+		t.Error("Expected the marp key to be dropped")
+	}
+
+	if len(s.Slides) != 3 {
+		t.Fatalf("Expected 3 slides, got %d", len(s.Slides))
+	}
+
+	first := s.Slides[0]
+	if first.Title != "Go Basics" || first.Layout != script.LayoutTitle || first.Content != "Getting started" {
+		t.Errorf("Unexpected first slide: %+v", first)
+	}
+	if first.Transcription != "Welcome everyone." {
+		t.Errorf("Expected the presenter note as transcription, got %q", first.Transcription)
+	}
+
+	second := s.Slides[1]
+	if second.Image != "img/cover.png" || second.Layout != script.LayoutImageLeft {
+		t.Errorf("Expected the background image to become an image-left Image, got %q (%s)", second.Image, second.Layout)
+	}
+	if second.Content != "- Types\n- Functions" {
+		t.Errorf("Expected the background image to be removed from the content, got %q", second.Content)
+	}
+	if second.Transcription != "First the types,\nthen functions." {
+		t.Errorf("Expected multi-line note as transcription, got %q", second.Transcription)
+	}
+
+	third := s.Slides[2]
+	if third.Layout != script.LayoutQuote {
+		t.Errorf("Expected the class directive to carry over to later slides, got %q", third.Layout)
+	}
+	if third.Content != "![Diagram](img/diagram.png)\n\n### Details" {
+		t.Errorf("Unexpected content %q", third.Content)
+	}
+
+	expectWarnings(t, s,
+		`front matter key "paginate"`,
+		`theme "gaia"`,
+		`image keywords "w:200"`,
+		`heading "Details" demoted`,
+		`directive "_backgroundColor"`,
+	)
+}
+
+func TestImportReveal(t *testing.T) {
+	path := writeDeck(t, `---
+title: Reveal Deck
+revealOptions:
+  transition: fade
+---
+
+# Hello
+
+<!-- .slide: data-background="img/bg.jpg" -->
+
+Note: Welcome to the talk.
+It will be short.
+
+---
+
+## Points
+
+- A <!-- .element: class="fragment" -->
+- B
+
+<!-- check the clock -->
+
+--
+
+## Vertical
+
+<!-- .slide: data-transition="zoom" -->
+
+`+"```yaml\nnote: not a note\n```"+`
+
+NOTES:
+Stacked slides are flattened.
+`)
+
+	s, err := Import(path, FormatReveal)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.Title != "Reveal Deck" {
+		t.Errorf("Expected title from the front matter, got %q", s.Title)
+	}
+	if len(s.Slides) != 3 {
+		t.Fatalf("Expected 3 slides, got %d", len(s.Slides))
+	}
+
+	first := s.Slides[0]
+	if first.Image != "img/bg.jpg" || first.Layout != script.LayoutFullBleed {
+		t.Errorf("Expected the background image as a full-bleed Image, got %q (%s)", first.Image, first.Layout)
+	}
+	if first.Transcription != "Welcome to the talk.\nIt will be short." {
+		t.Errorf("Expected the notes as transcription, got %q", first.Transcription)
+	}
+
+	second := s.Slides[1]
+	if second.Content != "- A\n- B" || second.Notes != "check the clock" {
+		t.Errorf("Expected plain comments as private notes, got content %q, notes %q", second.Content, second.Notes)
+	}
+
+	third := s.Slides[2]
+	if third.Title != "Vertical" || !strings.Contains(third.Content, "note: not a note") {
+		t.Errorf("Expected notes separators in code blocks to be ignored, got %+v", third)
+	}
+	if third.Transcription != "Stacked slides are flattened." {
+		t.Errorf("Unexpected transcription %q", third.Transcription)
+	}
+
+	expectWarnings(t, s,
+		`front matter key "revealOptions"`,
+		`fragment classes`,
+		`slide attribute "data-transition"`,
+	)
+}
+
+func TestImportProducesValidScript(t *testing.T) {
+	path := writeDeck(t, "# Deck\n\nIntro\n\n<!-- Say hi. -->\n\n---\n\n## Next\n\nMore\n")
+
+	s, err := Import(path, FormatMarp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out := filepath.Join(filepath.Dir(path), "script.md")
+	data, err := s.Markdown()
+	if err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	parsed, err := script.ParseScript(out)
+	if err != nil {
+		t.Fatalf("Imported script does not parse: %v", err)
+	}
+	if len(parsed.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", parsed.Diagnostics)
+	}
+	if len(parsed.Slides) != 2 || parsed.Slides[0].Transcription != "Say hi." || parsed.Slides[1].Content != "More" {
+		t.Errorf("Unexpected parsed slides: %+v", parsed.Slides)
+	}
+}
+
+func TestImportReportsHTML(t *testing.T) {
+	path := writeDeck(t, `# Deck
+
+<style>
+h1 { color: red; }
+</style>
+
+<div class="columns">
+
+Text with a<br>break
+
+</div>
+
+`+"```html\n<p>in code</p>\n```\n")
+
+	s, err := Import(path, FormatMarp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectWarnings(t, s,
+		`HTML "<style> ..."`,
+		`HTML "<div class=\"columns\">"`,
+		`HTML "<br>"`,
+		`HTML "</div>"`,
+	)
+	for i, line := range []int{3, 7, 9, 11} {
+		if s.Diagnostics[i].Line != line {
+			t.Errorf("Warning %d: expected line %d, got %d", i, line, s.Diagnostics[i].Line)
+		}
+	}
+}
+
+func TestImportEscapesDirectiveLines(t *testing.T) {
+	path := writeDeck(t, `# Deck
+
+Include: the basics
+Image: a sunset over the bay
+
+<!--
+Duration: about an hour.
+Notes: none.
+-->
+
+---
+
+## Next
+
+More
+`)
+
+	s, err := Import(path, FormatMarp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out := filepath.Join(filepath.Dir(path), "script.md")
+	data, err := s.Markdown()
+	if err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	parsed, err := script.ParseScript(out)
+	if err != nil {
+		t.Fatalf("Imported script does not parse: %v", err)
+	}
+	if len(parsed.Diagnostics) != 0 || len(parsed.Includes) != 0 {
+		t.Errorf("Expected no diagnostics or includes, got %v and %v", parsed.Diagnostics, parsed.Includes)
+	}
+	if len(parsed.Slides) != 2 {
+		t.Fatalf("Expected 2 slides, got %d:\n%s", len(parsed.Slides), data)
+	}
+	first := parsed.Slides[0]
+	if first.Content != s.Slides[0].Content || first.Image != "" {
+		t.Errorf("Expected content %q without an image, got %q and image %q", s.Slides[0].Content, first.Content, first.Image)
+	}
+	if first.Transcription != "Duration: about an hour.\nNotes: none." || first.FixedDuration || first.Notes != "" {
+		t.Errorf("Expected the transcription to stay text, got %q (fixed duration %v, notes %q)", first.Transcription, first.FixedDuration, first.Notes)
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	path := writeDeck(t, "# Deck\n")
+	if _, err := Import(path, Format("keynote")); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestImportRejectsPPTX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	if err := os.WriteFile(path, []byte("not a deck"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(path, FormatPPTX); err == nil {
+		t.Error("Expected PowerPoint decks to be rejected")
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected nothing written next to the deck, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// marpSeparator matches the horizontal rules Marp splits slides at
+var marpSeparator = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,}|(- ){2,}-)$`)
+
+// marpImagePattern matches Markdown images; Marp puts its image keywords
+// (bg, left, w:200px, ...) in the alternative text
+var marpImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?[^)]*\)`)
+
+// marpDirectives are the directives Marp reads from HTML comments. A comment
+// made only of these keys (optionally prefixed with _) is a directive; any
+// other comment is a presenter note.
+var marpDirectives = map[string]bool{
+	// Global directives
+	"theme": true, "style": true, "headingDivider": true, "size": true, "math": true,
+	"title": true, "author": true, "description": true, "image": true, "keywords": true,
+	"url": true, "lang": true, "marp": true,
+	// Local directives
+	"paginate": true, "header": true, "footer": true, "class": true, "color": true,
+	"backgroundColor": true, "backgroundImage": true, "backgroundPosition": true,
+	"backgroundRepeat": true, "backgroundSize": true,
+}
+
+// marpClassLayouts maps Marp theme classes to the closest slide layout
+var marpClassLayouts = map[string]string{
+	"lead": script.LayoutTitle,
+}
+
+// importMarp converts a Marp deck: slides are split at horizontal rules,
+// directives come from HTML comments and every other comment is a presenter
+// note, which becomes the transcription.
+func (im *importer) importMarp(lines []line) {
+	// Local directives without the _ prefix apply to the following slides too
+	inheritedClass := ""
+	for _, chunk := range splitSlides(lines, marpSeparator.MatchString) {
+		content, comments := extractComments(chunk)
+		raw := rawSlide{content: content}
+		if len(chunk) > 0 {
+			raw.line = chunk[0].num
+		}
+
+		class := inheritedClass
+		for _, c := range comments {
+			directives, ok := parseMarpDirectives(c.text)
+			if !ok {
+				raw.notes = append(raw.notes, c.text)
+				continue
+			}
+			for _, key := range sortedKeys(directives) {
+				value := directives[key]
+				switch name := strings.TrimPrefix(key, "_"); name {
+				case "class":
+					class = stringValue(value)
+					if !strings.HasPrefix(key, "_") {
+						inheritedClass = class
+					}
+				case "marp":
+				case "theme", "title", "author", "lang", "keywords", "description", "image", "url":
+					im.applyMetadata(c.line, name, value)
+				default:
+					im.warnf(c.line, "Marp directive %q is not supported", key)
+				}
+			}
+		}
+		if class != "" {
+			raw.layout = im.marpLayout(raw.line, class)
+		}
+
+		im.marpImages(&raw)
+		if raw.isBlank() {
+			continue
+		}
+		im.script.Slides = append(im.script.Slides, im.buildSlide(raw))
+	}
+}
+
+// parseMarpDirectives decodes a comment as Marp directives. It reports false
+// when the comment is a presenter note instead.
+func parseMarpDirectives(text string) (map[string]interface{}, bool) {
+	var directives map[string]interface{}
+	if err := yaml.Unmarshal([]byte(text), &directives); err != nil || len(directives) == 0 {
+		return nil, false
+	}
+	for key := range directives {
+		if !marpDirectives[strings.TrimPrefix(key, "_")] {
+			return nil, false
+		}
+	}
+	return directives, true
+}
+
+// marpLayout maps the classes of a slide to a layout. Classes named after a
+// rhesis layout are used as they are.
+func (im *importer) marpLayout(lineNum int, class string) string {
+	layout := ""
+	for _, name := range strings.Fields(class) {
+		mapped, ok := marpClassLayouts[name]
+		if !ok && script.IsValidLayout(name) {
+			mapped, ok = name, true
+		}
+		if !ok {
+			im.warnf(lineNum, "Marp class %q is not supported", name)
+			continue
+		}
+		if layout == "" {
+			layout = mapped
+		}
+	}
+	return layout
+}
+
+// marpImages turns the first background image of the slide (![bg](...)) into
+// its Image, with the layout given by the bg position, and strips image
+// keywords rhesis does not understand from the other images.
+func (im *importer) marpImages(raw *rawSlide) {
+	var content []line
+	for _, l := range raw.content {
+		text := marpImagePattern.ReplaceAllStringFunc(l.text, func(match string) string {
+			m := marpImagePattern.FindStringSubmatch(match)
+			keywords, alt := splitMarpKeywords(m[1])
+			if len(keywords) == 0 {
+				return match
+			}
+
+			background := false
+			position := ""
+			var unsupported []string
+			for _, keyword := range keywords {
+				switch {
+				case keyword == "bg":
+					background = true
+				case keyword == "left" || strings.HasPrefix(keyword, "left:"):
+					position = script.LayoutImageLeft
+				case keyword == "right" || strings.HasPrefix(keyword, "right:"):
+					position = script.LayoutImageRight
+				default:
+					unsupported = append(unsupported, keyword)
+				}
+			}
+			if len(unsupported) > 0 {
+				im.warnf(l.num, "Marp image keywords %q are not supported", strings.Join(unsupported, " "))
+			}
+
+			if !background {
+				return "![" + alt + "](" + m[2] + ")"
+			}
+			if raw.image != "" {
+				im.warnf(l.num, "only the first background image of a slide is kept; %q is inlined", m[2])
+				return "![" + alt + "](" + m[2] + ")"
+			}
+			raw.image = m[2]
+			if position != "" {
+				raw.layout = position
+			} else if raw.layout == "" {
+				raw.layout = script.LayoutFullBleed
+			}
+			return ""
+		})
+		if text != l.text && strings.TrimSpace(text) == "" {
+			continue
+		}
+		content = append(content, line{num: l.num, text: text})
+	}
+	raw.content = content
+}
+
+// marpKeywordPattern matches the image keywords of Marp's image syntax
+var marpKeywordPattern = regexp.MustCompile(`^(bg|left|right|vertical|contain|cover|fit|auto|\d+(\.\d+)?%|` +
+	`(w|h|width|height|left|right|blur|brightness|contrast|drop-shadow|hue-rotate|invert|opacity|saturate|sepia|grayscale):.*|` +
+	`blur|brightness|contrast|drop-shadow|grayscale|hue-rotate|invert|opacity|saturate|sepia)$`)
+
+// splitMarpKeywords separates Marp image keywords from the alternative text
+func splitMarpKeywords(alt string) ([]string, string) {
+	var keywords, words []string
+	for _, field := range strings.Fields(alt) {
+		if marpKeywordPattern.MatchString(field) {
+			keywords = append(keywords, field)
+		} else {
+			words = append(words, field)
+		}
+	}
+	return keywords, strings.Join(words, " ")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		return strings.Join(stringList(list), " ")
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,630 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// FormatPPTX is a PowerPoint deck (Office Open XML)
+const FormatPPTX Format = "pptx"
+
+// browserImageExtensions are the image formats the generated HTML can display
+var browserImageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true,
+}
+
+// ImportPPTX converts a PowerPoint deck into a script that is meant to be
+// saved at scriptPath. Slide titles become slide titles, body text becomes
+// Markdown bullets, speaker notes become the transcription (and private notes
+// after a "Notes:" paragraph) and embedded images
+// are extracted to assetsDir and referenced relative to scriptPath. Anything
+// that could not be converted (charts, media, hidden slides, ...) is listed in
+// the script's Diagnostics as warnings.
+func ImportPPTX(deckPath, scriptPath, assetsDir string) (*script.Script, error) {
+	archive, err := zip.OpenReader(deckPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open deck: %w", err)
+	}
+	defer archive.Close()
+
+	absPath, err := filepath.Abs(deckPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve deck path: %w", err)
+	}
+	absScript, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve script path: %w", err)
+	}
+
+	im := &pptxImporter{
+		importer: &importer{
+			path: absPath,
+			script: &script.Script{
+				DefaultTime: defaultSlideTime,
+				BaseDir:     filepath.Dir(absScript),
+			},
+		},
+		files:      make(map[string]*zip.File),
+		scriptPath: absScript,
+		assetsDir:  assetsDir,
+		extracted:  make(map[string]string),
+	}
+	for _, f := range archive.File {
+		im.files[f.Name] = f
+	}
+
+	if err := im.importDeck(); err != nil {
+		return nil, err
+	}
+
+	if im.script.Title == "" && len(im.script.Slides) > 0 {
+		im.script.Title = im.script.Slides[0].Title
+	}
+	if im.script.Title == "" {
+		im.script.Title = strings.TrimSuffix(filepath.Base(deckPath), filepath.Ext(deckPath))
+	}
+	return im.script, nil
+}
+
+// pptxImporter holds the state of a single ImportPPTX run
+type pptxImporter struct {
+	*importer
+
+	files      map[string]*zip.File
+	scriptPath string
+	assetsDir  string
+
+	// extracted maps media parts to their path relative to the script
+	extracted map[string]string
+}
+
+// slideWarnf reports a construct of the given slide (1-based) that was not converted
+func (im *pptxImporter) slideWarnf(slide int, format string, args ...interface{}) {
+	im.script.Diagnostics = append(im.script.Diagnostics, script.Diagnostic{
+		Severity: script.SeverityWarning,
+		File:     im.path,
+		Code:     CodeUnsupported,
+		Message:  fmt.Sprintf("slide %d: %s", slide, fmt.Sprintf(format, args...)),
+	})
+}
+
+type pptxRelationships struct {
+	Relationships []struct {
+		ID         string `xml:"Id,attr"`
+		Type       string `xml:"Type,attr"`
+		Target     string `xml:"Target,attr"`
+		TargetMode string `xml:"TargetMode,attr"`
+	} `xml:"Relationship"`
+}
+
+// pptxRel is a resolved relationship of a part
+type pptxRel struct {
+	kind     string
+	target   string
+	external bool
+}
+
+type pptxPresentation struct {
+	SlideIDs []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sldIdLst>sldId"`
+}
+
+type pptxCoreProperties struct {
+	Title    string `xml:"title"`
+	Creator  string `xml:"creator"`
+	Keywords string `xml:"keywords"`
+	Language string `xml:"language"`
+}
+
+type pptxSlide struct {
+	Show       string    `xml:"show,attr"`
+	Tree       pptxGroup `xml:"cSld>spTree"`
+	Transition *struct{} `xml:"transition"`
+	Timing     *struct{} `xml:"timing"`
+}
+
+type pptxLayout struct {
+	Type string `xml:"type,attr"`
+}
+
+type pptxGroup struct {
+	Shapes   []pptxShape   `xml:"sp"`
+	Pictures []pptxPicture `xml:"pic"`
+	Groups   []pptxGroup   `xml:"grpSp"`
+	Frames   []pptxFrame   `xml:"graphicFrame"`
+}
+
+type pptxShape struct {
+	Placeholder *struct {
+		Type string `xml:"type,attr"`
+	} `xml:"nvSpPr>nvPr>ph"`
+	Offset pptxOffset    `xml:"spPr>xfrm>off"`
+	Text   *pptxTextBody `xml:"txBody"`
+}
+
+type pptxOffset struct {
+	X int64 `xml:"x,attr"`
+	Y int64 `xml:"y,attr"`
+}
+
+type pptxPicture struct {
+	Properties struct {
+		Description string `xml:"descr,attr"`
+	} `xml:"nvPicPr>cNvPr"`
+	Video *struct{} `xml:"nvPicPr>nvPr>videoFile"`
+	Audio *struct{} `xml:"nvPicPr>nvPr>audioFile"`
+	Blip  struct {
+		Embed string `xml:"embed,attr"`
+		Link  string `xml:"link,attr"`
+	} `xml:"blipFill>blip"`
+	Offset pptxOffset `xml:"spPr>xfrm>off"`
+}
+
+type pptxFrame struct {
+	Data struct {
+		URI   string `xml:"uri,attr"`
+		Table *struct {
+			Rows []struct {
+				Cells []struct {
+					Text pptxTextBody `xml:"txBody"`
+				} `xml:"tc"`
+			} `xml:"tr"`
+		} `xml:"tbl"`
+	} `xml:"graphic>graphicData"`
+}
+
+type pptxTextBody struct {
+	Paragraphs []pptxParagraph `xml:"p"`
+}
+
+type pptxParagraph struct {
+	Properties struct {
+		Level    int       `xml:"lvl,attr"`
+		NoBullet *struct{} `xml:"buNone"`
+		Bullet   *struct{} `xml:"buChar"`
+		Numbered *struct{} `xml:"buAutoNum"`
+	} `xml:"pPr"`
+	Runs []pptxRun `xml:",any"`
+}
+
+// pptxRun is a child of a paragraph; only runs (r), fields (fld) and line
+// breaks (br) carry text
+type pptxRun struct {
+	XMLName    xml.Name
+	Properties struct {
+		Bold   string `xml:"b,attr"`
+		Italic string `xml:"i,attr"`
+		Link   *struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"hlinkClick"`
+	} `xml:"rPr"`
+	Text string `xml:"t"`
+}
+
+// readXML decodes a part of the archive
+func (im *pptxImporter) readXML(name string, v interface{}) error {
+	f, ok := im.files[name]
+	if !ok {
+		return fmt.Errorf("part %s not found", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("invalid part %s: %w", name, err)
+	}
+	return nil
+}
+
+// relationships returns the relationships of a part keyed by id, with
+// internal targets resolved to part names
+func (im *pptxImporter) relationships(part string) map[string]pptxRel {
+	rels := make(map[string]pptxRel)
+	var doc pptxRelationships
+	relsPart := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	if _, ok := im.files[relsPart]; !ok {
+		return rels
+	}
+	if err := im.readXML(relsPart, &doc); err != nil {
+		return rels
+	}
+	for _, r := range doc.Relationships {
+		rel := pptxRel{kind: path.Base(r.Type), target: r.Target, external: r.TargetMode == "External"}
+		if !rel.external {
+			rel.target = path.Join(path.Dir(part), r.Target)
+			if strings.HasPrefix(r.Target, "/") {
+				rel.target = strings.TrimPrefix(r.Target, "/")
+			}
+		}
+		rels[r.ID] = rel
+	}
+	return rels
+}
+
+func (im *pptxImporter) importDeck() error {
+	const presentationPart = "ppt/presentation.xml"
+	var presentation pptxPresentation
+	if err := im.readXML(presentationPart, &presentation); err != nil {
+		return fmt.Errorf("not a PowerPoint deck: %w", err)
+	}
+
+	var core pptxCoreProperties
+	if err := im.readXML("docProps/core.xml", &core); err == nil {
+		im.script.Title = strings.TrimSpace(core.Title)
+		im.script.Author = strings.TrimSpace(core.Creator)
+		im.script.Language = strings.TrimSpace(core.Language)
+		if core.Keywords != "" {
+			im.script.Tags = stringList(strings.ReplaceAll(core.Keywords, ";", ","))
+		}
+	}
+
+	rels := im.relationships(presentationPart)
+	for i, id := range presentation.SlideIDs {
+		rel, ok := rels[id.RID]
+		if !ok {
+			return fmt.Errorf("slide %d: relationship %s not found", i+1, id.RID)
+		}
+		if err := im.importSlide(i+1, rel.target); err != nil {
+			return fmt.Errorf("slide %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// pptxBlock is a text shape of a slide converted to Markdown
+type pptxBlock struct {
+	markdown string
+	offset   pptxOffset
+}
+
+func (im *pptxImporter) importSlide(number int, part string) error {
+	var doc pptxSlide
+	if err := im.readXML(part, &doc); err != nil {
+		return err
+	}
+	if doc.Show == "0" || doc.Show == "false" {
+		im.slideWarnf(number, "hidden slide skipped")
+		return nil
+	}
+	if doc.Transition != nil {
+		im.slideWarnf(number, "slide transition is not supported")
+	}
+	if doc.Timing != nil {
+		im.slideWarnf(number, "animations are not supported; add %s cue markers to the transcription to reveal content step by step",
+			script.CueMarker)
+	}
+
+	rels := im.relationships(part)
+	slide := script.Slide{
+		Duration: im.script.DefaultTime,
+		Source:   im.scriptPath,
+	}
+
+	var layoutType string
+	for _, rel := range rels {
+		switch rel.kind {
+		case "slideLayout":
+			var layout pptxLayout
+			if err := im.readXML(rel.target, &layout); err == nil {
+				layoutType = layout.Type
+			}
+		case "notesSlide":
+			slide.Transcription, slide.Notes = im.notes(rel.target)
+		}
+	}
+
+	var body []pptxBlock
+	var images []string
+	var imageOffset *pptxOffset
+	centeredTitle := false
+
+	var walk func(group pptxGroup)
+	walk = func(group pptxGroup) {
+		for _, shape := range group.Shapes {
+			if shape.Text == nil {
+				continue
+			}
+			kind := ""
+			if shape.Placeholder != nil {
+				kind = shape.Placeholder.Type
+				if kind == "" {
+					kind = "body"
+				}
+			}
+			switch kind {
+			case "title", "ctrTitle":
+				if slide.Title == "" {
+					slide.Title = plainText(*shape.Text)
+					centeredTitle = kind == "ctrTitle"
+					continue
+				}
+			case "dt", "ftr", "sldNum", "hdr":
+				continue
+			}
+			bullets := kind == "body" || kind == "obj"
+			if text := im.markdown(*shape.Text, rels, bullets); text != "" {
+				body = append(body, pptxBlock{markdown: text, offset: shape.Offset})
+			}
+		}
+
+		for _, pic := range group.Pictures {
+			if pic.Video != nil || pic.Audio != nil {
+				im.slideWarnf(number, "embedded media is not supported")
+				continue
+			}
+			rel, ok := rels[pic.Blip.Embed]
+			if pic.Blip.Embed == "" {
+				rel, ok = rels[pic.Blip.Link], false
+			}
+			if !ok || rel.external {
+				im.slideWarnf(number, "linked picture %q is not supported", rel.target)
+				continue
+			}
+			image, err := im.extract(number, rel.target)
+			if err != nil {
+				continue
+			}
+			if slide.Image == "" {
+				slide.Image = image
+				offset := pic.Offset
+				imageOffset = &offset
+				continue
+			}
+			images = append(images, fmt.Sprintf("![%s](%s)", escapeMarkdown(pic.Properties.Description), image))
+		}
+
+		for _, frame := range group.Frames {
+			if frame.Data.Table != nil {
+				body = append(body, pptxBlock{markdown: im.table(frame)})
+				continue
+			}
+			im.slideWarnf(number, "%s graphic is not supported", path.Base(frame.Data.URI))
+		}
+
+		for _, g := range group.Groups {
+			walk(g)
+		}
+	}
+	walk(doc.Tree)
+
+	if slide.Title == "" {
+		slide.Title = fmt.Sprintf("Slide %d", number)
+	}
+
+	var blocks []string
+	for _, block := range body {
+		blocks = append(blocks, block.markdown)
+	}
+
+	switch {
+	case strings.HasPrefix(layoutType, "two") && len(blocks) == 2:
+		slide.Layout = script.LayoutTwoColumn
+		blocks = []string{blocks[0], script.ColumnSeparator, blocks[1]}
+	case layoutType == "title" || centeredTitle:
+		slide.Layout = script.LayoutTitle
+	case layoutType == "secHead":
+		slide.Layout = script.LayoutSection
+	case imageOffset != nil && len(body) > 0:
+		slide.Layout = script.LayoutImageRight
+		if imageOffset.X < body[0].offset.X {
+			slide.Layout = script.LayoutImageLeft
+		}
+	}
+
+	slide.Content = strings.Join(append(blocks, images...), "\n\n")
+	im.script.Slides = append(im.script.Slides, slide)
+	return nil
+}
+
+// notes returns the text of the body placeholder of a notes slide. Paragraphs
+// after a "Notes:" paragraph, as rhesis exports them, are the private notes.
+func (im *pptxImporter) notes(part string) (transcription, notes string) {
+	var doc pptxSlide
+	if err := im.readXML(part, &doc); err != nil {
+		return "", ""
+	}
+
+	var paragraphs []string
+	var walk func(group pptxGroup)
+	walk = func(group pptxGroup) {
+		for _, shape := range group.Shapes {
+			if shape.Text == nil || shape.Placeholder == nil || shape.Placeholder.Type != "body" {
+				continue
+			}
+			for _, p := range shape.Text.Paragraphs {
+				if text := strings.TrimSpace(paragraphText(p, nil, false)); text != "" {
+					paragraphs = append(paragraphs, text)
+				}
+			}
+		}
+		for _, g := range group.Groups {
+			walk(g)
+		}
+	}
+	walk(doc.Tree)
+	for i, p := range paragraphs {
+		if p == "Notes:" {
+			return strings.Join(paragraphs[:i], "\n"), strings.Join(paragraphs[i+1:], "\n")
+		}
+	}
+	return strings.Join(paragraphs, "\n"), ""
+}
+
+// extract copies a media part to the assets directory once and returns its
+// path relative to the script
+func (im *pptxImporter) extract(number int, part string) (string, error) {
+	if image, ok := im.extracted[part]; ok {
+		return image, nil
+	}
+
+	f, ok := im.files[part]
+	if !ok {
+		im.slideWarnf(number, "picture %s is missing from the deck", part)
+		return "", fmt.Errorf("part %s not found", part)
+	}
+	if !browserImageExtensions[strings.ToLower(path.Ext(part))] {
+		im.slideWarnf(number, "picture %s is in a format browsers cannot display; convert it to PNG or SVG", path.Base(part))
+	}
+
+	if err := os.MkdirAll(im.assetsDir, 0755); err != nil {
+		return "", err
+	}
+	target := filepath.Join(im.assetsDir, path.Base(part))
+	if err := extractFile(f, target); err != nil {
+		im.slideWarnf(number, "failed to extract picture %s: %v", path.Base(part), err)
+		return "", err
+	}
+
+	image, err := filepath.Rel(filepath.Dir(im.scriptPath), target)
+	if err != nil {
+		image = target
+	}
+	image = filepath.ToSlash(image)
+	im.extracted[part] = image
+	return image, nil
+}
+
+func extractFile(f *zip.File, target string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// markdown converts a text body to Markdown. Paragraphs of body placeholders
+// are bullets (unless bullets are switched off); other text boxes are plain
+// paragraphs unless they have explicit bullets.
+func (im *pptxImporter) markdown(body pptxTextBody, rels map[string]pptxRel, bullets bool) string {
+	var lines []string
+	for _, p := range body.Paragraphs {
+		text := strings.TrimSpace(paragraphText(p, rels, true))
+		if text == "" {
+			continue
+		}
+
+		props := p.Properties
+		indent := strings.Repeat("  ", props.Level)
+		switch {
+		case props.Numbered != nil:
+			lines = append(lines, indent+"1. "+text)
+		case props.Bullet != nil || (bullets && props.NoBullet == nil):
+			lines = append(lines, indent+"- "+text)
+		default:
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// table converts a table graphic frame to a Markdown table; the first row is the header
+func (im *pptxImporter) table(frame pptxFrame) string {
+	var rows []string
+	for i, row := range frame.Data.Table.Rows {
+		var cells []string
+		for _, cell := range row.Cells {
+			text := strings.ReplaceAll(plainText(cell.Text), "|", `\|`)
+			cells = append(cells, text)
+		}
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// plainText returns the text of a body with paragraphs joined by spaces
+func plainText(body pptxTextBody) string {
+	var parts []string
+	for _, p := range body.Paragraphs {
+		if text := strings.TrimSpace(paragraphText(p, nil, false)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// paragraphText returns the text of a paragraph, as Markdown with emphasis
+// and links when markdown is set
+func paragraphText(p pptxParagraph, rels map[string]pptxRel, markdown bool) string {
+	var b strings.Builder
+	for _, run := range p.Runs {
+		switch run.XMLName.Local {
+		case "br":
+			// Scripts render every newline of a paragraph as a line break
+			if markdown {
+				b.WriteString("\n")
+			} else {
+				b.WriteString(" ")
+			}
+			continue
+		case "r", "fld":
+		default:
+			continue
+		}
+
+		text := run.Text
+		if !markdown || strings.TrimSpace(text) == "" {
+			b.WriteString(text)
+			continue
+		}
+
+		// Keep surrounding spaces outside of emphasis markers
+		trimmed := strings.TrimSpace(text)
+		lead := text[:strings.Index(text, trimmed)]
+		trail := text[len(lead)+len(trimmed):]
+
+		text = escapeMarkdown(trimmed)
+		if link := run.Properties.Link; link != nil {
+			if rel, ok := rels[link.RID]; ok && rel.external {
+				text = "[" + text + "](" + rel.target + ")"
+			}
+		}
+		if isTrue(run.Properties.Bold) {
+			text = "**" + text + "**"
+		}
+		if isTrue(run.Properties.Italic) {
+			text = "*" + text + "*"
+		}
+		b.WriteString(lead + text + trail)
+	}
+	return b.String()
+}
+
+func isTrue(value string) bool {
+	return value == "1" || value == "true"
+}
+
+// markdownEscaper escapes the characters that would turn slide text into Markdown syntax
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, "#", `\#`,
+)
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
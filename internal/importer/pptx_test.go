@@ -0,0 +1,226 @@
+package importer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+const (
+	pptxNamespaces = `xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"`
+	pptxRelsNamespace = `xmlns="http://schemas.openxmlformats.org/package/2006/relationships"`
+	pptxRelType       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/"
+)
+
+// writePPTX writes a deck made of the given parts
+func writePPTX(t *testing.T, parts map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create deck: %v", err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range parts {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		entry.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to write deck: %v", err)
+	}
+	f.Close()
+	return path
+}
+
+func pptxRelsXML(rels ...string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><Relationships ` + pptxRelsNamespace + `>` + strings.Join(rels, "") + `</Relationships>`
+}
+
+func pptxRelXML(id, kind, target string) string {
+	mode := ""
+	if strings.HasPrefix(target, "http") {
+		mode = ` TargetMode="External"`
+	}
+	return `<Relationship Id="` + id + `" Type="` + pptxRelType + kind + `" Target="` + target + `"` + mode + `/>`
+}
+
+func pptxSlideXML(attrs, shapes string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><p:sld ` + pptxNamespaces + attrs + `><p:cSld><p:spTree>` + shapes + `</p:spTree></p:cSld></p:sld>`
+}
+
+func pptxShapeXML(placeholder, x, paragraphs string) string {
+	ph := ""
+	if placeholder != "" {
+		ph = `<p:ph ` + placeholder + `/>`
+	}
+	return `<p:sp><p:nvSpPr><p:cNvPr id="2" name="Shape"/><p:cNvSpPr/><p:nvPr>` + ph + `</p:nvPr></p:nvSpPr>` +
+		`<p:spPr><a:xfrm><a:off x="` + x + `" y="0"/></a:xfrm></p:spPr><p:txBody>` + paragraphs + `</p:txBody></p:sp>`
+}
+
+func pptxParagraphXML(props, runs string) string {
+	return `<a:p>` + props + runs + `</a:p>`
+}
+
+func pptxRunXML(props, text string) string {
+	return `<a:r><a:rPr lang="en-US" ` + props + `/><a:t>` + text + `</a:t></a:r>`
+}
+
+func TestImportPPTX(t *testing.T) {
+	deck := writePPTX(t, map[string]string{
+		"docProps/core.xml": `<?xml version="1.0"?><cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+			`xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Quarterly Review</dc:title><dc:creator>Jane Doe</dc:creator>` +
+			`<cp:keywords>finance; q3</cp:keywords></cp:coreProperties>`,
+		"ppt/presentation.xml": `<?xml version="1.0"?><p:presentation ` + pptxNamespaces + `><p:sldIdLst>` +
+			`<p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/><p:sldId id="258" r:id="rId4"/>` +
+			`</p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": pptxRelsXML(
+			pptxRelXML("rId2", "slide", "slides/slide2.xml"),
+			pptxRelXML("rId3", "slide", "slides/slide1.xml"),
+			pptxRelXML("rId4", "slide", "slides/slide3.xml"),
+		),
+		"ppt/slideLayouts/slideLayout1.xml": `<p:sldLayout ` + pptxNamespaces + ` type="title"/>`,
+		"ppt/slideLayouts/slideLayout2.xml": `<p:sldLayout ` + pptxNamespaces + ` type="obj"/>`,
+
+		// Title slide
+		"ppt/slides/slide1.xml": pptxSlideXML(``,
+			pptxShapeXML(`type="ctrTitle"`, "0", pptxParagraphXML(``, pptxRunXML(``, "Q3 Results")))+
+				pptxShapeXML(`type="subTitle" idx="1"`, "0", pptxParagraphXML(``, pptxRunXML(``, "Finance team")))+
+				pptxShapeXML(`type="sldNum" idx="12"`, "0", pptxParagraphXML(``, `<a:fld id="{1}" type="slidenum"><a:t>1</a:t></a:fld>`))),
+		"ppt/slides/_rels/slide1.xml.rels": pptxRelsXML(
+			pptxRelXML("rId1", "slideLayout", "../slideLayouts/slideLayout1.xml"),
+			pptxRelXML("rId2", "notesSlide", "../notesSlides/notesSlide1.xml"),
+		),
+		"ppt/notesSlides/notesSlide1.xml": pptxSlideXML(``,
+			pptxShapeXML(`type="sldImg"`, "0", ``)+
+				pptxShapeXML(`type="body" idx="1"`, "0",
+					pptxParagraphXML(``, pptxRunXML(``, "Welcome to the review."))+
+						pptxParagraphXML(``, pptxRunXML(``, "It was a good quarter.")))),
+
+		// Content slide with bullets, emphasis, a link and pictures
+		"ppt/slides/slide2.xml": pptxSlideXML(``,
+			pptxShapeXML(`type="title"`, "0", pptxParagraphXML(``, pptxRunXML(``, "Highlights")))+
+				pptxShapeXML(`idx="1"`, "5000",
+					pptxParagraphXML(``, pptxRunXML(`b="1"`, "Revenue")+pptxRunXML(``, " up 10%"))+
+						pptxParagraphXML(`<a:pPr lvl="1"/>`, pptxRunXML(``, "driven by *new* customers"))+
+						pptxParagraphXML(``, `<a:r><a:rPr><a:hlinkClick r:id="rId9"/></a:rPr><a:t>Details</a:t></a:r>`))+
+				`<p:pic><p:nvPicPr><p:cNvPr id="4" name="Chart" descr="Revenue chart"/><p:cNvPicPr/><p:nvPr/></p:nvPicPr>`+
+				`<p:blipFill><a:blip r:embed="rId3"/></p:blipFill><p:spPr><a:xfrm><a:off x="100" y="0"/></a:xfrm></p:spPr></p:pic>`+
+				`<p:pic><p:nvPicPr><p:cNvPr id="5" name="Logo" descr="Logo"/><p:cNvPicPr/><p:nvPr/></p:nvPicPr>`+
+				`<p:blipFill><a:blip r:embed="rId4"/></p:blipFill></p:pic>`+
+				`<p:graphicFrame><a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/chart"/></a:graphic></p:graphicFrame>`+
+				`<p:graphicFrame><a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/table"><a:tbl>`+
+				`<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Region</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>Growth</a:t></a:r></a:p></a:txBody></a:tc></a:tr>`+
+				`<a:tr><a:tc><a:txBody><a:p><a:r><a:t>EU</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>12%</a:t></a:r></a:p></a:txBody></a:tc></a:tr>`+
+				`</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`),
+		"ppt/slides/_rels/slide2.xml.rels": pptxRelsXML(
+			pptxRelXML("rId1", "slideLayout", "../slideLayouts/slideLayout2.xml"),
+			pptxRelXML("rId3", "image", "../media/image1.png"),
+			pptxRelXML("rId4", "image", "../media/image2.emf"),
+			pptxRelXML("rId9", "hyperlink", "https://example.com/q3"),
+		),
+		"ppt/media/image1.png": "png data",
+		"ppt/media/image2.emf": "emf data",
+
+		// Hidden slide
+		"ppt/slides/slide3.xml": pptxSlideXML(` show="0"`,
+			pptxShapeXML(`type="title"`, "0", pptxParagraphXML(``, pptxRunXML(``, "Backup")))),
+	})
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "review.md")
+	assetsDir := filepath.Join(dir, "review-assets")
+
+	s, err := ImportPPTX(deck, scriptPath, assetsDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.Title != "Quarterly Review" || s.Author != "Jane Doe" || strings.Join(s.Tags, ",") != "finance,q3" {
+		t.Errorf("Expected document properties as metadata, got title %q, author %q, tags %v", s.Title, s.Author, s.Tags)
+	}
+	if len(s.Slides) != 2 {
+		t.Fatalf("Expected 2 slides (hidden slide skipped), got %d", len(s.Slides))
+	}
+
+	title := s.Slides[0]
+	if title.Title != "Q3 Results" || title.Layout != script.LayoutTitle || title.Content != "Finance team" {
+		t.Errorf("Unexpected title slide: %+v", title)
+	}
+	if title.Transcription != "Welcome to the review.\nIt was a good quarter." {
+		t.Errorf("Expected the speaker notes as transcription, got %q", title.Transcription)
+	}
+
+	content := s.Slides[1]
+	expected := "- **Revenue** up 10%\n  - driven by \\*new\\* customers\n- [Details](https://example.com/q3)\n\n" +
+		"| Region | Growth |\n| --- | --- |\n| EU | 12% |\n\n![Logo](review-assets/image2.emf)"
+	if content.Content != expected {
+		t.Errorf("Expected content:\n%s\ngot:\n%s", expected, content.Content)
+	}
+	if content.Image != "review-assets/image1.png" || content.Layout != script.LayoutImageLeft {
+		t.Errorf("Expected the first picture as an image-left Image, got %q (%s)", content.Image, content.Layout)
+	}
+	if data, err := os.ReadFile(filepath.Join(assetsDir, "image1.png")); err != nil || string(data) != "png data" {
+		t.Errorf("Expected the picture to be extracted, got %q (%v)", data, err)
+	}
+
+	var messages []string
+	for _, d := range s.Diagnostics {
+		messages = append(messages, d.Message)
+	}
+	for _, want := range []string{
+		"slide 2: picture image2.emf is in a format browsers cannot display",
+		"slide 2: chart graphic is not supported",
+		"slide 3: hidden slide skipped",
+	} {
+		found := false
+		for _, message := range messages {
+			found = found || strings.HasPrefix(message, want)
+		}
+		if !found {
+			t.Errorf("Expected warning %q, got %q", want, messages)
+		}
+	}
+
+	// The result is a valid script once written next to the assets
+	data, err := s.Markdown()
+	if err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	if err := os.WriteFile(scriptPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	parsed, err := script.ParseScript(scriptPath)
+	if err != nil {
+		t.Fatalf("Imported script does not parse: %v", err)
+	}
+	if parsed.HasErrors() {
+		t.Errorf("Expected no errors, got %v", parsed.Diagnostics)
+	}
+	if parsed.Slides[1].Image != content.Image || parsed.Slides[0].Transcription != title.Transcription {
+		t.Errorf("Expected the script to round trip, got %+v", parsed.Slides)
+	}
+}
+
+func TestImportPPTXInvalidDeck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	if err := os.WriteFile(path, []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write deck: %v", err)
+	}
+	if _, err := ImportPPTX(path, "deck.md", t.TempDir()); err == nil {
+		t.Error("Expected error for a file that is not a deck")
+	}
+
+	notPowerPoint := writePPTX(t, map[string]string{"word/document.xml": "<document/>"})
+	if _, err := ImportPPTX(notPowerPoint, "deck.md", t.TempDir()); err == nil {
+		t.Error("Expected error for an archive without a presentation")
+	}
+}
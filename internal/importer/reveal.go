@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// revealSeparator matches the horizontal (---) and vertical (-- or ----) slide
+// separators of reveal.js and reveal-md. Vertical stacks are flattened.
+var revealSeparator = regexp.MustCompile(`^(---|--|----)$`)
+
+// revealNotes matches the line that starts the speaker notes of a slide
+var revealNotes = regexp.MustCompile(`(?i)^\s*notes?:\s?`)
+
+// revealAttribute matches one attribute of a .slide or .element comment
+var revealAttribute = regexp.MustCompile(`([\w-]+)(?:=(?:"([^"]*)"|'([^']*)'|(\S+)))?`)
+
+// imageExtensions are the file extensions recognized as background images
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true,
+}
+
+// importReveal converts a reveal.js Markdown deck: slides are split at
+// separator lines, everything after a `Note:` line is the transcription and
+// .slide/.element attribute comments are mapped where rhesis has an
+// equivalent. Other HTML comments are kept as private speaker notes.
+func (im *importer) importReveal(lines []line) {
+	for _, chunk := range splitSlides(lines, revealSeparator.MatchString) {
+		raw := rawSlide{}
+		if len(chunk) > 0 {
+			raw.line = chunk[0].num
+		}
+
+		content, notes := splitRevealNotes(chunk)
+		if len(notes) > 0 {
+			raw.notes = append(raw.notes, strings.Join(notes, "\n"))
+		}
+
+		content, comments := extractComments(content)
+		raw.content = content
+		for _, c := range comments {
+			switch {
+			case strings.HasPrefix(c.text, ".slide:"):
+				im.revealSlideAttributes(&raw, c)
+			case strings.HasPrefix(c.text, ".element:"):
+				im.revealElementAttributes(c)
+			default:
+				raw.private = append(raw.private, c.text)
+			}
+		}
+
+		if raw.isBlank() && len(raw.private) == 0 {
+			continue
+		}
+		im.script.Slides = append(im.script.Slides, im.buildSlide(raw))
+	}
+}
+
+// splitRevealNotes splits a slide at its notes line, outside fenced code blocks
+func splitRevealNotes(lines []line) ([]line, []string) {
+	inFence := false
+	for i, l := range lines {
+		if fencePattern.MatchString(l.text) {
+			inFence = !inFence
+		}
+		if inFence || !revealNotes.MatchString(l.text) {
+			continue
+		}
+
+		notes := []string{revealNotes.ReplaceAllString(l.text, "")}
+		for _, n := range lines[i+1:] {
+			notes = append(notes, n.text)
+		}
+		return lines[:i], notes
+	}
+	return lines, nil
+}
+
+// revealSlideAttributes maps the attributes of a <!-- .slide: --> comment. A
+// background image becomes the slide Image with the full-bleed layout.
+func (im *importer) revealSlideAttributes(raw *rawSlide, c comment) {
+	for _, attr := range parseRevealAttributes(strings.TrimPrefix(c.text, ".slide:")) {
+		name, value := attr[0], attr[1]
+		switch {
+		case (name == "data-background" || name == "data-background-image") &&
+			imageExtensions[strings.ToLower(filepath.Ext(value))]:
+			if raw.image != "" {
+				im.warnf(c.line, "only one background image per slide is supported; %q is dropped", value)
+				continue
+			}
+			raw.image = value
+			raw.layout = script.LayoutFullBleed
+		default:
+			im.warnf(c.line, "slide attribute %q is not supported", name)
+		}
+	}
+}
+
+// revealElementAttributes reports the attributes of a <!-- .element: -->
+// comment; none of them has an equivalent, but fragments can be rebuilt with cues
+func (im *importer) revealElementAttributes(c comment) {
+	for _, attr := range parseRevealAttributes(strings.TrimPrefix(c.text, ".element:")) {
+		name, value := attr[0], attr[1]
+		if name == "class" && strings.Contains(" "+value+" ", " fragment ") {
+			im.warnf(c.line, "fragment classes are not supported; add %s cue markers to the transcription to reveal list items one by one",
+				script.CueMarker)
+			continue
+		}
+		im.warnf(c.line, "element attribute %q is not supported", name)
+	}
+}
+
+// parseRevealAttributes returns the name and value of each attribute
+func parseRevealAttributes(text string) [][2]string {
+	var attrs [][2]string
+	for _, m := range revealAttribute.FindAllStringSubmatch(text, -1) {
+		attrs = append(attrs, [2]string{m[1], m[2] + m[3] + m[4]})
+	}
+	return attrs
+}
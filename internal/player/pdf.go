@@ -0,0 +1,286 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+	"github.com/playwright-community/playwright-go"
+)
+
+// Page sizes a presentation can be printed at
+const (
+	PageSizeSlide  = "slide"
+	PageSizeA4     = "a4"
+	PageSizeLetter = "letter"
+)
+
+// pageSizes are the landscape dimensions of every paper size in CSS pixels
+// (96 per inch). Handouts print them in portrait.
+var pageSizes = map[string]playwright.Size{
+	PageSizeA4:     {Width: 1123, Height: 794},
+	PageSizeLetter: {Width: 1056, Height: 816},
+}
+
+// slideLength is the long side of a slide page in CSS pixels, which makes a
+// 16:9 slide 13.33x7.5 inches like a widescreen PowerPoint slide
+const slideLength = 1280
+
+// slideSize returns the size of a slide with the aspect ratio of the viewport
+func slideSize(v script.Viewport) playwright.Size {
+	if v.Width >= v.Height {
+		return playwright.Size{Width: slideLength, Height: int(math.Round(float64(slideLength*v.Height) / float64(v.Width)))}
+	}
+	return playwright.Size{Width: int(math.Round(float64(slideLength*v.Width) / float64(v.Height))), Height: slideLength}
+}
+
+// defaultRenderTimeout bounds the wait for diagrams, images and fonts
+const defaultRenderTimeout = 30 * time.Second
+
+// PDFOptions control how a presentation is printed
+type PDFOptions struct {
+	// PageSize is one of PageSizes; empty prints at the size of a slide,
+	// which has the aspect ratio of the player's viewport
+	PageSize string
+	// Handout prints every slide above its narration and speaker notes
+	Handout bool
+	// Timeout bounds the wait for diagrams and images to render
+	Timeout time.Duration
+}
+
+// PageSizes returns the names of the page sizes ExportPDF accepts
+func PageSizes() []string {
+	names := []string{PageSizeSlide}
+	for name := range pageSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pageSize returns the dimensions of a page in CSS pixels for slides the
+// shape of the viewport
+func (o PDFOptions) pageSize(v script.Viewport) (playwright.Size, error) {
+	name := strings.ToLower(o.PageSize)
+	if name == "" || name == PageSizeSlide {
+		return slideSize(v), nil
+	}
+	size, ok := pageSizes[name]
+	if !ok {
+		return playwright.Size{}, fmt.Errorf("unknown page size %q (expected one of %s)", o.PageSize, strings.Join(PageSizes(), ", "))
+	}
+	if o.Handout {
+		size.Width, size.Height = size.Height, size.Width
+	}
+	return size, nil
+}
+
+// ExportPDF prints a generated presentation to pdfPath, one slide per page
+// with all of its fragments revealed. Printing waits until Mermaid diagrams,
+// images and fonts have finished rendering.
+func (p *PresentationPlayer) ExportPDF(htmlPath, pdfPath string, opts PDFOptions) error {
+	size, err := opts.pageSize(p.viewport)
+	if err != nil {
+		return err
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRenderTimeout
+	}
+
+	if err := p.initializeWithOptions(true); err != nil {
+		return fmt.Errorf("failed to initialize player: %w", err)
+	}
+	defer p.cleanup()
+
+	if err := p.page.SetViewportSize(size.Width, size.Height); err != nil {
+		return fmt.Errorf("failed to set page size: %w", err)
+	}
+
+	// Lay the slides out as pages before the load event so that Mermaid
+	// renders every diagram while it is visible and can be measured
+	layout := fmt.Sprintf("window.__rhesisPrint = %s;\n%s", printSettings(size, slideSize(p.viewport), opts.Handout), printLayoutScript)
+	if err := p.page.AddInitScript(playwright.Script{Content: playwright.String(layout)}); err != nil {
+		return fmt.Errorf("failed to prepare print layout: %w", err)
+	}
+
+	absolutePath, err := filepath.Abs(htmlPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if _, err := p.page.Goto(fmt.Sprintf("file://%s", absolutePath)); err != nil {
+		return fmt.Errorf("failed to load presentation: %w", err)
+	}
+
+	if _, err := p.page.WaitForSelector("[data-ready='true']"); err != nil {
+		return fmt.Errorf("failed to wait for presentation ready: %w", err)
+	}
+
+	if err := p.waitForRender(timeout); err != nil {
+		return err
+	}
+
+	if _, err := p.page.PDF(playwright.PagePdfOptions{
+		Path:            playwright.String(pdfPath),
+		Width:           playwright.String(fmt.Sprintf("%dpx", size.Width)),
+		Height:          playwright.String(fmt.Sprintf("%dpx", size.Height)),
+		PrintBackground: playwright.Bool(true),
+		Margin: &playwright.Margin{
+			Top:    playwright.String("0"),
+			Right:  playwright.String("0"),
+			Bottom: playwright.String("0"),
+			Left:   playwright.String("0"),
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to print presentation: %w", err)
+	}
+
+	return nil
+}
+
+// waitForRender waits until every Mermaid diagram has been drawn and every
+// image and font has loaded. D2 diagrams are images rendered ahead of time.
+func (p *PresentationPlayer) waitForRender(timeout time.Duration) error {
+	_, err := p.page.WaitForFunction(renderedScript, nil, playwright.PageWaitForFunctionOptions{
+		Polling: 100,
+		Timeout: playwright.Float(float64(timeout.Milliseconds())),
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, playwright.ErrTimeout) {
+		return fmt.Errorf("failed to wait for slides to render: %w", err)
+	}
+
+	// Say what is still missing
+	pending, evalErr := p.page.Evaluate(pendingScript)
+	if evalErr != nil {
+		return fmt.Errorf("slides did not finish rendering within %s", timeout)
+	}
+	return fmt.Errorf("slides did not finish rendering within %s: %v", timeout, pending)
+}
+
+// printSettings returns the print layout parameters as a JavaScript object
+func printSettings(page, slide playwright.Size, handout bool) string {
+	return fmt.Sprintf("{width: %d, height: %d, slideWidth: %d, slideHeight: %d, handout: %t}",
+		page.Width, page.Height, slide.Width, slide.Height, handout)
+}
+
+// printLayoutScript moves every slide onto a page of its own. In handouts
+// the slide is scaled down above its narration and speaker notes.
+const printLayoutScript = `document.addEventListener('DOMContentLoaded', () => {
+    const settings = window.__rhesisPrint;
+    const slideWidth = settings.slideWidth, slideHeight = settings.slideHeight, padding = 36;
+    const zoom = Math.min((settings.width - 2 * padding) / slideWidth, (settings.height * 0.55) / slideHeight);
+    const background = getComputedStyle(document.body).background;
+    const notes = typeof presenterSlides !== 'undefined' ? presenterSlides : [];
+
+    const style = document.createElement('style');
+    style.textContent = ` + "`" + `
+        @page { size: ${settings.width}px ${settings.height}px; margin: 0; }
+        html, body { width: auto; height: auto; overflow: visible !important; }
+        body > :not(.print-pages) { display: none !important; }
+        .print-page {
+            position: relative;
+            width: ${settings.width}px;
+            height: ${settings.height}px;
+            overflow: hidden;
+            box-sizing: border-box;
+            break-after: page;
+        }
+        .print-page:last-child { break-after: auto; }
+        .print-slide.slide-area {
+            display: flex;
+            position: relative;
+            width: ${settings.handout ? slideWidth : settings.width}px;
+            height: ${settings.handout ? slideHeight : settings.height}px;
+            box-sizing: border-box;
+            overflow: hidden;
+        }
+        .print-page .slide { display: block !important; }
+        .print-page *, .print-page *::before, .print-page *::after {
+            animation: none !important;
+            transition: none !important;
+        }
+        .print-page .fragment { opacity: 1 !important; }
+        .handout .print-page {
+            padding: ${padding}px;
+            background: #fff;
+            color: #222;
+            font-family: Georgia, 'Times New Roman', serif;
+        }
+        .handout .print-slide.slide-area {
+            zoom: ${zoom};
+            border: 1px solid #ccc;
+        }
+        .handout-text { margin-top: 24px; font-size: 14px; line-height: 1.5; }
+        .handout-text h2 { font-size: 18px; margin: 0 0 8px; }
+        .handout-notes { margin-top: 12px; padding-top: 8px; border-top: 1px solid #ddd; color: #555; }
+    ` + "`" + `;
+    document.head.appendChild(style);
+
+    const pages = document.createElement('div');
+    pages.className = 'print-pages' + (settings.handout ? ' handout' : '');
+    document.querySelectorAll('.slide-area > .slide').forEach((slide, index) => {
+        const page = document.createElement('div');
+        page.className = 'print-page';
+        const area = document.createElement('div');
+        area.className = 'slide-area print-slide';
+        if (settings.handout) {
+            area.style.background = background;
+        }
+        slide.classList.add('active');
+        slide.querySelectorAll('.fragment').forEach(fragment => fragment.classList.add('visible'));
+        area.appendChild(slide);
+        page.appendChild(area);
+
+        if (settings.handout) {
+            const data = notes[index] || {};
+            const text = document.createElement('div');
+            text.className = 'handout-text';
+            const heading = document.createElement('h2');
+            heading.textContent = (index + 1) + '. ' + (data.title || '');
+            text.appendChild(heading);
+            const narration = document.createElement('div');
+            narration.className = 'handout-narration';
+            narration.innerHTML = data.narration || '';
+            text.appendChild(narration);
+            if (data.notes) {
+                const speakerNotes = document.createElement('div');
+                speakerNotes.className = 'handout-notes';
+                speakerNotes.innerHTML = data.notes;
+                text.appendChild(speakerNotes);
+            }
+            page.appendChild(text);
+        }
+        pages.appendChild(page);
+    });
+    document.body.appendChild(pages);
+});`
+
+// renderedScript reports whether the slides are ready to print
+const renderedScript = `() => {
+    const diagrams = [...document.querySelectorAll('.mermaid')];
+    if (diagrams.some(d => !d.querySelector('svg'))) return false;
+    if ([...document.images].some(img => !img.complete)) return false;
+    return document.fonts.status === 'loaded';
+}`
+
+// pendingScript describes what kept the slides from rendering
+const pendingScript = `() => {
+    const pending = [];
+    const diagrams = [...document.querySelectorAll('.mermaid')].filter(d => !d.querySelector('svg')).length;
+    if (diagrams > 0) {
+        pending.push(diagrams + ' Mermaid diagram(s) not drawn' + (typeof mermaid === 'undefined' ? ' (the Mermaid script could not be loaded)' : ''));
+    }
+    const images = [...document.images].filter(img => !img.complete).length;
+    if (images > 0) pending.push(images + ' image(s) still loading');
+    if (document.fonts.status !== 'loaded') pending.push('fonts still loading');
+    return pending.join(', ');
+}`
@@ -0,0 +1,104 @@
+package player
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/generator"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+// pageObject matches the page objects of a PDF but not its page tree
+var pageObject = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+func TestPDFPageSize(t *testing.T) {
+	portrait := script.Viewport{Width: 1080, Height: 1920, DeviceScale: 1}
+	lms := script.Viewport{Width: 1440, Height: 1080, DeviceScale: 1}
+	tests := []struct {
+		opts          PDFOptions
+		viewport      script.Viewport
+		width, height int
+		wantErr       bool
+	}{
+		{PDFOptions{}, script.DefaultViewport, 1280, 720, false},
+		{PDFOptions{}, portrait, 720, 1280, false},
+		{PDFOptions{PageSize: PageSizeSlide}, lms, 1280, 960, false},
+		{PDFOptions{PageSize: "A4"}, script.DefaultViewport, 1123, 794, false},
+		{PDFOptions{PageSize: PageSizeA4, Handout: true}, script.DefaultViewport, 794, 1123, false},
+		{PDFOptions{PageSize: PageSizeLetter, Handout: true}, portrait, 816, 1056, false},
+		{PDFOptions{PageSize: PageSizeSlide, Handout: true}, script.DefaultViewport, 1280, 720, false},
+		{PDFOptions{PageSize: "tabloid"}, script.DefaultViewport, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		size, err := tt.opts.pageSize(tt.viewport)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%+v: expected an error", tt.opts)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: unexpected error: %v", tt.opts, err)
+			continue
+		}
+		if size.Width != tt.width || size.Height != tt.height {
+			t.Errorf("%+v: expected %dx%d, got %dx%d", tt.opts, tt.width, tt.height, size.Width, size.Height)
+		}
+	}
+}
+
+func TestExportPDFIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping PDF integration test in short mode")
+	}
+
+	testScript := &script.Script{
+		Title: "Handout Test",
+		Slides: []script.Slide{
+			{
+				Title:         "First Slide",
+				Content:       "- One\n- Two",
+				Transcription: "The first point.",
+				Notes:         "Pause here.",
+				Duration:      2 * time.Second,
+			},
+			{
+				Title:         "Second Slide",
+				Content:       "The end",
+				Transcription: "Thanks for listening.",
+				Duration:      2 * time.Second,
+			},
+		},
+	}
+
+	tmpDir := t.TempDir()
+	htmlFile := filepath.Join(tmpDir, "presentation.html")
+	gen := generator.NewHTMLGenerator()
+	gen.SetSpeakerNotes(true)
+	if err := gen.GeneratePresentation(testScript, htmlFile, "modern", false); err != nil {
+		t.Fatalf("Failed to generate presentation: %v", err)
+	}
+
+	for _, opts := range []PDFOptions{{}, {PageSize: PageSizeA4, Handout: true}} {
+		pdfFile := filepath.Join(tmpDir, "slides.pdf")
+		if err := NewPresentationPlayer().ExportPDF(htmlFile, pdfFile, opts); err != nil {
+			t.Fatalf("%+v: failed to export PDF: %v", opts, err)
+		}
+
+		data, err := os.ReadFile(pdfFile)
+		if err != nil {
+			t.Fatalf("Failed to read PDF: %v", err)
+		}
+		if !bytes.HasPrefix(data, []byte("%PDF")) {
+			t.Errorf("%+v: expected a PDF file", opts)
+		}
+		if pages := len(pageObject.FindAll(data, -1)); pages != len(testScript.Slides) {
+			t.Errorf("%+v: expected %d pages, got %d", opts, len(testScript.Slides), pages)
+		}
+	}
+}
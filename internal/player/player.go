@@ -2,10 +2,12 @@ package player
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/jmcarbo/rhesis/internal/script"
 	"github.com/playwright-community/playwright-go"
 )
 
@@ -15,10 +17,28 @@ type PresentationPlayer struct {
 	context    playwright.BrowserContext
 	page       playwright.Page
 	recordPath string
+	viewport   script.Viewport
 }
 
 func NewPresentationPlayer() *PresentationPlayer {
-	return &PresentationPlayer{}
+	return &PresentationPlayer{viewport: script.DefaultViewport}
+}
+
+// SetViewport sets the size presentations are played, recorded and captured at
+func (p *PresentationPlayer) SetViewport(v script.Viewport) {
+	if v.DeviceScale <= 0 {
+		v.DeviceScale = 1
+	}
+	p.viewport = v
+}
+
+// videoSize returns the size of recorded frames in device pixels, rounded to
+// even numbers as video encoders require
+func videoSize(v script.Viewport) *playwright.Size {
+	even := func(n int) int {
+		return int(math.Round(float64(n)*v.DeviceScale/2)) * 2
+	}
+	return &playwright.Size{Width: even(v.Width), Height: even(v.Height)}
 }
 
 func (p *PresentationPlayer) PlayPresentation(htmlPath, recordPath string) error {
@@ -79,25 +99,36 @@ func (p *PresentationPlayer) initializeWithOptions(headless bool) error {
 	}
 	p.browser = browser
 
+	// Enable video recording if record path is specified
+	recordDir := ""
+	if p.recordPath != "" {
+		recordDir = filepath.Dir(p.recordPath)
+	}
+	return p.newPage(recordDir)
+}
+
+// newPage opens a browser context at the player's viewport and a page in it.
+// With a recordDir, the page is recorded to a video in that directory.
+func (p *PresentationPlayer) newPage(recordDir string) error {
+	if p.viewport.Width == 0 {
+		p.viewport = script.DefaultViewport
+	}
 	contextOptions := playwright.BrowserNewContextOptions{
 		Viewport: &playwright.Size{
-			Width:  1920,
-			Height: 1080,
+			Width:  p.viewport.Width,
+			Height: p.viewport.Height,
 		},
+		DeviceScaleFactor: playwright.Float(p.viewport.DeviceScale),
 	}
 
-	// Enable video recording if record path is specified
-	if p.recordPath != "" {
+	if recordDir != "" {
 		contextOptions.RecordVideo = &playwright.RecordVideo{
-			Dir: filepath.Dir(p.recordPath),
-			Size: &playwright.Size{
-				Width:  1920,
-				Height: 1080,
-			},
+			Dir:  recordDir,
+			Size: videoSize(p.viewport),
 		}
 	}
 
-	context, err := browser.NewContext(contextOptions)
+	context, err := p.browser.NewContext(contextOptions)
 	if err != nil {
 		return err
 	}
@@ -195,7 +226,7 @@ func (p *PresentationPlayer) playPresentation() error {
 	totalDuration, err := p.page.Evaluate(`() => {
 		let total = 0;
 		document.querySelectorAll('.slide').forEach(slide => {
-			total += parseInt(slide.dataset.duration) * 1000;
+			total += parseFloat(slide.dataset.duration) * 1000;
 		});
 		return total;
 	}`)
@@ -264,7 +295,8 @@ func (p *PresentationPlayer) playPresentation() error {
 		fmt.Printf("\nWARNING: Presentation is longer than 3 minutes (%.1fs).\n", totalDurationMs/1000)
 		fmt.Printf("Some browsers/codecs may truncate long video recordings.\n")
 		fmt.Printf("If the video is shorter than expected, consider:\n")
-		fmt.Printf("- Breaking the presentation into smaller segments\n")
+		fmt.Printf("- Rendering frame by frame with -render, which has no length limit\n")
+		fmt.Printf("- Recording in segments with -segment, one clip per N slides\n")
 		fmt.Printf("- Using a different output format (WebM vs MP4)\n")
 		fmt.Printf("- Recording without video and adding audio separately\n")
 	}
@@ -549,26 +549,26 @@ func TestPlaybackWithGeneratedHTML(t *testing.T) {
 	// Import required packages for this test
 	scriptContent := &script.Script{
 		Title:       "Test Presentation",
-		Duration:    10,
-		DefaultTime: 2,
+		Duration:    10 * time.Second,
+		DefaultTime: 2 * time.Second,
 		Slides: []script.Slide{
 			{
 				Title:         "First Slide",
 				Content:       "This is the first slide",
 				Transcription: "Welcome to the first slide",
-				Duration:      2,
+				Duration:      2 * time.Second,
 			},
 			{
 				Title:         "Second Slide",
 				Content:       "This is the second slide",
 				Transcription: "Now on the second slide",
-				Duration:      2,
+				Duration:      2 * time.Second,
 			},
 			{
 				Title:         "Third Slide",
 				Content:       "This is the third slide",
 				Transcription: "Finally, the third slide",
-				Duration:      2,
+				Duration:      2 * time.Second,
 			},
 		},
 	}
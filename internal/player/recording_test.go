@@ -18,19 +18,19 @@ func TestRecordingIntegration(t *testing.T) {
 	// Create a test script
 	testScript := &script.Script{
 		Title:       "Recording Test",
-		DefaultTime: 2,
+		DefaultTime: 2 * time.Second,
 		Slides: []script.Slide{
 			{
 				Title:         "First Slide",
 				Content:       "This is the first slide",
 				Transcription: "Testing recording functionality",
-				Duration:      2,
+				Duration:      2 * time.Second,
 			},
 			{
 				Title:         "Second Slide",
 				Content:       "This is the second slide",
 				Transcription: "Verifying video output",
-				Duration:      2,
+				Duration:      2 * time.Second,
 			},
 		},
 	}
@@ -0,0 +1,232 @@
+package player
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultFPS is the frame rate of rendered videos
+const DefaultFPS = 30
+
+// RenderOptions control how a presentation is rendered to video
+type RenderOptions struct {
+	// FPS is the frame rate of the video; zero renders at DefaultFPS
+	FPS int
+	// Timeout bounds the wait for diagrams and images to render before the first frame
+	Timeout time.Duration
+}
+
+// renderEpoch is the time the page clock starts at, so renders of the same
+// presentation show the same dates
+var renderEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// renderInitScript silences narration, which is merged into the video
+// afterwards, and steps CSS animations and transitions with the page clock
+// instead of the wall clock
+const renderInitScript = `HTMLMediaElement.prototype.play = function () { return Promise.resolve(); };
+window.__rhesisStep = (now) => {
+    for (const animation of document.getAnimations()) {
+        if (animation.__rhesisStart === undefined) {
+            animation.__rhesisStart = now;
+            animation.pause();
+        }
+        animation.currentTime = now - animation.__rhesisStart;
+    }
+};`
+
+// RenderPresentation plays a generated presentation on a paused page clock and
+// captures it frame by frame into videoPath with ffmpeg. Unlike real-time
+// recording, no frames are dropped on a busy machine and the video lasts the
+// sum of the slide durations, to the nearest frame.
+func (p *PresentationPlayer) RenderPresentation(htmlPath, videoPath string, opts RenderOptions) error {
+	fps := opts.FPS
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRenderTimeout
+	}
+
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("ffmpeg not found in PATH. Please install ffmpeg to render videos")
+	}
+
+	if err := p.initializeWithOptions(true); err != nil {
+		return fmt.Errorf("failed to initialize player: %w", err)
+	}
+	defer p.cleanup()
+
+	clock := p.page.Clock()
+	if err := clock.Install(playwright.ClockInstallOptions{Time: renderEpoch}); err != nil {
+		return fmt.Errorf("failed to install page clock: %w", err)
+	}
+	if err := p.page.AddInitScript(playwright.Script{Content: playwright.String(renderInitScript)}); err != nil {
+		return fmt.Errorf("failed to prepare rendering: %w", err)
+	}
+
+	absolutePath, err := filepath.Abs(htmlPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if _, err := p.page.Goto(fmt.Sprintf("file://%s", absolutePath)); err != nil {
+		return fmt.Errorf("failed to load presentation: %w", err)
+	}
+
+	if _, err := p.page.WaitForSelector("[data-ready='true']"); err != nil {
+		return fmt.Errorf("failed to wait for presentation ready: %w", err)
+	}
+
+	if err := p.waitForRender(timeout); err != nil {
+		return err
+	}
+
+	// Stop the clock; from here on time only moves between frames
+	now, err := p.page.Evaluate(`() => Date.now()`)
+	if err != nil {
+		return fmt.Errorf("failed to read page clock: %w", err)
+	}
+	start, err := milliseconds(now)
+	if err != nil {
+		return err
+	}
+	start += 100
+	if err := clock.PauseAt(start); err != nil {
+		return fmt.Errorf("failed to pause page clock: %w", err)
+	}
+
+	total, err := p.page.Evaluate(`() => totalDuration`)
+	if err != nil {
+		return fmt.Errorf("failed to get presentation duration: %w", err)
+	}
+	totalMs, err := milliseconds(total)
+	if err != nil {
+		return err
+	}
+	frames := frameTimes(totalMs, fps)
+	fmt.Printf("Rendering %d frames at %d fps (%.1f seconds)\n", len(frames), fps, float64(totalMs)/1000)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(ffmpegPath, encoderArgs(videoPath, fps)...)
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open ffmpeg input: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	if _, err := p.page.Evaluate(`() => startPresentation()`); err != nil {
+		stdin.Close()
+		cmd.Wait()
+		return fmt.Errorf("failed to start presentation: %w", err)
+	}
+
+	if err := p.captureFrames(stdin, frames); err != nil {
+		stdin.Close()
+		cmd.Wait()
+		return err
+	}
+	fmt.Printf("\nRendered %d frames\n", len(frames))
+
+	if err := stdin.Close(); err != nil {
+		return fmt.Errorf("failed to close ffmpeg input: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w\n%s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// captureFrames advances the page clock to every frame time and writes a
+// screenshot of each frame to w
+func (p *PresentationPlayer) captureFrames(w io.Writer, frames []int64) error {
+	clock := p.page.Clock()
+	var elapsed int64
+	for i, at := range frames {
+		if step := at - elapsed; step > 0 {
+			if err := clock.RunFor(step); err != nil {
+				return fmt.Errorf("failed to advance page clock: %w", err)
+			}
+			elapsed = at
+		}
+		if _, err := p.page.Evaluate(`now => window.__rhesisStep(now)`, at); err != nil {
+			return fmt.Errorf("failed to step animations: %w", err)
+		}
+
+		frame, err := p.page.Screenshot(playwright.PageScreenshotOptions{Type: playwright.ScreenshotTypePng})
+		if err != nil {
+			return fmt.Errorf("failed to capture frame %d: %w", i+1, err)
+		}
+		if _, err := w.Write(frame); err != nil {
+			return fmt.Errorf("failed to write frame %d to ffmpeg: %w", i+1, err)
+		}
+
+		if i%10 == 0 || i == len(frames)-1 {
+			fmt.Printf("\rRendering frame %d of %d", i+1, len(frames))
+		}
+	}
+	return nil
+}
+
+// frameTimes returns the time of every frame, in milliseconds from the start
+// of the presentation, for a presentation lasting totalMs
+func frameTimes(totalMs int64, fps int) []int64 {
+	count := int(math.Round(float64(totalMs) * float64(fps) / 1000))
+	times := make([]int64, count)
+	for i := range times {
+		times[i] = int64(math.Round(float64(i) * 1000 / float64(fps)))
+	}
+	return times
+}
+
+// encoderArgs returns the ffmpeg arguments that encode PNG frames read from
+// stdin into videoPath, choosing the codec by extension
+func encoderArgs(videoPath string, fps int) []string {
+	rate := strconv.Itoa(fps)
+	args := []string{"-y", "-f", "image2pipe", "-framerate", rate, "-c:v", "png", "-i", "-"}
+	args = append(args, videoCodecArgs(videoPath)...)
+	return append(args, "-vf", evenScale, "-pix_fmt", "yuv420p", "-r", rate, videoPath)
+}
+
+// evenScale is the ffmpeg filter that rounds the frame size down to even
+// numbers, which yuv420p needs
+const evenScale = "scale=trunc(iw/2)*2:trunc(ih/2)*2"
+
+// videoCodecArgs returns the ffmpeg video codec arguments for a file,
+// choosing the codec by extension
+func videoCodecArgs(videoPath string) []string {
+	switch strings.ToLower(filepath.Ext(videoPath)) {
+	case ".webm":
+		return []string{"-c:v", "libvpx-vp9", "-crf", "32", "-b:v", "0", "-row-mt", "1"}
+	default:
+		return []string{"-c:v", "libx264", "-preset", "medium", "-crf", "20"}
+	}
+}
+
+// milliseconds converts a number returned by the page to an integer
+func milliseconds(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		return int64(math.Round(v)), nil
+	default:
+		return 0, fmt.Errorf("unexpected time type: %T", value)
+	}
+}
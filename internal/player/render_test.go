@@ -0,0 +1,85 @@
+package player
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/audio"
+	"github.com/jmcarbo/rhesis/internal/generator"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+func TestFrameTimes(t *testing.T) {
+	times := frameTimes(2500, 30)
+	if len(times) != 75 {
+		t.Fatalf("Expected 75 frames for 2.5s at 30fps, got %d", len(times))
+	}
+	for i, want := range map[int]int64{0: 0, 1: 33, 2: 67, 3: 100, 74: 2467} {
+		if times[i] != want {
+			t.Errorf("Expected frame %d at %dms, got %dms", i, want, times[i])
+		}
+	}
+
+	if got := len(frameTimes(1000, 24)); got != 24 {
+		t.Errorf("Expected 24 frames for 1s at 24fps, got %d", got)
+	}
+}
+
+func TestEncoderArgs(t *testing.T) {
+	tests := []struct {
+		path  string
+		codec string
+	}{
+		{"out.mp4", "libx264"},
+		{"out.WEBM", "libvpx-vp9"},
+	}
+
+	for _, tt := range tests {
+		args := strings.Join(encoderArgs(tt.path, 25), " ")
+		if !strings.HasPrefix(args, "-y -f image2pipe -framerate 25 -c:v png -i -") {
+			t.Errorf("%s: expected PNG frames read from stdin, got %s", tt.path, args)
+		}
+		if !strings.Contains(args, "-c:v "+tt.codec) || !strings.HasSuffix(args, "-r 25 "+tt.path) {
+			t.Errorf("%s: expected %s at 25fps, got %s", tt.path, tt.codec, args)
+		}
+	}
+}
+
+func TestRenderPresentationIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping render integration test in short mode")
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not available")
+	}
+
+	testScript := &script.Script{
+		Title: "Render Test",
+		Slides: []script.Slide{
+			{Title: "First Slide", Content: "One", Duration: 1500 * time.Millisecond},
+			{Title: "Second Slide", Content: "Two", Duration: 1 * time.Second},
+		},
+	}
+
+	tmpDir := t.TempDir()
+	htmlFile := filepath.Join(tmpDir, "presentation.html")
+	if err := generator.NewHTMLGenerator().GeneratePresentation(testScript, htmlFile, "modern", false); err != nil {
+		t.Fatalf("Failed to generate presentation: %v", err)
+	}
+
+	videoFile := filepath.Join(tmpDir, "render.mp4")
+	if err := NewPresentationPlayer().RenderPresentation(htmlFile, videoFile, RenderOptions{FPS: 10}); err != nil {
+		t.Fatalf("Failed to render presentation: %v", err)
+	}
+
+	duration, err := audio.GetVideoDuration(videoFile)
+	if err != nil {
+		t.Fatalf("Failed to get video duration: %v", err)
+	}
+	if diff := duration - testScript.GetTotalDuration(); diff < -100*time.Millisecond || diff > 100*time.Millisecond {
+		t.Errorf("Expected the video to last %v, got %v", testScript.GetTotalDuration(), duration)
+	}
+}
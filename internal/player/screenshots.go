@@ -0,0 +1,263 @@
+package player
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/script"
+	"github.com/playwright-community/playwright-go"
+	"golang.org/x/image/draw"
+)
+
+// Image formats slides can be saved in
+const (
+	ImageFormatPNG  = "png"
+	ImageFormatWebP = "webp"
+)
+
+// Defaults for ScreenshotOptions
+const (
+	defaultWebPQuality    = 90
+	defaultSheetColumns   = 4
+	defaultThumbnailWidth = 480
+	sheetSpacing          = 16
+)
+
+// ScreenshotOptions control how slides are captured
+type ScreenshotOptions struct {
+	// Width and Height are the size of every image in CSS pixels; zero captures
+	// at the player's viewport. Images are DeviceScale times larger.
+	Width, Height int
+	// Format is ImageFormatPNG (the default) or ImageFormatWebP
+	Format string
+	// Quality is the WebP quality from 1 to 100
+	Quality int
+	// ContactSheet is the path of an image with all slides in a grid; empty skips it.
+	// Its format follows the extension (.png or .webp).
+	ContactSheet string
+	// Columns is the number of thumbnails in every row of the contact sheet
+	Columns int
+	// ThumbnailWidth is the width of every thumbnail on the contact sheet
+	ThumbnailWidth int
+	// Timeout bounds the wait for diagrams and images to render on each slide
+	Timeout time.Duration
+}
+
+// withDefaults fills in the options left unset
+func (o ScreenshotOptions) withDefaults(viewport script.Viewport) (ScreenshotOptions, error) {
+	if o.Width <= 0 || o.Height <= 0 {
+		o.Width, o.Height = viewport.Width, viewport.Height
+	}
+	o.Format = strings.ToLower(o.Format)
+	switch o.Format {
+	case "":
+		o.Format = ImageFormatPNG
+	case ImageFormatPNG, ImageFormatWebP:
+	default:
+		return o, fmt.Errorf("unknown image format %q (expected %s or %s)", o.Format, ImageFormatPNG, ImageFormatWebP)
+	}
+	if o.ContactSheet != "" {
+		if _, err := imageFormat(o.ContactSheet); err != nil {
+			return o, err
+		}
+	}
+	if o.Quality <= 0 || o.Quality > 100 {
+		o.Quality = defaultWebPQuality
+	}
+	if o.Columns <= 0 {
+		o.Columns = defaultSheetColumns
+	}
+	if o.ThumbnailWidth <= 0 {
+		o.ThumbnailWidth = defaultThumbnailWidth
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultRenderTimeout
+	}
+	return o, nil
+}
+
+// imageFormat returns the format of an image from its file extension
+func imageFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".png":
+		return ImageFormatPNG, nil
+	case ".webp":
+		return ImageFormatWebP, nil
+	default:
+		return "", fmt.Errorf("unsupported image extension %q for %s (expected .png or .webp)", ext, path)
+	}
+}
+
+// screenshotStyle hides the playback controls and the slide transitions
+const screenshotStyle = `
+.controls, .slide-counter, .progress-bar { display: none !important; }
+.slide, .slide * { animation: none !important; transition: none !important; }
+`
+
+// ExportScreenshots shows every slide of a generated presentation with all of
+// its fragments revealed and saves it to outputDir as slide_01.png, ...
+// It returns the paths written, contact sheet excluded.
+func (p *PresentationPlayer) ExportScreenshots(htmlPath, outputDir string, opts ScreenshotOptions) ([]string, error) {
+	opts, err := opts.withDefaults(p.viewport)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := p.initializeWithOptions(true); err != nil {
+		return nil, fmt.Errorf("failed to initialize player: %w", err)
+	}
+	defer p.cleanup()
+
+	if err := p.page.SetViewportSize(opts.Width, opts.Height); err != nil {
+		return nil, fmt.Errorf("failed to set resolution: %w", err)
+	}
+
+	absolutePath, err := filepath.Abs(htmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if _, err := p.page.Goto(fmt.Sprintf("file://%s", absolutePath)); err != nil {
+		return nil, fmt.Errorf("failed to load presentation: %w", err)
+	}
+
+	if _, err := p.page.WaitForSelector("[data-ready='true']"); err != nil {
+		return nil, fmt.Errorf("failed to wait for presentation ready: %w", err)
+	}
+
+	if _, err := p.page.AddStyleTag(playwright.PageAddStyleTagOptions{Content: playwright.String(screenshotStyle)}); err != nil {
+		return nil, fmt.Errorf("failed to hide controls: %w", err)
+	}
+
+	count, err := p.page.Evaluate(`() => document.querySelectorAll('.slide').length`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count slides: %w", err)
+	}
+	var total int
+	switch v := count.(type) {
+	case int:
+		total = v
+	case float64:
+		total = int(v)
+	default:
+		return nil, fmt.Errorf("unexpected slide count type: %T", count)
+	}
+
+	var paths []string
+	var shots [][]byte
+	for i := 0; i < total; i++ {
+		if _, err := p.page.Evaluate(`index => showSlide(index, true)`, i); err != nil {
+			return paths, fmt.Errorf("failed to show slide %d: %w", i+1, err)
+		}
+		if err := p.waitForRender(opts.Timeout); err != nil {
+			return paths, fmt.Errorf("slide %d: %w", i+1, err)
+		}
+
+		shot, err := p.page.Screenshot(playwright.PageScreenshotOptions{Type: playwright.ScreenshotTypePng})
+		if err != nil {
+			return paths, fmt.Errorf("failed to capture slide %d: %w", i+1, err)
+		}
+		shots = append(shots, shot)
+
+		path := filepath.Join(outputDir, fmt.Sprintf("slide_%02d.%s", i+1, opts.Format))
+		if err := p.writeImage(path, shot, opts.Format, opts.Quality); err != nil {
+			return paths, fmt.Errorf("failed to save slide %d: %w", i+1, err)
+		}
+		paths = append(paths, path)
+	}
+
+	if opts.ContactSheet != "" {
+		sheet, err := contactSheet(shots, opts.Columns, opts.ThumbnailWidth)
+		if err != nil {
+			return paths, fmt.Errorf("failed to assemble contact sheet: %w", err)
+		}
+		format, _ := imageFormat(opts.ContactSheet)
+		if err := p.writeImage(opts.ContactSheet, sheet, format, opts.Quality); err != nil {
+			return paths, fmt.Errorf("failed to save contact sheet: %w", err)
+		}
+	}
+
+	return paths, nil
+}
+
+// writeImage saves a PNG image in the given format. Chromium encodes WebP,
+// which the standard library cannot.
+func (p *PresentationPlayer) writeImage(path string, data []byte, format string, quality int) error {
+	if format == ImageFormatWebP {
+		encoded, err := p.page.Evaluate(`async ([data, quality]) => {
+			const image = new Image();
+			image.src = 'data:image/png;base64,' + data;
+			await image.decode();
+			const canvas = document.createElement('canvas');
+			canvas.width = image.naturalWidth;
+			canvas.height = image.naturalHeight;
+			canvas.getContext('2d').drawImage(image, 0, 0);
+			const url = canvas.toDataURL('image/webp', quality / 100);
+			return url.startsWith('data:image/webp') ? url.slice(url.indexOf(',') + 1) : '';
+		}`, []interface{}{base64.StdEncoding.EncodeToString(data), quality})
+		if err != nil {
+			return fmt.Errorf("failed to encode WebP: %w", err)
+		}
+		webp, _ := encoded.(string)
+		if webp == "" {
+			return fmt.Errorf("the browser cannot encode WebP")
+		}
+		data, err = base64.StdEncoding.DecodeString(webp)
+		if err != nil {
+			return fmt.Errorf("failed to decode WebP: %w", err)
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// contactSheet assembles PNG screenshots as thumbnails in a grid and returns
+// the sheet as a PNG image
+func contactSheet(shots [][]byte, columns, thumbnailWidth int) ([]byte, error) {
+	if len(shots) == 0 {
+		return nil, fmt.Errorf("no slides")
+	}
+	if columns > len(shots) {
+		columns = len(shots)
+	}
+
+	thumbnails := make([]image.Image, len(shots))
+	for i, shot := range shots {
+		img, err := png.Decode(bytes.NewReader(shot))
+		if err != nil {
+			return nil, fmt.Errorf("slide %d: %w", i+1, err)
+		}
+		thumbnails[i] = img
+	}
+
+	// Every cell takes the aspect ratio of the first slide
+	first := thumbnails[0].Bounds()
+	thumbnailHeight := thumbnailWidth * first.Dy() / first.Dx()
+	rows := (len(thumbnails) + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0,
+		columns*(thumbnailWidth+sheetSpacing)+sheetSpacing,
+		rows*(thumbnailHeight+sheetSpacing)+sheetSpacing))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.RGBA{0x20, 0x20, 0x20, 0xff}), image.Point{}, draw.Src)
+
+	for i, img := range thumbnails {
+		x := sheetSpacing + (i%columns)*(thumbnailWidth+sheetSpacing)
+		y := sheetSpacing + (i/columns)*(thumbnailHeight+sheetSpacing)
+		draw.CatmullRom.Scale(sheet, image.Rect(x, y, x+thumbnailWidth, y+thumbnailHeight), img, img.Bounds(), draw.Src, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sheet); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package player
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/generator"
+	"github.com/jmcarbo/rhesis/internal/script"
+	_ "golang.org/x/image/webp"
+)
+
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestContactSheet(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	shots := [][]byte{
+		encodePNG(t, 320, 180, red),
+		encodePNG(t, 320, 180, blue),
+		encodePNG(t, 320, 180, red),
+	}
+
+	data, err := contactSheet(shots, 2, 160)
+	if err != nil {
+		t.Fatalf("Failed to assemble contact sheet: %v", err)
+	}
+	sheet, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Contact sheet is not a PNG image: %v", err)
+	}
+
+	// Two columns and two rows of 160x90 thumbnails with 16px spacing
+	if got := sheet.Bounds(); got.Dx() != 2*160+3*16 || got.Dy() != 2*90+3*16 {
+		t.Errorf("Expected a 368x228 sheet, got %dx%d", got.Dx(), got.Dy())
+	}
+	for _, tt := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{16 + 80, 16 + 45, red},
+		{2*16 + 160 + 80, 16 + 45, blue},
+		{16 + 80, 2*16 + 90 + 45, red},
+	} {
+		if got := color.RGBAModel.Convert(sheet.At(tt.x, tt.y)).(color.RGBA); got != tt.want {
+			t.Errorf("Expected %v at %d,%d, got %v", tt.want, tt.x, tt.y, got)
+		}
+	}
+
+	if _, err := contactSheet(nil, 4, 160); err == nil {
+		t.Error("Expected an error without slides")
+	}
+}
+
+func TestScreenshotOptions(t *testing.T) {
+	opts, err := ScreenshotOptions{Format: "WEBP"}.withDefaults(script.DefaultViewport)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Width != 1920 || opts.Height != 1080 || opts.Format != ImageFormatWebP || opts.Quality != 90 || opts.Columns != 4 {
+		t.Errorf("Unexpected defaults: %+v", opts)
+	}
+
+	for _, bad := range []ScreenshotOptions{{Format: "gif"}, {ContactSheet: "sheet.jpg"}} {
+		if _, err := bad.withDefaults(script.DefaultViewport); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestExportScreenshotsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping screenshot integration test in short mode")
+	}
+
+	testScript := &script.Script{
+		Title: "Screenshot Test",
+		Slides: []script.Slide{
+			{Title: "First Slide", Content: "One", Duration: 2 * time.Second},
+			{Title: "Second Slide", Content: "Two", Duration: 2 * time.Second},
+		},
+	}
+
+	tmpDir := t.TempDir()
+	htmlFile := filepath.Join(tmpDir, "presentation.html")
+	if err := generator.NewHTMLGenerator().GeneratePresentation(testScript, htmlFile, "modern", false); err != nil {
+		t.Fatalf("Failed to generate presentation: %v", err)
+	}
+
+	for _, format := range []string{ImageFormatPNG, ImageFormatWebP} {
+		t.Run(format, func(t *testing.T) {
+			outputDir := filepath.Join(tmpDir, format)
+			sheetPath := filepath.Join(outputDir, "sheet."+format)
+			paths, err := NewPresentationPlayer().ExportScreenshots(htmlFile, outputDir, ScreenshotOptions{
+				Width:        1280,
+				Height:       720,
+				Format:       format,
+				ContactSheet: sheetPath,
+			})
+			if err != nil {
+				t.Fatalf("Failed to export screenshots: %v", err)
+			}
+			if len(paths) != 2 || filepath.Base(paths[1]) != "slide_02."+format {
+				t.Fatalf("Expected slide_01.%s and slide_02.%s, got %v", format, format, paths)
+			}
+
+			for _, path := range append(paths, sheetPath) {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatalf("Failed to open %s: %v", path, err)
+				}
+				config, got, err := image.DecodeConfig(f)
+				f.Close()
+				if err != nil {
+					t.Fatalf("Failed to decode %s: %v", path, err)
+				}
+				if got != format {
+					t.Errorf("Expected %s to be %s, got %s", path, format, got)
+				}
+				if path != sheetPath && (config.Width != 1280 || config.Height != 720) {
+					t.Errorf("Expected %s to be 1280x720, got %dx%d", path, config.Width, config.Height)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package player
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// segmentFPS is the constant frame rate clips are normalized to, so they can
+// be joined without re-encoding
+const segmentFPS = 25
+
+// segmentSettle is how much longer than its slides a clip is recorded, so the
+// last frames are never cut short
+const segmentSettle = 500 * time.Millisecond
+
+// SegmentOptions control how a presentation is recorded in segments
+type SegmentOptions struct {
+	// SlidesPerSegment is the number of slides in every clip; zero records one slide per clip
+	SlidesPerSegment int
+	// Headless hides the browser
+	Headless bool
+	// Timeout bounds the wait for diagrams and images to render before each clip
+	Timeout time.Duration
+}
+
+// Segment is one recorded clip of a presentation
+type Segment struct {
+	// Path is the clip file
+	Path string
+	// First is the index of the first slide in the clip and Count the number of slides
+	First, Count int
+	// Duration is the exact length of the clip, the sum of its slide durations
+	Duration time.Duration
+}
+
+// RecordSegments records a presentation as one clip per slide, or per
+// SlidesPerSegment slides, and joins the clips into recordPath with the ffmpeg
+// concat demuxer. Every clip is recorded in a fresh browser context, so no
+// recording runs into browser length limits, and is cut to the exact length of
+// its slides. The clips are kept in a _segments directory next to recordPath.
+func (p *PresentationPlayer) RecordSegments(htmlPath, recordPath string, opts SegmentOptions) ([]Segment, error) {
+	perSegment := opts.SlidesPerSegment
+	if perSegment <= 0 {
+		perSegment = 1
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRenderTimeout
+	}
+
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found in PATH. Please install ffmpeg to record in segments")
+	}
+
+	absolutePath, err := filepath.Abs(htmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	fileURL := fmt.Sprintf("file://%s", absolutePath)
+
+	segmentDir := strings.TrimSuffix(recordPath, filepath.Ext(recordPath)) + "_segments"
+	if err := os.MkdirAll(segmentDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create segment directory: %w", err)
+	}
+	rawDir, err := os.MkdirTemp("", "rhesis_segments_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(rawDir)
+
+	// Recording is per segment, never for the whole run
+	p.recordPath = ""
+	if err := p.initializeWithOptions(opts.Headless); err != nil {
+		return nil, fmt.Errorf("failed to initialize player: %w", err)
+	}
+	defer p.cleanup()
+
+	if _, err := p.page.Goto(fileURL); err != nil {
+		return nil, fmt.Errorf("failed to load presentation: %w", err)
+	}
+	durations, err := p.slideDurations()
+	if err != nil {
+		return nil, err
+	}
+	p.page.Close()
+	p.context.Close()
+	p.page, p.context = nil, nil
+
+	segments := planSegments(durations, perSegment, segmentDir, filepath.Ext(recordPath))
+	for i := range segments {
+		segment := &segments[i]
+		fmt.Printf("Recording segment %d of %d (slides %d-%d, %.1f seconds)\n",
+			i+1, len(segments), segment.First+1, segment.First+segment.Count, segment.Duration.Seconds())
+
+		raw, offset, err := p.recordSegment(fileURL, rawDir, *segment, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record segment %d: %w", i+1, err)
+		}
+
+		cmd := exec.Command(ffmpegPath, trimArgs(raw, segment.Path, offset, segment.Duration)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to cut segment %d: %w\nOutput: %s", i+1, err, string(output))
+		}
+	}
+
+	if err := concatSegments(ffmpegPath, segments, recordPath); err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// slideDurations returns the duration of every slide of the loaded presentation
+func (p *PresentationPlayer) slideDurations() ([]time.Duration, error) {
+	values, err := p.page.Evaluate(`() => [...document.querySelectorAll('.slide')].map(slide => parseFloat(slide.dataset.duration) * 1000)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slide durations: %w", err)
+	}
+	list, ok := values.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected slide durations type: %T", values)
+	}
+	durations := make([]time.Duration, len(list))
+	for i, value := range list {
+		ms, err := milliseconds(value)
+		if err != nil {
+			return nil, err
+		}
+		durations[i] = time.Duration(ms) * time.Millisecond
+	}
+	return durations, nil
+}
+
+// recordSegment plays the slides of a segment in a context of its own and
+// returns the raw recording and the time into it at which playback started
+func (p *PresentationPlayer) recordSegment(fileURL, rawDir string, segment Segment, timeout time.Duration) (string, time.Duration, error) {
+	dir := filepath.Join(rawDir, strconv.Itoa(segment.First))
+	recordingStart := time.Now()
+	if err := p.newPage(dir); err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		if p.page != nil {
+			p.page.Close()
+		}
+		if p.context != nil {
+			p.context.Close()
+		}
+		p.page, p.context = nil, nil
+	}()
+
+	if _, err := p.page.Goto(fileURL); err != nil {
+		return "", 0, fmt.Errorf("failed to load presentation: %w", err)
+	}
+	if _, err := p.page.WaitForSelector("[data-ready='true']"); err != nil {
+		return "", 0, fmt.Errorf("failed to wait for presentation ready: %w", err)
+	}
+	if _, err := p.page.AddStyleTag(playwright.PageAddStyleTagOptions{Content: playwright.String(screenshotStyle)}); err != nil {
+		return "", 0, fmt.Errorf("failed to hide controls: %w", err)
+	}
+	if err := p.waitForRender(timeout); err != nil {
+		return "", 0, err
+	}
+
+	if _, err := p.page.Evaluate(`index => { showSlide(index); startPresentation(); }`, segment.First); err != nil {
+		return "", 0, fmt.Errorf("failed to start playback: %w", err)
+	}
+	offset := time.Since(recordingStart)
+
+	time.Sleep(segment.Duration + segmentSettle)
+	if _, err := p.page.Evaluate(`() => stopPresentation()`); err != nil {
+		return "", 0, fmt.Errorf("failed to stop playback: %w", err)
+	}
+
+	video := p.page.Video()
+	if video == nil {
+		return "", 0, fmt.Errorf("page was not recorded")
+	}
+	raw, err := video.Path()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get video path: %w", err)
+	}
+
+	// Closing the context writes the video
+	p.page.Close()
+	p.context.Close()
+	p.page, p.context = nil, nil
+	return raw, offset, nil
+}
+
+// planSegments groups consecutive slides into segments of perSegment slides
+func planSegments(durations []time.Duration, perSegment int, dir, ext string) []Segment {
+	var segments []Segment
+	for first := 0; first < len(durations); first += perSegment {
+		count := min(perSegment, len(durations)-first)
+		var total time.Duration
+		for _, d := range durations[first : first+count] {
+			total += d
+		}
+		segments = append(segments, Segment{
+			Path:     filepath.Join(dir, fmt.Sprintf("segment_%02d%s", len(segments)+1, ext)),
+			First:    first,
+			Count:    count,
+			Duration: total,
+		})
+	}
+	return segments
+}
+
+// trimArgs returns the ffmpeg arguments that cut duration from a raw
+// recording, starting at offset. Frames are normalized to a constant rate
+// and the last frame is held if the recording falls short, so the clip lasts
+// exactly duration and all clips share the same encoding.
+func trimArgs(raw, clipPath string, offset, duration time.Duration) []string {
+	seconds := strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+	args := []string{"-y", "-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64), "-i", raw, "-an"}
+	args = append(args, videoCodecArgs(clipPath)...)
+	filter := fmt.Sprintf("fps=%d,tpad=stop_mode=clone:stop_duration=%s,%s", segmentFPS, seconds, evenScale)
+	return append(args, "-vf", filter, "-pix_fmt", "yuv420p", "-t", seconds, clipPath)
+}
+
+// concatList returns the concat demuxer script that joins the clips
+func concatList(segments []Segment) (string, error) {
+	var b strings.Builder
+	for _, segment := range segments {
+		path, err := filepath.Abs(segment.Path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "file '%s'\n", strings.ReplaceAll(path, "'", `'\''`))
+		fmt.Fprintf(&b, "duration %s\n", strconv.FormatFloat(segment.Duration.Seconds(), 'f', 3, 64))
+	}
+	return b.String(), nil
+}
+
+// concatSegments joins the clips into outputPath without re-encoding them
+func concatSegments(ffmpegPath string, segments []Segment, outputPath string) error {
+	list, err := concatList(segments)
+	if err != nil {
+		return fmt.Errorf("failed to list segments: %w", err)
+	}
+	listFile, err := os.CreateTemp("", "rhesis_concat_*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create segment list: %w", err)
+	}
+	defer os.Remove(listFile.Name())
+	if _, err := listFile.WriteString(list); err != nil {
+		listFile.Close()
+		return fmt.Errorf("failed to write segment list: %w", err)
+	}
+	listFile.Close()
+
+	cmd := exec.Command(ffmpegPath, "-y", "-f", "concat", "-safe", "0", "-i", listFile.Name(), "-c", "copy", outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to join segments: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package player
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmcarbo/rhesis/internal/audio"
+	"github.com/jmcarbo/rhesis/internal/generator"
+	"github.com/jmcarbo/rhesis/internal/script"
+)
+
+func TestPlanSegments(t *testing.T) {
+	durations := []time.Duration{5 * time.Second, 2500 * time.Millisecond, 4 * time.Second, 1 * time.Second, 3 * time.Second}
+
+	segments := planSegments(durations, 2, "out", ".mp4")
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d", len(segments))
+	}
+	want := []Segment{
+		{Path: filepath.Join("out", "segment_01.mp4"), First: 0, Count: 2, Duration: 7500 * time.Millisecond},
+		{Path: filepath.Join("out", "segment_02.mp4"), First: 2, Count: 2, Duration: 5 * time.Second},
+		{Path: filepath.Join("out", "segment_03.mp4"), First: 4, Count: 1, Duration: 3 * time.Second},
+	}
+	for i, got := range segments {
+		if got != want[i] {
+			t.Errorf("Segment %d: expected %+v, got %+v", i+1, want[i], got)
+		}
+	}
+
+	if got := len(planSegments(durations, 1, "out", ".webm")); got != len(durations) {
+		t.Errorf("Expected one segment per slide, got %d", got)
+	}
+}
+
+func TestTrimArgs(t *testing.T) {
+	args := strings.Join(trimArgs("raw.webm", "segment_01.mp4", 1234*time.Millisecond, 7500*time.Millisecond), " ")
+	for _, want := range []string{
+		"-ss 1.234 -i raw.webm -an",
+		"-c:v libx264",
+		"fps=25,tpad=stop_mode=clone:stop_duration=7.500",
+		"-t 7.500 segment_01.mp4",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected %q in %s", want, args)
+		}
+	}
+}
+
+func TestConcatList(t *testing.T) {
+	dir := t.TempDir()
+	segments := []Segment{
+		{Path: filepath.Join(dir, "segment_01.mp4"), Duration: 7500 * time.Millisecond},
+		{Path: filepath.Join(dir, "it's", "segment_02.mp4"), Duration: 3 * time.Second},
+	}
+
+	list, err := concatList(segments)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "file '" + filepath.Join(dir, "segment_01.mp4") + "'\nduration 7.500\n" +
+		"file '" + filepath.Join(dir, `it'\''s`, "segment_02.mp4") + "'\nduration 3.000\n"
+	if list != want {
+		t.Errorf("Expected list:\n%s\ngot:\n%s", want, list)
+	}
+}
+
+func TestRecordSegmentsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping segment integration test in short mode")
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not available")
+	}
+
+	testScript := &script.Script{
+		Title: "Segment Test",
+		Slides: []script.Slide{
+			{Title: "First Slide", Content: "One", Duration: 1500 * time.Millisecond},
+			{Title: "Second Slide", Content: "Two", Duration: 1 * time.Second},
+			{Title: "Third Slide", Content: "Three", Duration: 1 * time.Second},
+		},
+	}
+
+	tmpDir := t.TempDir()
+	htmlFile := filepath.Join(tmpDir, "presentation.html")
+	if err := generator.NewHTMLGenerator().GeneratePresentation(testScript, htmlFile, "modern", false); err != nil {
+		t.Fatalf("Failed to generate presentation: %v", err)
+	}
+
+	videoFile := filepath.Join(tmpDir, "recording.mp4")
+	segments, err := NewPresentationPlayer().RecordSegments(htmlFile, videoFile, SegmentOptions{SlidesPerSegment: 2, Headless: true})
+	if err != nil {
+		t.Fatalf("Failed to record segments: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(segments))
+	}
+	for _, segment := range segments {
+		if _, err := os.Stat(segment.Path); err != nil {
+			t.Errorf("Expected clip %s: %v", segment.Path, err)
+		}
+	}
+
+	duration, err := audio.GetVideoDuration(videoFile)
+	if err != nil {
+		t.Fatalf("Failed to get video duration: %v", err)
+	}
+	if diff := duration - testScript.GetTotalDuration(); diff < -100*time.Millisecond || diff > 100*time.Millisecond {
+		t.Errorf("Expected the video to last %v, got %v", testScript.GetTotalDuration(), duration)
+	}
+}
@@ -0,0 +1,56 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jmcarbo/rhesis/internal/imageembed"
+)
+
+// inlineImagePattern matches the destination of inline Markdown images: ![alt](destination "title")
+var inlineImagePattern = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+
+// ResolvePath resolves a path found in the script's front matter against the script's directory
+func (s *Script) ResolvePath(path string) string {
+	return resolvePath(s.BaseDir, path)
+}
+
+// ResolvePath resolves an asset path referenced by the slide against the
+// directory of the file the slide was defined in
+func (s Slide) ResolvePath(path string) string {
+	if s.Source == "" {
+		return filepath.Clean(path)
+	}
+	return resolvePath(filepath.Dir(s.Source), path)
+}
+
+func resolvePath(baseDir, path string) string {
+	if baseDir == "" || filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// checkInlineImages reports inline Markdown images of the current slide that point to missing files
+func (p *parser) checkInlineImages(lineNum int, line string) {
+	trimmedLine := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmedLine, "```") || strings.HasPrefix(trimmedLine, "~~~") {
+		p.inFence = !p.inFence
+		return
+	}
+	if p.inFence {
+		return
+	}
+
+	for _, match := range inlineImagePattern.FindAllStringSubmatchIndex(line, -1) {
+		ref := line[match[2]:match[3]]
+		if !imageembed.IsLocal(ref) {
+			continue
+		}
+		if _, err := os.Stat(p.currentSlide.ResolvePath(ref)); err != nil {
+			p.errorf(lineNum, match[2]+1, CodeMissingImage, "image %q not found", ref)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package script
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// CueMarker placed in a transcription reveals the next fragment of the slide
+// when the narration reaches that point
+const CueMarker = "[>]"
+
+// extractCues removes cue markers from a transcription and returns the cleaned
+// text together with the byte offset of every marker in it
+func extractCues(transcription string) (string, []int) {
+	if !strings.Contains(transcription, CueMarker) {
+		return transcription, nil
+	}
+
+	var b strings.Builder
+	var cues []int
+	rest := transcription
+	for {
+		i := strings.Index(rest, CueMarker)
+		if i < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:i])
+		rest = rest[i+len(CueMarker):]
+
+		// Avoid leaving a double space where "word [>] word" was
+		text := b.String()
+		if (text == "" || strings.HasSuffix(text, " ") || strings.HasSuffix(text, "\n")) && strings.HasPrefix(rest, " ") {
+			rest = rest[1:]
+		}
+		cues = append(cues, b.Len())
+	}
+
+	text := b.String()
+	trimmed := strings.TrimLeft(text, " \t\n")
+	shift := len(text) - len(trimmed)
+	trimmed = strings.TrimRight(trimmed, " \t\n")
+	for i := range cues {
+		cues[i] = min(max(cues[i]-shift, 0), len(trimmed))
+	}
+	return trimmed, cues
+}
+
+// CueFractions returns the position of each cue as a fraction (0 to 1) of the
+// transcription length, used to time fragment reveals during playback
+func (s Slide) CueFractions() []float64 {
+	if len(s.Cues) == 0 {
+		return nil
+	}
+
+	total := utf8.RuneCountInString(s.Transcription)
+	fractions := make([]float64, len(s.Cues))
+	for i, offset := range s.Cues {
+		if total == 0 {
+			continue
+		}
+		fractions[i] = float64(utf8.RuneCountInString(s.Transcription[:offset])) / float64(total)
+	}
+	return fractions
+}
@@ -0,0 +1,89 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity describes how serious a parse diagnostic is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic codes reported by the parser
+const (
+	CodeMissingTitle       = "missing-title"
+	CodeInvalidFrontMatter = "invalid-front-matter"
+	CodeInvalidDuration    = "invalid-duration"
+	CodeInvalidDefaultTime = "invalid-default-time"
+	CodeUnknownDirective   = "unknown-directive"
+	CodeEmptySlide         = "empty-slide"
+	CodeMissingImage       = "missing-image"
+	CodeUnknownLayout      = "unknown-layout"
+	CodeMissingInclude     = "missing-include"
+	CodeIncludeCycle       = "include-cycle"
+	CodeMisplacedInclude   = "misplaced-include"
+	CodeMissingStylesheet  = "missing-stylesheet"
+	CodeUnsupportedVersion = "unsupported-version"
+	CodeInvalidCue         = "invalid-cue"
+)
+
+// Diagnostic is a problem found while parsing a script, located by line and column (both 1-based)
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic the way compilers do: file:line:column: severity: message [code].
+// The line and column are left out for diagnostics not tied to a line (Line 0),
+// and the whole location when there is no file either.
+func (d Diagnostic) String() string {
+	var parts []string
+	if d.File != "" {
+		parts = append(parts, d.File)
+	}
+	if d.Line > 0 {
+		parts = append(parts, fmt.Sprintf("%d:%d", d.Line, d.Column))
+	}
+	message := fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Code)
+	if len(parts) == 0 {
+		return message
+	}
+	return strings.Join(parts, ":") + ": " + message
+}
+
+// ParseError is returned by ParseScript when a script cannot be parsed at all.
+// It carries every diagnostic collected up to that point.
+type ParseError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ParseError) Error() string {
+	var messages []string
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			messages = append(messages, d.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return "failed to parse script"
+	}
+	return strings.Join(messages, "; ")
+}
+
+// HasErrors reports whether any error-level diagnostic was collected while parsing
+func (s *Script) HasErrors() bool {
+	for _, d := range s.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
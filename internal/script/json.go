@@ -0,0 +1,282 @@
+package script
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// JSONVersion is the version of the JSON representation written by WriteJSON.
+// It is bumped whenever a change would break existing readers.
+const JSONVersion = 1
+
+// JSONSchema is the JSON Schema describing the JSON representation of a script
+//
+//go:embed script.schema.json
+var JSONSchema []byte
+
+// jsonScript is the versioned JSON representation of a Script. Durations are
+// expressed in seconds.
+type jsonScript struct {
+	Version     int           `json:"version"`
+	Title       string        `json:"title"`
+	Duration    float64       `json:"duration,omitempty"`
+	DefaultTime float64       `json:"defaultTime,omitempty"`
+	Metadata    *jsonMetadata `json:"metadata,omitempty"`
+	BaseDir     string        `json:"baseDir,omitempty"`
+	Includes    []jsonInclude `json:"includes,omitempty"`
+	Slides      []jsonSlide   `json:"slides"`
+	Diagnostics []Diagnostic  `json:"diagnostics,omitempty"`
+}
+
+type jsonMetadata struct {
+	Author        string                 `json:"author,omitempty"`
+	Date          string                 `json:"date,omitempty"`
+	Language      string                 `json:"language,omitempty"`
+	Theme         string                 `json:"theme,omitempty"`
+	Voice         string                 `json:"voice,omitempty"`
+	Model         string                 `json:"model,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	Transcription *bool                  `json:"transcription,omitempty"`
+	Resolution    string                 `json:"resolution,omitempty"`
+	Aspect        string                 `json:"aspect,omitempty"`
+	DeviceScale   float64                `json:"deviceScale,omitempty"`
+	TTS           string                 `json:"tts,omitempty"`
+	TTSProviders  map[string]TTSConfig   `json:"ttsProviders,omitempty"`
+	Extra         map[string]interface{} `json:"extra,omitempty"`
+}
+
+type jsonInclude struct {
+	Path  string `json:"path"`
+	Index int    `json:"index"`
+	Count int    `json:"count"`
+}
+
+type jsonSlide struct {
+	Title             string  `json:"title"`
+	Content           string  `json:"content,omitempty"`
+	Image             string  `json:"image,omitempty"`
+	ImagePath         string  `json:"imagePath,omitempty"`
+	Layout            string  `json:"layout,omitempty"`
+	Transcription     string  `json:"transcription,omitempty"`
+	Cues              []int   `json:"cues,omitempty"`
+	Notes             string  `json:"notes,omitempty"`
+	Duration          float64 `json:"duration,omitempty"`
+	FixedDuration     bool    `json:"fixedDuration,omitempty"`
+	NarrationDuration float64 `json:"narrationDuration,omitempty"`
+	Source            string  `json:"source,omitempty"`
+}
+
+// WriteJSON writes the script in its versioned JSON representation, including
+// resolved slide durations, resolved asset paths and parse diagnostics. Source
+// files and asset paths are written as absolute paths so the JSON can be read
+// from any directory.
+func (s *Script) WriteJSON(w io.Writer) error {
+	doc := jsonScript{
+		Version:     JSONVersion,
+		Title:       s.Title,
+		Duration:    s.Duration.Seconds(),
+		DefaultTime: s.DefaultTime.Seconds(),
+		BaseDir:     absPath(s.BaseDir),
+		Slides:      make([]jsonSlide, len(s.Slides)),
+		Diagnostics: s.Diagnostics,
+	}
+
+	metadata := jsonMetadata{
+		Author:        s.Author,
+		Date:          s.Date,
+		Language:      s.Language,
+		Theme:         s.Theme,
+		Voice:         s.Voice,
+		Model:         s.Model,
+		Tags:          s.Tags,
+		Transcription: s.Transcription,
+		Resolution:    s.Resolution,
+		Aspect:        s.Aspect,
+		DeviceScale:   s.DeviceScale,
+		TTS:           s.TTS,
+		TTSProviders:  s.TTSProviders,
+		Extra:         s.Extra,
+	}
+	if metadata.Author != "" || metadata.Date != "" || metadata.Language != "" || metadata.Theme != "" ||
+		metadata.Voice != "" || metadata.Model != "" || len(metadata.Tags) > 0 ||
+		metadata.Transcription != nil || metadata.Resolution != "" || metadata.Aspect != "" ||
+		metadata.DeviceScale != 0 || metadata.TTS != "" || len(metadata.TTSProviders) > 0 ||
+		len(metadata.Extra) > 0 {
+		doc.Metadata = &metadata
+	}
+
+	for _, include := range s.Includes {
+		doc.Includes = append(doc.Includes, jsonInclude(include))
+	}
+
+	for i, slide := range s.Slides {
+		doc.Slides[i] = jsonSlide{
+			Title:             slide.Title,
+			Content:           slide.Content,
+			Image:             slide.Image,
+			Layout:            slide.Layout,
+			Transcription:     slide.Transcription,
+			Cues:              slide.Cues,
+			Notes:             slide.Notes,
+			Duration:          slide.Duration.Seconds(),
+			FixedDuration:     slide.FixedDuration,
+			NarrationDuration: slide.NarrationDuration.Seconds(),
+			Source:            absPath(slide.Source),
+		}
+		if slide.Image != "" {
+			doc.Slides[i].ImagePath = absPath(slide.ResolvePath(slide.Image))
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// ParseJSON reads a script from its JSON representation. Slides without a
+// source resolve their assets relative to the JSON file, and images with a
+// resolved imagePath are taken from there.
+func ParseJSON(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc jsonScript
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON script: %w", err)
+	}
+
+	p := &parser{
+		script: &Script{
+			Title:       doc.Title,
+			Duration:    seconds(doc.Duration),
+			DefaultTime: 10 * time.Second,
+			BaseDir:     filepath.Dir(path),
+		},
+		path: path,
+	}
+	script := p.script
+
+	if doc.Version != JSONVersion {
+		p.fatalf(1, 1, CodeUnsupportedVersion, "unsupported JSON script version %d, expected %d", doc.Version, JSONVersion)
+	}
+	if doc.DefaultTime > 0 {
+		script.DefaultTime = seconds(doc.DefaultTime)
+	} else if doc.DefaultTime < 0 {
+		p.errorf(1, 1, CodeInvalidDefaultTime, "invalid default time %v: must be positive", doc.DefaultTime)
+	}
+	if doc.Duration < 0 {
+		p.errorf(1, 1, CodeInvalidDuration, "invalid duration %v: must be positive", doc.Duration)
+		script.Duration = 0
+	}
+
+	if m := doc.Metadata; m != nil {
+		script.Author = m.Author
+		script.Date = m.Date
+		script.Language = m.Language
+		script.Theme = m.Theme
+		script.Voice = m.Voice
+		script.Model = m.Model
+		script.Tags = m.Tags
+		script.Transcription = m.Transcription
+		script.Resolution = m.Resolution
+		script.Aspect = m.Aspect
+		script.DeviceScale = m.DeviceScale
+		script.TTS = m.TTS
+		script.TTSProviders = m.TTSProviders
+		script.Extra = m.Extra
+	}
+
+	for _, include := range doc.Includes {
+		script.Includes = append(script.Includes, Include(include))
+	}
+
+	for i, js := range doc.Slides {
+		slide := Slide{
+			Title:             js.Title,
+			Content:           js.Content,
+			Image:             js.Image,
+			Layout:            strings.ToLower(js.Layout),
+			Transcription:     js.Transcription,
+			Cues:              js.Cues,
+			Notes:             js.Notes,
+			Duration:          script.DefaultTime,
+			FixedDuration:     js.FixedDuration,
+			NarrationDuration: seconds(js.NarrationDuration),
+			Source:            js.Source,
+		}
+		if slide.Source == "" {
+			slide.Source = path
+		}
+		if js.ImagePath != "" && slide.ResolvePath(slide.Image) != filepath.Clean(js.ImagePath) {
+			slide.Image = js.ImagePath
+		}
+		if js.Duration > 0 {
+			slide.Duration = seconds(js.Duration)
+		} else if js.Duration < 0 {
+			p.errorf(1, 1, CodeInvalidDuration, "slide %d: invalid duration %v: must be positive", i+1, js.Duration)
+		}
+		if slide.Layout != LayoutDefault && !IsValidLayout(slide.Layout) {
+			p.warnf(1, 1, CodeUnknownLayout, "slide %d: unknown layout %q, expected one of: %s",
+				i+1, slide.Layout, strings.Join(Layouts(), ", "))
+			slide.Layout = LayoutDefault
+		}
+		for _, cue := range slide.Cues {
+			if cue < 0 || cue > len(slide.Transcription) {
+				p.errorf(1, 1, CodeInvalidCue, "slide %d: cue offset %d is outside the transcription", i+1, cue)
+				slide.Cues = nil
+				break
+			}
+		}
+		if slide.Image != "" {
+			if _, err := os.Stat(slide.ResolvePath(slide.Image)); err != nil {
+				p.errorf(1, 1, CodeMissingImage, "slide %d: image %q not found", i+1, slide.Image)
+			}
+		}
+		script.Slides = append(script.Slides, slide)
+	}
+
+	if script.Title == "" {
+		p.fatalf(1, 1, CodeMissingTitle, "presentation must have a title")
+	}
+	if p.fatal {
+		return nil, &ParseError{Diagnostics: script.Diagnostics}
+	}
+	return script, nil
+}
+
+// Load reads a script from Markdown, or from its JSON representation when the
+// file has a .json extension
+func Load(path string) (*Script, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(path)
+	}
+	return ParseScript(path)
+}
+
+// absPath makes a path absolute, leaving it unchanged when it is empty or
+// cannot be resolved
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
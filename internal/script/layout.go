@@ -0,0 +1,68 @@
+package script
+
+import "strings"
+
+// Built-in slide layouts selectable with the `Layout:` slide directive
+const (
+	LayoutDefault    = ""
+	LayoutTitle      = "title"
+	LayoutSection    = "section"
+	LayoutTwoColumn  = "two-column"
+	LayoutImageLeft  = "image-left"
+	LayoutImageRight = "image-right"
+	LayoutFullBleed  = "full-bleed"
+	LayoutQuote      = "quote"
+	LayoutCodeFocus  = "code-focus"
+)
+
+// ColumnSeparator is the line that splits slide content into columns
+const ColumnSeparator = "|||"
+
+// Layouts returns the names of the built-in layouts
+func Layouts() []string {
+	return []string{
+		LayoutTitle,
+		LayoutSection,
+		LayoutTwoColumn,
+		LayoutImageLeft,
+		LayoutImageRight,
+		LayoutFullBleed,
+		LayoutQuote,
+		LayoutCodeFocus,
+	}
+}
+
+// IsValidLayout reports whether name is one of the built-in layouts
+func IsValidLayout(name string) bool {
+	for _, layout := range Layouts() {
+		if layout == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Columns splits the slide content on ColumnSeparator lines. Separators inside
+// fenced code blocks are left alone. Content without separators is returned as
+// a single column.
+func (s Slide) Columns() []string {
+	var columns []string
+	var current []string
+	inFence := false
+
+	for _, line := range strings.Split(s.Content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && trimmed == ColumnSeparator {
+			columns = append(columns, strings.TrimSpace(strings.Join(current, "\n")))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	columns = append(columns, strings.TrimSpace(strings.Join(current, "\n")))
+
+	return columns
+}
@@ -3,170 +3,670 @@ package script
 import (
 	"bufio"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Script struct {
 	Title       string
-	Duration    int
+	Duration    time.Duration
 	Slides      []Slide
-	DefaultTime int
+	DefaultTime time.Duration
+
+	// Presentation-wide metadata from the optional YAML front matter
+	Author        string
+	Date          string
+	Language      string
+	Theme         string
+	Voice         string
+	Model         string
+	Tags          []string
+	Transcription *bool
+	Extra         map[string]interface{}
+
+	// Resolution (WIDTHxHEIGHT), Aspect (W:H) and DeviceScale set the
+	// viewport; see Viewport
+	Resolution  string
+	Aspect      string
+	DeviceScale float64
+
+	// TTS names the text-to-speech provider and TTSProviders holds settings
+	// per provider; see SpeechConfig
+	TTS          string
+	TTSProviders map[string]TTSConfig
+
+	// Includes lists the Include: directives of the script file itself
+	Includes []Include
+
+	// BaseDir is the directory of the script file; relative asset paths in
+	// the script and its front matter are resolved against it
+	BaseDir string
+
+	// Diagnostics collected while parsing
+	Diagnostics []Diagnostic
+}
+
+// Include records an Include: directive and the slides it spliced in
+type Include struct {
+	// Path is the included file as written in the directive
+	Path string
+	// Index is the position of the first included slide in Script.Slides
+	Index int
+	// Count is the number of slides the include contributed
+	Count int
 }
 
 type Slide struct {
 	Title         string
 	Content       string
 	Image         string
+	Layout        string
 	Transcription string
-	Duration      int
+	Notes         string
+	Duration      time.Duration
+
+	// FixedDuration is set when the slide has its own Duration: line, which
+	// FitDuration leaves untouched
+	FixedDuration bool
+
+	// Cues are the byte offsets in Transcription where cue markers ([>]) were
+	// placed; each one reveals the next fragment of the slide
+	Cues []int
+
+	// NarrationDuration is the length of the slide's generated audio, if any.
+	// Cue reveals are timed against it instead of Duration when set.
+	NarrationDuration time.Duration
+
+	// Source is the file the slide was defined in, which differs from the
+	// script path for slides spliced in with Include
+	Source string
+}
+
+// frontMatter mirrors the keys accepted in the `---` delimited YAML block
+// at the top of a script. Unknown keys are kept in Extra.
+type frontMatter struct {
+	Author        string                 `yaml:"author,omitempty"`
+	Date          string                 `yaml:"date,omitempty"`
+	Language      string                 `yaml:"language,omitempty"`
+	Theme         string                 `yaml:"theme,omitempty"`
+	Voice         string                 `yaml:"voice,omitempty"`
+	Model         string                 `yaml:"model,omitempty"`
+	Tags          []string               `yaml:"tags,omitempty"`
+	Transcription *bool                  `yaml:"transcription,omitempty"`
+	Resolution    string                 `yaml:"resolution,omitempty"`
+	Aspect        string                 `yaml:"aspect,omitempty"`
+	DeviceScale   float64                `yaml:"deviceScale,omitempty"`
+	TTS           string                 `yaml:"tts,omitempty"`
+	TTSProviders  map[string]TTSConfig   `yaml:"ttsProviders,omitempty"`
+	Extra         map[string]interface{} `yaml:",inline"`
+}
+
+// applyFrontMatter decodes the YAML front matter into the script metadata
+func (s *Script) applyFrontMatter(data string) error {
+	var fm frontMatter
+	if err := yaml.Unmarshal([]byte(data), &fm); err != nil {
+		return err
+	}
+
+	s.Author = fm.Author
+	s.Date = fm.Date
+	s.Language = fm.Language
+	s.Theme = fm.Theme
+	s.Voice = fm.Voice
+	s.Model = fm.Model
+	s.Tags = fm.Tags
+	s.Transcription = fm.Transcription
+	s.Resolution = fm.Resolution
+	s.Aspect = fm.Aspect
+	s.DeviceScale = fm.DeviceScale
+	s.TTS = fm.TTS
+	s.TTSProviders = fm.TTSProviders
+	if len(fm.Extra) > 0 {
+		s.Extra = fm.Extra
+	}
+	return nil
+}
+
+// directivePattern matches lines shaped like a `Key: value` directive
+var directivePattern = regexp.MustCompile(`^([A-Z][A-Za-z]*(?: [A-Za-z]+)?):\s+\S`)
+
+// directiveKeywords start lines the parser may read as directives inside a
+// slide. Slide text starting with one is escaped with a backslash.
+var directiveKeywords = []string{"Duration:", "Default time:", "Image:", "Layout:", "Include:", "Notes:", "Transcription:"}
+
+// EscapeLine guards a line of slide text that would be read as a directive,
+// such as "Image: see below", by putting a backslash before it. The parser
+// removes one leading backslash from such lines, so lines already starting
+// with backslashes keep them.
+func EscapeLine(line string) string {
+	rest := strings.TrimLeft(line, " \t")
+	if !isDirectiveText(strings.TrimLeft(rest, "\\")) {
+		return line
+	}
+	return line[:len(line)-len(rest)] + "\\" + rest
+}
+
+// EscapeText applies EscapeLine to every line of text
+func EscapeText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = EscapeLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unescapeLine undoes EscapeLine
+func unescapeLine(line string) string {
+	rest := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(rest, "\\") || !isDirectiveText(strings.TrimLeft(rest, "\\")) {
+		return line
+	}
+	return line[:len(line)-len(rest)] + rest[1:]
+}
+
+func isDirectiveText(text string) bool {
+	for _, keyword := range directiveKeywords {
+		if strings.HasPrefix(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// parser holds the state of a single ParseScript run. Included files are
+// parsed by child parsers whose slides and diagnostics are spliced into the parent.
+type parser struct {
+	script *Script
+	path   string
+
+	// stack holds the absolute paths of the files being parsed, outermost first
+	stack []string
+
+	currentSlide     *Slide
+	currentSlideLine int
+	inContent        bool
+	inTranscription  bool
+	inNotes          bool
+	inDirectives     bool
+	inFrontMatter    bool
+	inFence          bool
+	frontMatterLine  int
+	fatal            bool
+
+	// pending holds Include: lines met inside a slide, and the blank lines
+	// after them, until it is known whether the slide ends there
+	pending []pendingLine
+
+	contentBuilder, transcriptionBuilder, notesBuilder, frontMatterBuilder strings.Builder
 }
 
 func ParseScript(path string) (*Script, error) {
-	file, err := os.Open(path)
+	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	script := &Script{
-		DefaultTime: 10, // Default to 10 seconds if not specified
+	p := &parser{
+		script: &Script{
+			DefaultTime: 10 * time.Second, // Default to 10 seconds if not specified
+			BaseDir:     filepath.Dir(path),
+		},
+		path:  path,
+		stack: []string{absPath},
 	}
 
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	if p.inFrontMatter {
+		p.fatalf(p.frontMatterLine, 1, CodeInvalidFrontMatter, "front matter is not closed (missing ---)")
+	}
+
+	// A custom stylesheet named in the front matter lives next to the script
+	if theme := p.script.Theme; strings.HasSuffix(strings.ToLower(theme), ".css") {
+		if _, err := os.Stat(p.script.ResolvePath(theme)); err != nil {
+			p.errorf(p.frontMatterLine, 1, CodeMissingStylesheet, "stylesheet %q not found", theme)
+		}
+	}
+
+	if _, err := p.script.Viewport(); err != nil {
+		p.errorf(p.frontMatterLine, 1, CodeInvalidFrontMatter, "%v", err)
+	}
+
+	// Validate
+	if p.script.Title == "" {
+		p.fatalf(1, 1, CodeMissingTitle, "presentation must have a title (# Title)")
+	}
+
+	if p.fatal {
+		return nil, &ParseError{Diagnostics: p.script.Diagnostics}
+	}
+
+	return p.script, nil
+}
+
+// pendingLine is a line held back while deciding where an Include: belongs
+type pendingLine struct {
+	num  int
+	text string
+}
+
+// parse reads the parser's file line by line
+func (p *parser) parse() error {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
 	scanner := bufio.NewScanner(file)
-	var currentSlide *Slide
-	var inContent bool
-	var inTranscription bool
-	var contentBuilder, transcriptionBuilder strings.Builder
 	lineNum := 0
 
 	for scanner.Scan() {
 		lineNum++
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
+		p.parseLine(lineNum, scanner.Text())
+	}
+
+	// Save last slide
+	p.flushIncludes()
+	p.finishSlide()
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	return nil
+}
+
+// include parses another script and splices its slides at the current position
+func (p *parser) include(lineNum int, line string) {
+	target := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "Include:"))
+	column := valueColumn(line, "Include:")
+	if target == "" {
+		p.errorf(lineNum, column, CodeMissingInclude, "include directive without a path")
+		return
+	}
+
+	// Remember where the include was so the script can be written back with it
+	p.script.Includes = append(p.script.Includes, Include{Path: target, Index: len(p.script.Slides)})
+	record := &p.script.Includes[len(p.script.Includes)-1]
+
+	// Includes are resolved relative to the including file
+	includePath := target
+	if !filepath.IsAbs(includePath) {
+		includePath = filepath.Join(filepath.Dir(p.path), target)
+	}
+
+	absPath, err := filepath.Abs(includePath)
+	if err != nil {
+		p.errorf(lineNum, column, CodeMissingInclude, "cannot include %q: %v", target, err)
+		return
+	}
 
-		// Check for title (first H1)
-		if strings.HasPrefix(line, "# ") && script.Title == "" {
-			script.Title = strings.TrimPrefix(line, "# ")
-			continue
+	for i, parent := range p.stack {
+		if parent == absPath {
+			chain := append(append([]string{}, p.stack[i:]...), absPath)
+			p.errorf(lineNum, column, CodeIncludeCycle, "include cycle: %s", strings.Join(chain, " -> "))
+			return
 		}
+	}
+
+	child := &parser{
+		script: &Script{
+			DefaultTime: p.script.DefaultTime,
+		},
+		path:  includePath,
+		stack: append(append([]string{}, p.stack...), absPath),
+	}
 
-		// Check for metadata
-		if strings.HasPrefix(trimmedLine, "Duration:") && currentSlide == nil {
-			durationStr := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Duration:"))
-			if duration, err := strconv.Atoi(durationStr); err == nil {
-				script.Duration = duration
+	// A file that cannot be included leaves the presentation incomplete, so
+	// its errors stop the including script as well
+	if err := child.parse(); err != nil {
+		p.fatalf(lineNum, column, CodeMissingInclude, "cannot include %q: %v", target, err)
+		return
+	}
+	if child.inFrontMatter {
+		child.fatalf(child.frontMatterLine, 1, CodeInvalidFrontMatter, "front matter is not closed (missing ---)")
+	}
+	p.fatal = p.fatal || child.fatal
+
+	// Only slides and diagnostics cross the include boundary; title, metadata
+	// and default time of the included file stay local to it
+	p.script.Slides = append(p.script.Slides, child.script.Slides...)
+	record.Count = len(child.script.Slides)
+	p.script.Diagnostics = append(p.script.Diagnostics, child.script.Diagnostics...)
+}
+
+// parseLine consumes a single line of the script
+func (p *parser) parseLine(lineNum int, line string) {
+	script := p.script
+	trimmedLine := strings.TrimSpace(line)
+
+	// Check for YAML front matter (must open on the first line)
+	if lineNum == 1 && trimmedLine == "---" {
+		p.inFrontMatter = true
+		p.frontMatterLine = lineNum
+		return
+	}
+
+	if p.inFrontMatter {
+		if trimmedLine == "---" || trimmedLine == "..." {
+			if err := script.applyFrontMatter(p.frontMatterBuilder.String()); err != nil {
+				p.fatalf(p.frontMatterLine, 1, CodeInvalidFrontMatter, "invalid front matter: %v", err)
 			}
-			continue
+			p.inFrontMatter = false
+			return
 		}
+		p.frontMatterBuilder.WriteString(line)
+		p.frontMatterBuilder.WriteString("\n")
+		return
+	}
 
-		if strings.HasPrefix(trimmedLine, "Default time:") {
-			defaultTimeStr := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Default time:"))
-			if defaultTime, err := strconv.Atoi(defaultTimeStr); err == nil {
-				script.DefaultTime = defaultTime
-			}
-			continue
-		}
-
-		// Check for slide title (H2)
-		if strings.HasPrefix(line, "## ") {
-			// Save previous slide if exists
-			if currentSlide != nil {
-				if inContent {
-					currentSlide.Content = strings.TrimSpace(contentBuilder.String())
-					contentBuilder.Reset()
-					inContent = false
-				}
-				if inTranscription {
-					currentSlide.Transcription = strings.TrimSpace(transcriptionBuilder.String())
-					transcriptionBuilder.Reset()
-					inTranscription = false
-				}
-				script.Slides = append(script.Slides, *currentSlide)
-			}
+	// An Include: inside a slide only counts if nothing but blank lines and
+	// other includes follow it before the next slide
+	if len(p.pending) > 0 {
+		switch {
+		case trimmedLine == "" || strings.HasPrefix(line, "Include:"):
+			p.pending = append(p.pending, pendingLine{lineNum, line})
+			return
+		case strings.HasPrefix(line, "## "):
+			p.flushIncludes()
+		default:
+			p.keepIncludesAsText()
+		}
+	}
 
-			// Start new slide
-			currentSlide = &Slide{
-				Title:    strings.TrimPrefix(line, "## "),
-				Duration: script.DefaultTime,
-			}
-			inContent = true
-			continue
+	// Check for title (first H1)
+	if strings.HasPrefix(line, "# ") && script.Title == "" {
+		script.Title = strings.TrimPrefix(line, "# ")
+		return
+	}
+
+	// Check for metadata
+	if strings.HasPrefix(trimmedLine, "Duration:") && p.currentSlide == nil {
+		durationStr := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Duration:"))
+		if duration, err := ParseDuration(durationStr); err == nil {
+			script.Duration = duration
+		} else {
+			p.errorf(lineNum, valueColumn(line, "Duration:"), CodeInvalidDuration,
+				"invalid duration %q: %v", durationStr, err)
 		}
+		return
+	}
 
-		// Check for horizontal rule (transcription separator)
-		if trimmedLine == "---" && currentSlide != nil {
-			if inContent {
-				currentSlide.Content = strings.TrimSpace(contentBuilder.String())
-				contentBuilder.Reset()
-				inContent = false
-			}
-			inTranscription = true
-			continue
+	if strings.HasPrefix(trimmedLine, "Default time:") {
+		defaultTimeStr := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Default time:"))
+		if defaultTime, err := ParseDuration(defaultTimeStr); err == nil {
+			script.DefaultTime = defaultTime
+		} else {
+			p.errorf(lineNum, valueColumn(line, "Default time:"), CodeInvalidDefaultTime,
+				"invalid default time %q: %v", defaultTimeStr, err)
 		}
+		return
+	}
 
-		// Check for slide duration
-		if currentSlide != nil && strings.HasPrefix(trimmedLine, "Duration:") {
-			durationStr := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Duration:"))
-			if duration, err := strconv.Atoi(durationStr); err == nil {
-				currentSlide.Duration = duration
-			}
-			continue
-		}
-
-		// Check for image
-		if currentSlide != nil && strings.HasPrefix(trimmedLine, "Image:") {
-			imagePath := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Image:"))
-			currentSlide.Image = filepath.Clean(imagePath)
-			continue
-		}
-
-		// Accumulate content or transcription
-		if currentSlide != nil {
-			if inTranscription {
-				if transcriptionBuilder.Len() > 0 {
-					transcriptionBuilder.WriteString("\n")
-				}
-				transcriptionBuilder.WriteString(line)
-			} else if inContent {
-				if contentBuilder.Len() > 0 {
-					contentBuilder.WriteString("\n")
-				}
-				contentBuilder.WriteString(line)
-			}
+	// Check for include (splices the slides of another file). Inside a
+	// slide it waits to see whether the slide ends there; inside a code
+	// block it is text.
+	if strings.HasPrefix(line, "Include:") && !(p.inContent && p.inFence) {
+		if p.currentSlide == nil {
+			p.include(lineNum, line)
+		} else {
+			p.pending = append(p.pending, pendingLine{lineNum, line})
 		}
+		return
 	}
 
-	// Save last slide
-	if currentSlide != nil {
-		if inContent {
-			currentSlide.Content = strings.TrimSpace(contentBuilder.String())
+	// Check for slide title (H2)
+	if strings.HasPrefix(line, "## ") {
+		// Save previous slide if exists
+		p.finishSlide()
+
+		// Start new slide
+		p.currentSlide = &Slide{
+			Title:    strings.TrimPrefix(line, "## "),
+			Duration: script.DefaultTime,
+			Source:   p.path,
 		}
-		if inTranscription {
-			currentSlide.Transcription = strings.TrimSpace(transcriptionBuilder.String())
+		p.currentSlideLine = lineNum
+		p.inContent = true
+		p.inDirectives = true
+		return
+	}
+
+	// Check for horizontal rule (transcription separator)
+	if trimmedLine == "---" && p.currentSlide != nil {
+		if p.inContent {
+			p.currentSlide.Content = strings.TrimSpace(p.contentBuilder.String())
+			p.contentBuilder.Reset()
+			p.inContent = false
 		}
-		script.Slides = append(script.Slides, *currentSlide)
+		p.inNotes = false
+		p.inTranscription = true
+		p.inDirectives = false
+		return
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+	// Check for speaker notes (private to the presenter, never spoken)
+	if p.currentSlide != nil && (trimmedLine == "Notes:" || strings.HasPrefix(trimmedLine, "Notes: ")) {
+		if p.inContent {
+			p.currentSlide.Content = strings.TrimSpace(p.contentBuilder.String())
+			p.contentBuilder.Reset()
+			p.inContent = false
+		}
+		p.inTranscription = false
+		p.inNotes = true
+		p.inDirectives = false
+		if inline := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Notes:")); inline != "" {
+			p.appendLine(&p.notesBuilder, inline)
+		}
+		return
 	}
 
-	// Validate
-	if script.Title == "" {
-		return nil, fmt.Errorf("presentation must have a title (# Title)")
+	// Check for slide duration
+	if p.currentSlide != nil && strings.HasPrefix(trimmedLine, "Duration:") {
+		durationStr := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Duration:"))
+		if duration, err := ParseDuration(durationStr); err == nil {
+			p.currentSlide.Duration = duration
+			p.currentSlide.FixedDuration = true
+		} else {
+			p.errorf(lineNum, valueColumn(line, "Duration:"), CodeInvalidDuration,
+				"invalid slide duration %q: %v", durationStr, err)
+		}
+		return
+	}
+
+	// Check for image
+	if p.currentSlide != nil && strings.HasPrefix(trimmedLine, "Image:") {
+		imagePath := strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Image:"))
+		p.currentSlide.Image = filepath.Clean(imagePath)
+		if _, err := os.Stat(p.currentSlide.ResolvePath(p.currentSlide.Image)); err != nil {
+			p.errorf(lineNum, valueColumn(line, "Image:"), CodeMissingImage,
+				"image %q not found", imagePath)
+		}
+		return
+	}
+
+	// Check for layout
+	if p.currentSlide != nil && p.inDirectives && strings.HasPrefix(trimmedLine, "Layout:") {
+		layout := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmedLine, "Layout:")))
+		if IsValidLayout(layout) {
+			p.currentSlide.Layout = layout
+		} else {
+			p.warnf(lineNum, valueColumn(line, "Layout:"), CodeUnknownLayout,
+				"unknown layout %q, expected one of: %s", layout, strings.Join(Layouts(), ", "))
+		}
+		return
+	}
+
+	// Anything shaped like a directive where directives are expected is most likely a typo
+	if p.currentSlide == nil || p.inDirectives {
+		if match := directivePattern.FindStringSubmatch(trimmedLine); match != nil {
+			p.warnf(lineNum, strings.Index(line, match[1])+1, CodeUnknownDirective,
+				"unknown directive %q", match[1])
+		}
+		if p.currentSlide != nil && trimmedLine != "" {
+			p.inDirectives = false
+		}
+	}
+
+	// Accumulate content, transcription or notes
+	if p.currentSlide != nil {
+		text := unescapeLine(line)
+		if p.inNotes {
+			p.appendLine(&p.notesBuilder, text)
+		} else if p.inTranscription {
+			p.appendLine(&p.transcriptionBuilder, text)
+		} else if p.inContent {
+			p.appendLine(&p.contentBuilder, text)
+			p.checkInlineImages(lineNum, line)
+		}
 	}
+}
 
-	return script, nil
+// flushIncludes ends the current slide and splices in the pending includes
+func (p *parser) flushIncludes() {
+	if len(p.pending) == 0 {
+		return
+	}
+	p.finishSlide()
+	for _, pending := range p.pending {
+		if strings.HasPrefix(pending.text, "Include:") {
+			p.include(pending.num, pending.text)
+		}
+	}
+	p.pending = nil
+}
+
+// keepIncludesAsText puts the pending lines back into the slide section they
+// were met in, reporting every Include: as misplaced
+func (p *parser) keepIncludesAsText() {
+	for _, pending := range p.pending {
+		if strings.HasPrefix(pending.text, "Include:") {
+			p.warnf(pending.num, 1, CodeMisplacedInclude,
+				"Include: is only honored between slides; kept as slide text")
+		}
+		switch {
+		case p.inNotes:
+			p.appendLine(&p.notesBuilder, pending.text)
+		case p.inTranscription:
+			p.appendLine(&p.transcriptionBuilder, pending.text)
+		case p.inContent:
+			p.appendLine(&p.contentBuilder, pending.text)
+		}
+	}
+	p.pending = nil
+}
+
+// appendLine adds a line to one of the section builders
+func (p *parser) appendLine(b *strings.Builder, line string) {
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(line)
+}
+
+// finishSlide stores the slide being parsed, if any, and resets the slide state
+func (p *parser) finishSlide() {
+	if p.currentSlide == nil {
+		return
+	}
+
+	if p.inContent {
+		p.currentSlide.Content = strings.TrimSpace(p.contentBuilder.String())
+		p.contentBuilder.Reset()
+		p.inContent = false
+	}
+	if p.transcriptionBuilder.Len() > 0 {
+		p.currentSlide.Transcription, p.currentSlide.Cues = extractCues(strings.TrimSpace(p.transcriptionBuilder.String()))
+		p.transcriptionBuilder.Reset()
+	}
+	if p.notesBuilder.Len() > 0 {
+		p.currentSlide.Notes = strings.TrimSpace(p.notesBuilder.String())
+		p.notesBuilder.Reset()
+	}
+	p.inTranscription = false
+	p.inNotes = false
+	p.inFence = false
+
+	slide := p.currentSlide
+	if slide.Content == "" && slide.Image == "" && slide.Transcription == "" {
+		p.warnf(p.currentSlideLine, 1, CodeEmptySlide,
+			"slide %q has no content, image or transcription", slide.Title)
+	}
+
+	p.script.Slides = append(p.script.Slides, *slide)
+	p.currentSlide = nil
+	p.inDirectives = false
+}
+
+func (p *parser) addDiagnostic(severity Severity, line, column int, code, format string, args ...interface{}) {
+	p.script.Diagnostics = append(p.script.Diagnostics, Diagnostic{
+		Severity: severity,
+		File:     p.path,
+		Line:     line,
+		Column:   column,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *parser) errorf(line, column int, code, format string, args ...interface{}) {
+	p.addDiagnostic(SeverityError, line, column, code, format, args...)
+}
+
+func (p *parser) warnf(line, column int, code, format string, args ...interface{}) {
+	p.addDiagnostic(SeverityWarning, line, column, code, format, args...)
+}
+
+// fatalf records an error that prevents the script from being used at all
+func (p *parser) fatalf(line, column int, code, format string, args ...interface{}) {
+	p.errorf(line, column, code, format, args...)
+	p.fatal = true
+}
+
+// valueColumn returns the 1-based column where the value following prefix starts
+func valueColumn(line, prefix string) int {
+	idx := strings.Index(line, prefix)
+	if idx < 0 {
+		return 1
+	}
+	idx += len(prefix)
+	for idx < len(line) && (line[idx] == ' ' || line[idx] == '\t') {
+		idx++
+	}
+	return idx + 1
+}
+
+// ParseDuration parses a duration written in a script: a plain number of seconds
+// such as "10" or "2.5", or a Go duration such as "1m30s" or "750ms"
+func ParseDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(seconds) && !math.IsInf(seconds, 0) {
+		duration = time.Duration(seconds * float64(time.Second))
+	} else if d, err := time.ParseDuration(value); err == nil {
+		duration = d
+	} else {
+		return 0, fmt.Errorf("expected seconds (e.g. 2.5) or a duration (e.g. 1m30s)")
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return duration, nil
 }
 
 func (s *Script) GetTotalDuration() time.Duration {
-	total := 0
+	var total time.Duration
 	for _, slide := range s.Slides {
 		total += slide.Duration
 	}
-	return time.Duration(total) * time.Second
+	return total
 }
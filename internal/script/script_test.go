@@ -1,9 +1,13 @@
 package script
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -43,11 +47,11 @@ Transcription 2`,
 			expectError: false,
 			expected: &Script{
 				Title:       "Test Presentation",
-				Duration:    60,
-				DefaultTime: 5,
+				Duration:    60 * time.Second,
+				DefaultTime: 5 * time.Second,
 				Slides: []Slide{
-					{Title: "Slide 1", Content: "Content 1", Transcription: "Transcription 1", Duration: 10},
-					{Title: "Slide 2", Content: "Content 2", Transcription: "Transcription 2", Duration: 5},
+					{Title: "Slide 1", Content: "Content 1", Transcription: "Transcription 1", Duration: 10 * time.Second},
+					{Title: "Slide 2", Content: "Content 2", Transcription: "Transcription 2", Duration: 5 * time.Second},
 				},
 			},
 		},
@@ -65,9 +69,9 @@ Transcription 1`,
 			expectError: false,
 			expected: &Script{
 				Title:       "Test",
-				DefaultTime: 10,
+				DefaultTime: 10 * time.Second,
 				Slides: []Slide{
-					{Title: "Slide 1", Content: "Content 1", Transcription: "Transcription 1", Duration: 10},
+					{Title: "Slide 1", Content: "Content 1", Transcription: "Transcription 1", Duration: 10 * time.Second},
 				},
 			},
 		},
@@ -92,9 +96,9 @@ Test transcription`,
 			expectError: false,
 			expected: &Script{
 				Title:       "Test",
-				DefaultTime: 10,
+				DefaultTime: 10 * time.Second,
 				Slides: []Slide{
-					{Title: "Slide 1", Content: "Line 1\nLine 2\nLine 3", Transcription: "Test transcription", Duration: 10},
+					{Title: "Slide 1", Content: "Line 1\nLine 2\nLine 3", Transcription: "Test transcription", Duration: 10 * time.Second},
 				},
 			},
 		},
@@ -118,13 +122,13 @@ This shows Go code`,
 			expectError: false,
 			expected: &Script{
 				Title:       "Code Example",
-				DefaultTime: 10,
+				DefaultTime: 10 * time.Second,
 				Slides: []Slide{
 					{
 						Title:         "Code Slide",
 						Content:       "Here's some code:\n\n```go\nfunc main() {\n    fmt.Println(\"Hello\")\n}\n```",
 						Transcription: "This shows Go code",
-						Duration:      10,
+						Duration:      10 * time.Second,
 					},
 				},
 			},
@@ -163,7 +167,7 @@ This shows Go code`,
 			}
 
 			if result.DefaultTime != tt.expected.DefaultTime {
-				t.Errorf("Expected default time %d, got %d", tt.expected.DefaultTime, result.DefaultTime)
+				t.Errorf("Expected default time %v, got %v", tt.expected.DefaultTime, result.DefaultTime)
 			}
 
 			if len(result.Slides) != len(tt.expected.Slides) {
@@ -183,7 +187,7 @@ This shows Go code`,
 					t.Errorf("Slide %d: expected transcription %s, got %s", i, expected.Transcription, slide.Transcription)
 				}
 				if slide.Duration != expected.Duration {
-					t.Errorf("Slide %d: expected duration %d, got %d", i, expected.Duration, slide.Duration)
+					t.Errorf("Slide %d: expected duration %v, got %v", i, expected.Duration, slide.Duration)
 				}
 			}
 		})
@@ -193,9 +197,9 @@ This shows Go code`,
 func TestScriptGetTotalDuration(t *testing.T) {
 	script := &Script{
 		Slides: []Slide{
-			{Duration: 10},
-			{Duration: 15},
-			{Duration: 5},
+			{Duration: 10 * time.Second},
+			{Duration: 15 * time.Second},
+			{Duration: 5 * time.Second},
 		},
 	}
 
@@ -297,8 +301,8 @@ Content`,
 				if s.Slides[0].Transcription != "" {
 					t.Error("Expected empty transcription")
 				}
-				if s.Slides[0].Duration != 10 {
-					t.Errorf("Expected default duration 10, got %d", s.Slides[0].Duration)
+				if s.Slides[0].Duration != 10*time.Second {
+					t.Errorf("Expected default duration 10s, got %v", s.Slides[0].Duration)
 				}
 			},
 		},
@@ -325,14 +329,14 @@ Duration: 20
 Content`,
 			expectError: false,
 			validation: func(t *testing.T, s *Script) {
-				if s.Slides[0].Duration != 5 {
-					t.Errorf("Slide 1: expected duration 5, got %d", s.Slides[0].Duration)
+				if s.Slides[0].Duration != 5*time.Second {
+					t.Errorf("Slide 1: expected duration 5s, got %v", s.Slides[0].Duration)
 				}
-				if s.Slides[1].Duration != 15 {
-					t.Errorf("Slide 2: expected duration 15, got %d", s.Slides[1].Duration)
+				if s.Slides[1].Duration != 15*time.Second {
+					t.Errorf("Slide 2: expected duration 15s, got %v", s.Slides[1].Duration)
 				}
-				if s.Slides[2].Duration != 20 {
-					t.Errorf("Slide 3: expected duration 20, got %d", s.Slides[2].Duration)
+				if s.Slides[2].Duration != 20*time.Second {
+					t.Errorf("Slide 3: expected duration 20s, got %v", s.Slides[2].Duration)
 				}
 			},
 		},
@@ -513,3 +517,1365 @@ func TestParseScriptLargeFile(t *testing.T) {
 		t.Errorf("Expected last slide title 'Slide %d', got %s", numSlides, result.Slides[numSlides-1].Title)
 	}
 }
+
+func TestParseScriptFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+		validation  func(t *testing.T, s *Script)
+	}{
+		{
+			name: "all known keys",
+			content: `---
+author: Jane Doe
+date: 2024-05-01
+language: es
+theme: dark
+voice: voice-123
+model: eleven_turbo_v2
+tags: [go, training]
+transcription: true
+---
+# Front Matter
+
+## Slide 1
+
+Content`,
+			validation: func(t *testing.T, s *Script) {
+				if s.Title != "Front Matter" {
+					t.Errorf("Expected title 'Front Matter', got %s", s.Title)
+				}
+				if s.Author != "Jane Doe" {
+					t.Errorf("Expected author 'Jane Doe', got %s", s.Author)
+				}
+				if s.Date != "2024-05-01" {
+					t.Errorf("Expected date '2024-05-01', got %s", s.Date)
+				}
+				if s.Language != "es" || s.Theme != "dark" || s.Voice != "voice-123" || s.Model != "eleven_turbo_v2" {
+					t.Errorf("Unexpected metadata: %+v", s)
+				}
+				if len(s.Tags) != 2 || s.Tags[0] != "go" || s.Tags[1] != "training" {
+					t.Errorf("Expected tags [go training], got %v", s.Tags)
+				}
+				if s.Transcription == nil || !*s.Transcription {
+					t.Error("Expected transcription to be enabled")
+				}
+				if len(s.Slides) != 1 {
+					t.Errorf("Expected 1 slide, got %d", len(s.Slides))
+				}
+			},
+		},
+		{
+			name: "unknown keys are kept as extra",
+			content: `---
+audience: engineers
+level: 2
+---
+# Extra`,
+			validation: func(t *testing.T, s *Script) {
+				if s.Extra["audience"] != "engineers" {
+					t.Errorf("Expected extra audience 'engineers', got %v", s.Extra["audience"])
+				}
+				if s.Extra["level"] != 2 {
+					t.Errorf("Expected extra level 2, got %v", s.Extra["level"])
+				}
+				if s.Transcription != nil {
+					t.Error("Expected transcription to be unset")
+				}
+			},
+		},
+		{
+			name: "no front matter",
+			content: `# Plain
+
+## Slide 1
+
+Content`,
+			validation: func(t *testing.T, s *Script) {
+				if s.Author != "" || s.Extra != nil {
+					t.Errorf("Expected empty metadata, got %+v", s)
+				}
+			},
+		},
+		{
+			name: "viewport keys",
+			content: `---
+aspect: "9:16"
+deviceScale: 2
+---
+# Shorts`,
+			validation: func(t *testing.T, s *Script) {
+				v, err := s.Viewport()
+				if err != nil {
+					t.Fatalf("Unexpected viewport error: %v", err)
+				}
+				if v.Width != 1080 || v.Height != 1920 || v.DeviceScale != 2 || v.Orientation() != OrientationPortrait {
+					t.Errorf("Expected a 1080x1920 portrait viewport at scale 2, got %+v", v)
+				}
+			},
+		},
+		{
+			name: "tts keys",
+			content: `---
+tts: openai
+voice: voice-123
+ttsProviders:
+  openai:
+    voice: nova
+    url: http://localhost:8000/v1
+  piper:
+    model: voices/en_US-lessac-medium.onnx
+---
+# Narrated`,
+			validation: func(t *testing.T, s *Script) {
+				if s.TTS != "openai" {
+					t.Errorf("Expected provider openai, got %q", s.TTS)
+				}
+				if got := s.SpeechConfig("openai"); got != (TTSConfig{Voice: "nova", URL: "http://localhost:8000/v1"}) {
+					t.Errorf("Expected the openai block, got %+v", got)
+				}
+				if got := s.SpeechConfig("OpenAI"); got != (TTSConfig{Voice: "nova", URL: "http://localhost:8000/v1"}) {
+					t.Errorf("Expected the openai block whatever the case, got %+v", got)
+				}
+				if got := s.SpeechConfig("piper"); got != (TTSConfig{Model: "voices/en_US-lessac-medium.onnx"}) {
+					t.Errorf("Expected the piper block without the ElevenLabs voice, got %+v", got)
+				}
+				if got := s.SpeechConfig("elevenlabs"); got != (TTSConfig{Voice: "voice-123"}) {
+					t.Errorf("Expected the presentation voice without a block, got %+v", got)
+				}
+			},
+		},
+		{
+			name: "unclosed front matter",
+			content: `---
+author: Jane
+# Title`,
+			expectError: true,
+		},
+		{
+			name: "invalid yaml",
+			content: `---
+tags: [unterminated
+---
+# Title`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "test*.md")
+			if err != nil {
+				t.Fatalf("Failed to create temp file: %v", err)
+			}
+			defer os.Remove(tmpFile.Name())
+
+			if _, err := tmpFile.WriteString(tt.content); err != nil {
+				t.Fatalf("Failed to write to temp file: %v", err)
+			}
+			tmpFile.Close()
+
+			result, err := ParseScript(tmpFile.Name())
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if tt.validation != nil {
+				tt.validation(t, result)
+			}
+		})
+	}
+}
+
+func TestParseScriptDiagnostics(t *testing.T) {
+	content := `# Diagnostics
+
+Duration: soon
+Default time: 0
+
+## Typo
+
+Duraton: 5
+
+Content
+
+## Bad Values
+
+Duration: -3
+Image: does/not/exist.png
+
+Content
+
+## Empty
+`
+
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ParseScript(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Diagnostic{
+		{Severity: SeverityError, Line: 3, Column: 11, Code: CodeInvalidDuration},
+		{Severity: SeverityError, Line: 4, Column: 15, Code: CodeInvalidDefaultTime},
+		{Severity: SeverityWarning, Line: 8, Column: 1, Code: CodeUnknownDirective},
+		{Severity: SeverityError, Line: 14, Column: 11, Code: CodeInvalidDuration},
+		{Severity: SeverityError, Line: 15, Column: 8, Code: CodeMissingImage},
+		{Severity: SeverityWarning, Line: 19, Column: 1, Code: CodeEmptySlide},
+	}
+
+	if len(result.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(result.Diagnostics), result.Diagnostics)
+	}
+
+	for i, d := range result.Diagnostics {
+		e := expected[i]
+		if d.Severity != e.Severity || d.Line != e.Line || d.Column != e.Column || d.Code != e.Code {
+			t.Errorf("Diagnostic %d: expected %s %d:%d %s, got %s", i, e.Severity, e.Line, e.Column, e.Code, d)
+		}
+		if d.File != tmpFile.Name() {
+			t.Errorf("Diagnostic %d: expected file %s, got %s", i, tmpFile.Name(), d.File)
+		}
+	}
+
+	if !result.HasErrors() {
+		t.Error("Expected HasErrors to report errors")
+	}
+
+	// Invalid values must not replace the defaults
+	if result.DefaultTime != 10*time.Second {
+		t.Errorf("Expected default time to stay 10s, got %v", result.DefaultTime)
+	}
+	if result.Slides[1].Duration != 10*time.Second {
+		t.Errorf("Expected slide duration to stay 10s, got %v", result.Slides[1].Duration)
+	}
+}
+
+func TestParseScriptMissingTitleDiagnostic(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString("## Slide\n\nContent"); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	_, err = ParseScript(tmpFile.Name())
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %v", err)
+	}
+
+	found := false
+	for _, d := range parseErr.Diagnostics {
+		if d.Code == CodeMissingTitle && d.Severity == SeverityError {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected missing-title diagnostic, got %v", parseErr.Diagnostics)
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	tests := []struct {
+		diagnostic Diagnostic
+		want       string
+	}{
+		{Diagnostic{Severity: SeverityError, File: "deck.md", Line: 3, Column: 1, Code: CodeUnknownLayout, Message: "unknown layout"}, "deck.md:3:1: error: unknown layout [unknown-layout]"},
+		{Diagnostic{Severity: SeverityWarning, Line: 3, Column: 1, Code: CodeEmptySlide, Message: "empty slide"}, "3:1: warning: empty slide [empty-slide]"},
+		{Diagnostic{Severity: SeverityError, File: "deck.md", Code: CodeMissingTitle, Message: "missing title"}, "deck.md: error: missing title [missing-title]"},
+		{Diagnostic{Severity: SeverityError, Code: CodeMissingTitle, Message: "missing title"}, "error: missing title [missing-title]"},
+	}
+	for _, tt := range tests {
+		if got := tt.diagnostic.String(); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestParseScriptLayout(t *testing.T) {
+	content := `# Layouts
+
+## Cover
+
+Layout: title
+
+Subtitle
+
+## Compare
+
+Layout: Two-Column
+
+Left side
+|||
+Right side
+
+## Typo
+
+Layout: sidebar
+
+Content`
+
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ParseScript(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Slides[0].Layout != LayoutTitle {
+		t.Errorf("Expected layout %q, got %q", LayoutTitle, result.Slides[0].Layout)
+	}
+	if result.Slides[0].Content != "Subtitle" {
+		t.Errorf("Expected layout directive to be excluded from content, got %q", result.Slides[0].Content)
+	}
+	if result.Slides[1].Layout != LayoutTwoColumn {
+		t.Errorf("Expected layout %q, got %q", LayoutTwoColumn, result.Slides[1].Layout)
+	}
+	if result.Slides[2].Layout != LayoutDefault {
+		t.Errorf("Expected unknown layout to fall back to default, got %q", result.Slides[2].Layout)
+	}
+
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != CodeUnknownLayout {
+		t.Errorf("Expected a single unknown-layout diagnostic, got %v", result.Diagnostics)
+	}
+}
+
+func TestSlideColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "no separator",
+			content:  "Only one column",
+			expected: []string{"Only one column"},
+		},
+		{
+			name:     "two columns",
+			content:  "- Left\n\n|||\n\n- Right",
+			expected: []string{"- Left", "- Right"},
+		},
+		{
+			name:     "separator inside code fence",
+			content:  "```\n|||\n```\n|||\nRight",
+			expected: []string{"```\n|||\n```", "Right"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := Slide{Content: tt.content}.Columns()
+			if len(columns) != len(tt.expected) {
+				t.Fatalf("Expected %d columns, got %d: %q", len(tt.expected), len(columns), columns)
+			}
+			for i := range columns {
+				if columns[i] != tt.expected[i] {
+					t.Errorf("Column %d: expected %q, got %q", i, tt.expected[i], columns[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseScriptNotes(t *testing.T) {
+	content := `# Notes
+
+## After Transcription
+
+Content
+
+---
+
+Spoken text
+
+Notes:
+Remember to show the demo
+- and the metrics
+
+## Inline Notes
+
+Content
+Notes: Keep it short
+---
+Spoken after notes
+
+## No Notes
+
+Content
+
+---
+
+Just narration`
+
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ParseScript(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		content       string
+		transcription string
+		notes         string
+	}{
+		{"Content", "Spoken text", "Remember to show the demo\n- and the metrics"},
+		{"Content", "Spoken after notes", "Keep it short"},
+		{"Content", "Just narration", ""},
+	}
+
+	for i, e := range expected {
+		slide := result.Slides[i]
+		if slide.Content != e.content {
+			t.Errorf("Slide %d: expected content %q, got %q", i, e.content, slide.Content)
+		}
+		if slide.Transcription != e.transcription {
+			t.Errorf("Slide %d: expected transcription %q, got %q", i, e.transcription, slide.Transcription)
+		}
+		if slide.Notes != e.notes {
+			t.Errorf("Slide %d: expected notes %q, got %q", i, e.notes, slide.Notes)
+		}
+	}
+}
+
+func writeScriptFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestParseScriptInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	writeScriptFile(t, filepath.Join(dir, "main.md"), `# Main
+
+## Intro
+
+Welcome
+
+Include: chapters/one.md
+
+## Outro
+
+Bye`)
+	writeScriptFile(t, filepath.Join(dir, "chapters", "one.md"), `# Chapter One
+
+## First
+
+Image: images/diagram.png
+
+Chapter content
+
+Include: ../shared/closing.md`)
+	writeScriptFile(t, filepath.Join(dir, "chapters", "images", "diagram.png"), "png")
+	writeScriptFile(t, filepath.Join(dir, "shared", "closing.md"), `## Closing
+
+Shared slide
+
+---
+
+Shared narration`)
+
+	result, err := ParseScript(filepath.Join(dir, "main.md"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Title != "Main" {
+		t.Errorf("Expected title %q, got %q", "Main", result.Title)
+	}
+
+	expectedTitles := []string{"Intro", "First", "Closing", "Outro"}
+	if len(result.Slides) != len(expectedTitles) {
+		t.Fatalf("Expected %d slides, got %d", len(expectedTitles), len(result.Slides))
+	}
+	for i, title := range expectedTitles {
+		if result.Slides[i].Title != title {
+			t.Errorf("Slide %d: expected title %q, got %q", i, title, result.Slides[i].Title)
+		}
+	}
+
+	first := result.Slides[1]
+	if first.Image != filepath.Join("images", "diagram.png") {
+		t.Errorf("Expected image %q, got %q", "images/diagram.png", first.Image)
+	}
+	expectedImage := filepath.Join(dir, "chapters", "images", "diagram.png")
+	if resolved := first.ResolvePath(first.Image); resolved != expectedImage {
+		t.Errorf("Expected image to resolve to %q, got %q", expectedImage, resolved)
+	}
+	if first.Source != filepath.Join(dir, "chapters", "one.md") {
+		t.Errorf("Expected source chapters/one.md, got %q", first.Source)
+	}
+	if result.Slides[2].Transcription != "Shared narration" {
+		t.Errorf("Expected transcription %q, got %q", "Shared narration", result.Slides[2].Transcription)
+	}
+	if len(result.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", result.Diagnostics)
+	}
+}
+
+func TestParseScriptIncludeDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+
+	writeScriptFile(t, filepath.Join(dir, "main.md"), `# Main
+
+Include: a.md`)
+	writeScriptFile(t, filepath.Join(dir, "a.md"), `## A
+
+Image: nope.png
+
+Content
+
+Include: b.md`)
+	writeScriptFile(t, filepath.Join(dir, "b.md"), `## B
+
+Content
+
+Include: a.md`)
+
+	result, err := ParseScript(filepath.Join(dir, "main.md"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Slides) != 2 {
+		t.Fatalf("Expected 2 slides, got %d", len(result.Slides))
+	}
+
+	expected := []struct {
+		file string
+		line int
+		code string
+	}{
+		{"a.md", 3, CodeMissingImage},
+		{"b.md", 5, CodeIncludeCycle},
+	}
+
+	if len(result.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(result.Diagnostics), result.Diagnostics)
+	}
+	for i, e := range expected {
+		d := result.Diagnostics[i]
+		if d.File != filepath.Join(dir, e.file) || d.Line != e.line || d.Code != e.code {
+			t.Errorf("Diagnostic %d: expected %s:%d [%s], got %s", i, e.file, e.line, e.code, d.String())
+		}
+		if d.Severity != SeverityError {
+			t.Errorf("Diagnostic %d: expected error severity, got %s", i, d.Severity)
+		}
+	}
+}
+
+func TestParseScriptBrokenInclude(t *testing.T) {
+	tests := []struct {
+		name     string
+		included string
+		file     string
+		line     int
+		code     string
+	}{
+		{"missing file", "", "main.md", 3, CodeMissingInclude},
+		{"invalid front matter", "---\n: [\n---\n## A\n\nContent", "part.md", 1, CodeInvalidFrontMatter},
+		{"unclosed front matter", "---\nauthor: Jane\n## A\n\nContent", "part.md", 1, CodeInvalidFrontMatter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeScriptFile(t, filepath.Join(dir, "main.md"), "# Main\n\nInclude: part.md\n\n## Last\n\nContent")
+			if tt.included != "" {
+				writeScriptFile(t, filepath.Join(dir, "part.md"), tt.included)
+			}
+
+			_, err := Load(filepath.Join(dir, "main.md"))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a parse error, got %v", err)
+			}
+			found := false
+			for _, d := range parseErr.Diagnostics {
+				if d.File == filepath.Join(dir, tt.file) && d.Line == tt.line && d.Code == tt.code {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected %s:%d [%s], got %v", tt.file, tt.line, tt.code, parseErr.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestEscapeLine(t *testing.T) {
+	tests := map[string]string{
+		"Image: a sunset":     "\\Image: a sunset",
+		"  Include: x.md":     "  \\Include: x.md",
+		"\\Notes: kept":       "\\\\Notes: kept",
+		"Images of the bay":   "Images of the bay",
+		"See Duration: below": "See Duration: below",
+	}
+	for line, want := range tests {
+		if got := EscapeLine(line); got != want {
+			t.Errorf("%q: expected %q, got %q", line, want, got)
+		}
+		if got := unescapeLine(EscapeLine(line)); got != line {
+			t.Errorf("%q: expected to read back the line, got %q", line, got)
+		}
+	}
+}
+
+func TestParseScriptMisplacedInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	writeScriptFile(t, filepath.Join(dir, "main.md"), "# Main\n\n## Code\n\n```markdown\nInclude: other.md\n```\n\n"+
+		"## Prose\n\nInclude: other.md\n\nMore content\n\n---\n\nSay it.\nInclude: other.md\nAnd more.\n\n"+
+		"## Last\n\nEnd\n\nInclude: other.md\n")
+	writeScriptFile(t, filepath.Join(dir, "other.md"), "## Other\n\nOther content")
+
+	result, err := ParseScript(filepath.Join(dir, "main.md"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedTitles := []string{"Code", "Prose", "Last", "Other"}
+	if len(result.Slides) != len(expectedTitles) {
+		t.Fatalf("Expected %d slides, got %d", len(expectedTitles), len(result.Slides))
+	}
+	for i, title := range expectedTitles {
+		if result.Slides[i].Title != title {
+			t.Errorf("Slide %d: expected title %q, got %q", i, title, result.Slides[i].Title)
+		}
+	}
+
+	if want := "```markdown\nInclude: other.md\n```"; result.Slides[0].Content != want {
+		t.Errorf("Expected the code block to keep the include, got %q", result.Slides[0].Content)
+	}
+	prose := result.Slides[1]
+	if want := "Include: other.md\n\nMore content"; prose.Content != want {
+		t.Errorf("Expected content %q, got %q", want, prose.Content)
+	}
+	if want := "Say it.\nInclude: other.md\nAnd more."; prose.Transcription != want {
+		t.Errorf("Expected transcription %q, got %q", want, prose.Transcription)
+	}
+
+	expectedLines := []int{11, 18}
+	if len(result.Diagnostics) != len(expectedLines) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expectedLines), len(result.Diagnostics), result.Diagnostics)
+	}
+	for i, line := range expectedLines {
+		d := result.Diagnostics[i]
+		if d.Line != line || d.Code != CodeMisplacedInclude || d.Severity != SeverityWarning {
+			t.Errorf("Diagnostic %d: expected warning on line %d [%s], got %s", i, line, CodeMisplacedInclude, d.String())
+		}
+	}
+}
+
+func TestParseScriptAssetResolution(t *testing.T) {
+	dir := t.TempDir()
+	deck := filepath.Join(dir, "decks", "foo.md")
+
+	writeScriptFile(t, deck, "---\ntheme: custom.css\n---\n# Assets\n\n## Pictures\n\nImage: img/logo.png\n\n"+
+		"![present](img/logo.png) and ![missing](img/missing.png)\n"+
+		"![remote](https://example.com/a.png)\n\n```markdown\n![in code](img/nope.png)\n```")
+	writeScriptFile(t, filepath.Join(dir, "decks", "img", "logo.png"), "png")
+
+	result, err := ParseScript(deck)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.BaseDir != filepath.Join(dir, "decks") {
+		t.Errorf("Expected base dir %q, got %q", filepath.Join(dir, "decks"), result.BaseDir)
+	}
+	if resolved := result.ResolvePath(result.Theme); resolved != filepath.Join(dir, "decks", "custom.css") {
+		t.Errorf("Expected theme to resolve next to the script, got %q", resolved)
+	}
+
+	expected := []struct {
+		line   int
+		column int
+		code   string
+	}{
+		{10, 41, CodeMissingImage},
+		{1, 1, CodeMissingStylesheet},
+	}
+
+	if len(result.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(result.Diagnostics), result.Diagnostics)
+	}
+	for i, e := range expected {
+		d := result.Diagnostics[i]
+		if d.Line != e.line || d.Column != e.column || d.Code != e.code {
+			t.Errorf("Diagnostic %d: expected %d:%d [%s], got %s", i, e.line, e.column, e.code, d.String())
+		}
+	}
+}
+
+func TestParseScriptCues(t *testing.T) {
+	content := `# Cues
+
+## Agenda
+
+- First
+- Second
+- Third
+
+---
+
+[>] We start with the first point. [>] Then the second, [>]and finally the third.
+
+## Plain
+
+Content
+
+---
+
+No cues here`
+
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ParseScript(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	agenda := result.Slides[0]
+	expectedText := "We start with the first point. Then the second, and finally the third."
+	if agenda.Transcription != expectedText {
+		t.Errorf("Expected transcription %q, got %q", expectedText, agenda.Transcription)
+	}
+
+	expectedCues := []int{0, 31, 48}
+	if len(agenda.Cues) != len(expectedCues) {
+		t.Fatalf("Expected cues %v, got %v", expectedCues, agenda.Cues)
+	}
+	for i, cue := range expectedCues {
+		if agenda.Cues[i] != cue {
+			t.Errorf("Cue %d: expected offset %d, got %d", i, cue, agenda.Cues[i])
+		}
+	}
+
+	fractions := agenda.CueFractions()
+	if fractions[0] != 0 || fractions[2] <= fractions[1] || fractions[2] >= 1 {
+		t.Errorf("Expected increasing cue fractions within [0, 1), got %v", fractions)
+	}
+
+	if plain := result.Slides[1]; plain.Cues != nil || plain.CueFractions() != nil {
+		t.Errorf("Expected no cues on plain slide, got %v", plain.Cues)
+	}
+}
+
+func TestScriptFitDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    int
+		slides    []Slide
+		expected  []int
+		warnings  int
+		shouldErr bool
+	}{
+		{
+			name:   "weighted by transcription length",
+			budget: 60,
+			slides: []Slide{
+				{Title: "Intro", Duration: 10 * time.Second, FixedDuration: true},
+				{Title: "Short", Duration: 10 * time.Second, Transcription: strings.Repeat("a", 100)},
+				{Title: "Long", Duration: 10 * time.Second, Transcription: strings.Repeat("a", 300)},
+			},
+			expected: []int{10, 13, 37},
+		},
+		{
+			name:   "slides without transcription weigh the average",
+			budget: 30,
+			slides: []Slide{
+				{Title: "Narrated", Duration: 10 * time.Second, Transcription: strings.Repeat("a", 50)},
+				{Title: "Silent", Duration: 10 * time.Second},
+				{Title: "Narrated too", Duration: 10 * time.Second, Transcription: strings.Repeat("a", 50)},
+			},
+			expected: []int{10, 10, 10},
+		},
+		{
+			name:   "not enough time left",
+			budget: 11,
+			slides: []Slide{
+				{Title: "Fixed", Duration: 10 * time.Second, FixedDuration: true},
+				{Title: "A", Duration: 10 * time.Second},
+				{Title: "B", Duration: 10 * time.Second},
+			},
+			expected: []int{10, 1, 1},
+			warnings: 1,
+		},
+		{
+			name:   "narrated slides keep their audio length",
+			budget: 60,
+			slides: []Slide{
+				{Title: "Narrated", Duration: 20 * time.Second, NarrationDuration: 19500 * time.Millisecond},
+				{Title: "Fixed", Duration: 10 * time.Second, FixedDuration: true},
+				{Title: "A", Duration: 10 * time.Second},
+				{Title: "B", Duration: 10 * time.Second},
+			},
+			expected: []int{20, 10, 15, 15},
+		},
+		{
+			name:   "narration exceeds the budget",
+			budget: 30,
+			slides: []Slide{
+				{Title: "Narrated", Duration: 25 * time.Second, NarrationDuration: 24500 * time.Millisecond},
+				{Title: "Fixed", Duration: 10 * time.Second, FixedDuration: true},
+				{Title: "Silent", Duration: 10 * time.Second},
+			},
+			expected: []int{25, 10, 1},
+			warnings: 1,
+		},
+		{
+			name:   "fixed slides exceed the budget",
+			budget: 15,
+			slides: []Slide{
+				{Title: "A", Duration: 10 * time.Second, FixedDuration: true},
+				{Title: "B", Duration: 10 * time.Second, FixedDuration: true},
+				{Title: "C", Duration: 10 * time.Second},
+			},
+			expected:  []int{10, 10, 10},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Script{Duration: time.Duration(tt.budget) * time.Second, Slides: tt.slides}
+			report, err := s.FitDuration()
+			if tt.shouldErr != (err != nil) {
+				t.Fatalf("Expected error: %v, got %v", tt.shouldErr, err)
+			}
+
+			for i, seconds := range tt.expected {
+				duration := time.Duration(seconds) * time.Second
+				if s.Slides[i].Duration != duration {
+					t.Errorf("Slide %d: expected duration %v, got %v", i, duration, s.Slides[i].Duration)
+				}
+				if report.Slides[i].Duration != duration {
+					t.Errorf("Report slide %d: expected duration %v, got %v", i, duration, report.Slides[i].Duration)
+				}
+			}
+			if len(report.Warnings) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %v", tt.warnings, report.Warnings)
+			}
+			if !tt.shouldErr && tt.warnings == 0 && report.Total != s.Duration {
+				t.Errorf("Expected total %v, got %v", s.Duration, report.Total)
+			}
+			if !strings.Contains(report.String(), "Total:") {
+				t.Errorf("Expected report to include the total, got %q", report.String())
+			}
+		})
+	}
+}
+
+func TestParseScriptFixedDuration(t *testing.T) {
+	content := `# Fixed
+
+Duration: 60
+Default time: 5
+
+## Explicit
+
+Duration: 20
+
+Content
+
+## Implicit
+
+Content`
+
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ParseScript(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Slides[0].FixedDuration {
+		t.Error("Expected slide with Duration: to have a fixed duration")
+	}
+	if result.Slides[1].FixedDuration {
+		t.Error("Expected slide without Duration: to have a flexible duration")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  time.Duration
+		shouldErr bool
+	}{
+		{"10", 10 * time.Second, false},
+		{"2.5", 2500 * time.Millisecond, false},
+		{"1m30s", 90 * time.Second, false},
+		{"750ms", 750 * time.Millisecond, false},
+		{"0", 0, true},
+		{"-3", 0, true},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+		{"NaN", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseDuration(tt.input)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %v", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseScriptSubSecondDurations(t *testing.T) {
+	content := `# Timing
+
+Duration: 1m30s
+Default time: 2.5
+
+## Default
+
+Content
+
+## Explicit
+
+Duration: 1m5.25s
+
+Content`
+
+	tmpFile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	result, err := ParseScript(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Duration != 90*time.Second {
+		t.Errorf("Expected total duration 1m30s, got %v", result.Duration)
+	}
+	if result.Slides[0].Duration != 2500*time.Millisecond {
+		t.Errorf("Expected default slide duration 2.5s, got %v", result.Slides[0].Duration)
+	}
+	if result.Slides[1].Duration != 65250*time.Millisecond {
+		t.Errorf("Expected explicit slide duration 1m5.25s, got %v", result.Slides[1].Duration)
+	}
+	if result.GetTotalDuration() != 67750*time.Millisecond {
+		t.Errorf("Expected total of slides 1m7.75s, got %v", result.GetTotalDuration())
+	}
+}
+
+func TestScriptWriteMarkdown(t *testing.T) {
+	dir := t.TempDir()
+
+	input := "---\nauthor: Jane Doe\ntags: [go]\naudience: engineers\n---\n" +
+		"# Canonical\nDefault time: 5\nDuration:   1m\n\n" +
+		"## Intro\nImage: logo.png\nLayout: title\nDuration: 2.5\n\n\nWelcome\n---\n[>] Hello   [>] there\nNotes: Smile\n" +
+		"Include: part.md\n" +
+		"## Plain\n\nJust content\n"
+	expected := "---\nauthor: Jane Doe\ntags:\n    - go\naudience: engineers\n---\n" +
+		"# Canonical\n\nDuration: 60\nDefault time: 5\n\n" +
+		"## Intro\n\nDuration: 2.5\nLayout: title\nImage: logo.png\n\nWelcome\n\n---\n\n[>] Hello   [>] there\n\nNotes:\nSmile\n" +
+		"\nInclude: part.md\n" +
+		"\n## Plain\n\nJust content\n"
+
+	writeScriptFile(t, filepath.Join(dir, "deck.md"), input)
+	writeScriptFile(t, filepath.Join(dir, "logo.png"), "png")
+	writeScriptFile(t, filepath.Join(dir, "part.md"), "## Included\n\nFrom another file\n")
+
+	parsed, err := ParseScript(filepath.Join(dir, "deck.md"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(parsed.Slides) != 3 {
+		t.Fatalf("Expected 3 slides, got %d", len(parsed.Slides))
+	}
+
+	formatted, err := parsed.Markdown()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(formatted) != expected {
+		t.Errorf("Expected canonical Markdown:\n%s\ngot:\n%s", expected, formatted)
+	}
+
+	// Formatting is idempotent and preserves the parsed script
+	writeScriptFile(t, filepath.Join(dir, "deck.md"), string(formatted))
+	reparsed, err := ParseScript(filepath.Join(dir, "deck.md"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	again, err := reparsed.Markdown()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(again) != string(formatted) {
+		t.Errorf("Expected formatting to be idempotent, got:\n%s", again)
+	}
+	for i := range parsed.Slides {
+		a, b := parsed.Slides[i], reparsed.Slides[i]
+		if a.Title != b.Title || a.Content != b.Content || a.Transcription != b.Transcription ||
+			a.Notes != b.Notes || a.Duration != b.Duration || a.Image != b.Image || a.Layout != b.Layout ||
+			fmt.Sprint(a.Cues) != fmt.Sprint(b.Cues) {
+			t.Errorf("Slide %d changed after formatting: %+v != %+v", i, a, b)
+		}
+	}
+}
+
+func TestScriptWriteMarkdownCues(t *testing.T) {
+	tests := []struct {
+		transcription string
+		cues          []int
+		expected      string
+	}{
+		{"One two", []int{0, 4}, "[>] One [>] two"},
+		{"wordword", []int{4}, "word[>]word"},
+		{"The end.", []int{8}, "The end.[>]"},
+	}
+
+	for _, tt := range tests {
+		if got := insertCues(tt.transcription, tt.cues); got != tt.expected {
+			t.Errorf("insertCues(%q, %v): expected %q, got %q", tt.transcription, tt.cues, tt.expected, got)
+		}
+		text, cues := extractCues(tt.expected)
+		if text != tt.transcription || fmt.Sprint(cues) != fmt.Sprint(tt.cues) {
+			t.Errorf("extractCues(%q): expected %q %v, got %q %v", tt.expected, tt.transcription, tt.cues, text, cues)
+		}
+	}
+}
+
+func TestScriptJSONRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeScriptFile(t, filepath.Join(dir, "deck.md"), "---\nauthor: Jane Doe\n---\n# JSON\n\nDefault time: 4\n\n"+
+		"## Intro\n\nDuration: 2.5\nLayout: title\nImage: img/logo.png\n\nWelcome\n\n---\n\n[>] Hello [>] world\n\nNotes: Smile\n\n"+
+		"## Second\n\nMore")
+	writeScriptFile(t, filepath.Join(dir, "img", "logo.png"), "png")
+
+	parsed, err := ParseScript(filepath.Join(dir, "deck.md"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := parsed.WriteJSON(&buf); err != nil {
+		t.Fatalf("Failed to write JSON: %v", err)
+	}
+	for _, field := range []string{`"version": 1`, `"duration": 2.5`, `"imagePath": "` + filepath.Join(dir, "img", "logo.png") + `"`, `"cues": [`} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("Expected %s in JSON output:\n%s", field, buf.String())
+		}
+	}
+
+	// Put the JSON elsewhere: slides keep resolving assets against their source
+	jsonPath := filepath.Join(t.TempDir(), "deck.json")
+	writeScriptFile(t, jsonPath, buf.String())
+
+	loaded, err := Load(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to load JSON: %v", err)
+	}
+	if len(loaded.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", loaded.Diagnostics)
+	}
+	if loaded.Title != parsed.Title || loaded.Author != parsed.Author || loaded.DefaultTime != parsed.DefaultTime {
+		t.Errorf("Expected script metadata to survive the round trip, got %+v", loaded)
+	}
+	if len(loaded.Slides) != len(parsed.Slides) {
+		t.Fatalf("Expected %d slides, got %d", len(parsed.Slides), len(loaded.Slides))
+	}
+	for i := range parsed.Slides {
+		a, b := parsed.Slides[i], loaded.Slides[i]
+		if a.Title != b.Title || a.Content != b.Content || a.Transcription != b.Transcription || a.Notes != b.Notes ||
+			a.Duration != b.Duration || a.FixedDuration != b.FixedDuration || a.Image != b.Image ||
+			a.Layout != b.Layout || a.Source != b.Source || fmt.Sprint(a.Cues) != fmt.Sprint(b.Cues) {
+			t.Errorf("Slide %d changed in the round trip: %+v != %+v", i, a, b)
+		}
+	}
+}
+
+func TestScriptJSONFromOtherDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeScriptFile(t, filepath.Join(dir, "deck.md"), "# JSON\n\n## Intro\n\nImage: img/logo.png\n\n![Inline](img/logo.png)")
+	writeScriptFile(t, filepath.Join(dir, "img", "logo.png"), "png")
+
+	// Parse with a path relative to the working directory, as parse -json does
+	t.Chdir(dir)
+	parsed, err := ParseScript("deck.md")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf strings.Builder
+	if err := parsed.WriteJSON(&buf); err != nil {
+		t.Fatalf("Failed to write JSON: %v", err)
+	}
+
+	// Load the JSON from somewhere else
+	other := t.TempDir()
+	writeScriptFile(t, filepath.Join(other, "deck.json"), buf.String())
+	t.Chdir(other)
+	loaded, err := Load("deck.json")
+	if err != nil {
+		t.Fatalf("Failed to load JSON: %v", err)
+	}
+	if len(loaded.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", loaded.Diagnostics)
+	}
+	slide := loaded.Slides[0]
+	if slide.Source != filepath.Join(dir, "deck.md") {
+		t.Errorf("Expected an absolute source, got %q", slide.Source)
+	}
+	if got := slide.ResolvePath(slide.Image); got != filepath.Join(dir, "img", "logo.png") {
+		t.Errorf("Expected the image to resolve to the script directory, got %q", got)
+	}
+
+	// JSON written with a relative source falls back to imagePath
+	stale := strings.ReplaceAll(buf.String(), `"source": "`+filepath.Join(dir, "deck.md")+`"`, `"source": "deck.md"`)
+	writeScriptFile(t, filepath.Join(other, "stale.json"), stale)
+	loaded, err = Load("stale.json")
+	if err != nil {
+		t.Fatalf("Failed to load JSON: %v", err)
+	}
+	if len(loaded.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", loaded.Diagnostics)
+	}
+	if got := loaded.Slides[0].Image; got != filepath.Join(dir, "img", "logo.png") {
+		t.Errorf("Expected the image to come from imagePath, got %q", got)
+	}
+}
+
+func TestParseJSONValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		codes     []string
+		shouldErr bool
+	}{
+		{
+			name:    "minimal",
+			content: `{"version": 1, "title": "Minimal", "slides": [{"title": "One", "content": "Hi"}]}`,
+		},
+		{
+			name:      "wrong version",
+			content:   `{"version": 2, "title": "Future", "slides": []}`,
+			shouldErr: true,
+		},
+		{
+			name:      "missing title",
+			content:   `{"version": 1, "slides": []}`,
+			shouldErr: true,
+		},
+		{
+			name:      "unknown field",
+			content:   `{"version": 1, "title": "Typo", "slids": []}`,
+			shouldErr: true,
+		},
+		{
+			name:    "invalid slide values",
+			content: `{"version": 1, "title": "Bad", "slides": [{"title": "One", "layout": "fancy", "transcription": "Hi", "cues": [5], "duration": -1}]}`,
+			codes:   []string{CodeUnknownLayout, CodeInvalidDuration, CodeInvalidCue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "deck.json")
+			writeScriptFile(t, path, tt.content)
+
+			result, err := ParseJSON(path)
+			if tt.shouldErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var codes []string
+			for _, d := range result.Diagnostics {
+				codes = append(codes, d.Code)
+			}
+			sort.Strings(codes)
+			expected := append([]string{}, tt.codes...)
+			sort.Strings(expected)
+			if fmt.Sprint(codes) != fmt.Sprint(expected) {
+				t.Errorf("Expected diagnostics %v, got %v", expected, codes)
+			}
+			if result.Slides[0].Duration != 10*time.Second {
+				t.Errorf("Expected default duration, got %v", result.Slides[0].Duration)
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]interface{} `json:"properties"`
+		Defs       struct {
+			Slide struct {
+				Properties map[string]struct {
+					Enum []string `json:"enum"`
+				} `json:"properties"`
+			} `json:"slide"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(JSONSchema, &schema); err != nil {
+		t.Fatalf("JSON Schema is not valid JSON: %v", err)
+	}
+
+	// Every field of the representation must be described by the schema
+	for _, check := range []struct {
+		value      interface{}
+		properties map[string]bool
+	}{
+		{jsonScript{}, keys(schema.Properties)},
+		{jsonSlide{}, func() map[string]bool {
+			m := make(map[string]bool)
+			for k := range schema.Defs.Slide.Properties {
+				m[k] = true
+			}
+			return m
+		}()},
+	} {
+		typ := reflect.TypeOf(check.value)
+		for i := 0; i < typ.NumField(); i++ {
+			name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+			if name != "-" && !check.properties[name] {
+				t.Errorf("Field %s.%s is missing from the JSON Schema", typ.Name(), name)
+			}
+		}
+	}
+
+	layouts := append([]string{LayoutDefault}, Layouts()...)
+	if fmt.Sprint(schema.Defs.Slide.Properties["layout"].Enum) != fmt.Sprint(layouts) {
+		t.Errorf("Expected schema layouts %v, got %v", layouts, schema.Defs.Slide.Properties["layout"].Enum)
+	}
+}
+
+func keys(m map[string]interface{}) map[string]bool {
+	result := make(map[string]bool, len(m))
+	for k := range m {
+		result[k] = true
+	}
+	return result
+}
+
+func TestResolveViewport(t *testing.T) {
+	tests := []struct {
+		resolution, aspect string
+		scale              float64
+		want               Viewport
+		wantErr            bool
+	}{
+		{"", "", 0, DefaultViewport, false},
+		{"1280x720", "", 0, Viewport{1280, 720, 1}, false},
+		{"", "4:3", 0, Viewport{1440, 1080, 1}, false},
+		{"", "9:16", 1.5, Viewport{1080, 1920, 1.5}, false},
+		{"", "1:1", 0, Viewport{1080, 1080, 1}, false},
+		{"1080X1920", "9:16", 0, Viewport{1080, 1920, 1}, false},
+		{"1920x1080", "4:3", 0, Viewport{}, true},
+		{"1920", "", 0, Viewport{}, true},
+		{"", "wide", 0, Viewport{}, true},
+		{"", "", -1, Viewport{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveViewport(tt.resolution, tt.aspect, tt.scale)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q %q %v: expected an error", tt.resolution, tt.aspect, tt.scale)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q %q %v: unexpected error: %v", tt.resolution, tt.aspect, tt.scale, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q %q %v: expected %+v, got %+v", tt.resolution, tt.aspect, tt.scale, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// minSlideDuration is the shortest duration FitDuration gives a slide
+const minSlideDuration = time.Second
+
+// SlideTiming is the duration allocated to one slide by FitDuration
+type SlideTiming struct {
+	Index    int
+	Title    string
+	Duration time.Duration
+	Fixed    bool
+	Narrated bool
+}
+
+// TimingReport describes how FitDuration distributed the total duration over the slides
+type TimingReport struct {
+	Budget   time.Duration
+	Fixed    time.Duration
+	Narrated time.Duration
+	Total    time.Duration
+	Slides   []SlideTiming
+	Warnings []string
+}
+
+// FitDuration distributes the script's total Duration over its slides. Slides with
+// their own Duration: keep it, and so do narrated slides, which are timed to their
+// audio; the remaining time is shared by the other slides in proportion to the
+// length of their transcription, to the millisecond. Every slide gets at least one
+// second. It returns an error if the fixed slides alone exceed the total, and warns
+// with the resulting total if narration does.
+func (s *Script) FitDuration() (*TimingReport, error) {
+	report := &TimingReport{Budget: s.Duration}
+	if s.Duration <= 0 {
+		return report, fmt.Errorf("no total duration set")
+	}
+
+	var flexible []int
+	for i, slide := range s.Slides {
+		switch {
+		case slide.NarrationDuration > 0:
+			report.Narrated += slide.Duration
+		case slide.FixedDuration:
+			report.Fixed += slide.Duration
+		default:
+			flexible = append(flexible, i)
+		}
+	}
+
+	if report.Fixed > s.Duration {
+		report.fill(s.Slides)
+		return report, fmt.Errorf("slides with a fixed duration take %v, more than the total duration of %v",
+			report.Fixed, s.Duration)
+	}
+
+	remaining := s.Duration - report.Fixed - report.Narrated
+	if remaining < 0 {
+		// Narration cannot be shortened, so the presentation runs longer
+		for _, i := range flexible {
+			s.Slides[i].Duration = minSlideDuration
+		}
+		report.fill(s.Slides)
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"narrated slides take %v and fixed slides %v, more than the total duration of %v; the presentation runs %v",
+			report.Narrated, report.Fixed, s.Duration, report.Total))
+		return report, nil
+	}
+	if len(flexible) > 0 {
+		if remaining < time.Duration(len(flexible))*minSlideDuration {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"only %v left for %d slides without a fixed duration; giving each %v", remaining, len(flexible), minSlideDuration))
+			for _, i := range flexible {
+				s.Slides[i].Duration = minSlideDuration
+			}
+		} else {
+			parts := distribute(remaining.Milliseconds(), minSlideDuration.Milliseconds(), transcriptionWeights(s.Slides, flexible))
+			for j, ms := range parts {
+				s.Slides[flexible[j]].Duration = time.Duration(ms) * time.Millisecond
+			}
+		}
+	} else if remaining > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"every slide has a fixed duration; %v of the total duration is unused", remaining))
+	}
+
+	report.fill(s.Slides)
+	return report, nil
+}
+
+// fill records the final slide durations in the report
+func (r *TimingReport) fill(slides []Slide) {
+	r.Slides = make([]SlideTiming, len(slides))
+	r.Total = 0
+	for i, slide := range slides {
+		r.Slides[i] = SlideTiming{
+			Index:    i,
+			Title:    slide.Title,
+			Duration: slide.Duration,
+			Fixed:    slide.FixedDuration,
+			Narrated: slide.NarrationDuration > 0,
+		}
+		r.Total += slide.Duration
+	}
+}
+
+// transcriptionWeights returns the weight of each of the given slides. Slides
+// without transcription weigh as much as the average narrated slide.
+func transcriptionWeights(slides []Slide, indexes []int) []int64 {
+	weights := make([]int64, len(indexes))
+	var sum, narrated int64
+	for j, i := range indexes {
+		weights[j] = int64(utf8.RuneCountInString(slides[i].Transcription))
+		if weights[j] > 0 {
+			sum += weights[j]
+			narrated++
+		}
+	}
+
+	average := int64(1)
+	if narrated > 0 {
+		average = max(sum/narrated, 1)
+	}
+	for j := range weights {
+		if weights[j] == 0 {
+			weights[j] = average
+		}
+	}
+	return weights
+}
+
+// distribute splits total into whole parts proportional to weights, each at least
+// minimum, using the largest remainder method so the parts add up to total exactly
+func distribute(total, minimum int64, weights []int64) []int64 {
+	parts := make([]int64, len(weights))
+	// Reserve the minimum of every part and share the rest
+	spare := total - minimum*int64(len(weights))
+
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+
+	type remainder struct {
+		index int
+		value int64
+	}
+	remainders := make([]remainder, len(weights))
+	var assigned int64
+	for i, w := range weights {
+		share := spare * w
+		parts[i] = minimum + share/sum
+		assigned += share / sum
+		remainders[i] = remainder{index: i, value: share % sum}
+	}
+
+	sort.SliceStable(remainders, func(a, b int) bool {
+		return remainders[a].value > remainders[b].value
+	})
+	for k := int64(0); k < spare-assigned; k++ {
+		parts[remainders[k].index]++
+	}
+	return parts
+}
+
+// String formats the report as a table for the command line
+func (r *TimingReport) String() string {
+	var b strings.Builder
+	if r.Narrated > 0 {
+		fmt.Fprintf(&b, "Timing report (total duration %v, fixed %v, narrated %v):\n", r.Budget, r.Fixed, r.Narrated)
+	} else {
+		fmt.Fprintf(&b, "Timing report (total duration %v, fixed %v):\n", r.Budget, r.Fixed)
+	}
+	for _, slide := range r.Slides {
+		kind := "auto"
+		switch {
+		case slide.Narrated:
+			kind = "narrated"
+		case slide.Fixed:
+			kind = "fixed"
+		}
+		fmt.Fprintf(&b, "  %3d. %-40s %8.3fs  %s\n", slide.Index+1, truncate(slide.Title, 40), slide.Duration.Seconds(), kind)
+	}
+	fmt.Fprintf(&b, "  Total: %v\n", r.Total)
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&b, "Warning: %s\n", warning)
+	}
+	return b.String()
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,47 @@
+package script
+
+import "strings"
+
+// TTSConfig is a block of text-to-speech settings for one provider, set in
+// the front matter under ttsProviders
+type TTSConfig struct {
+	// Voice and Model are provider specific: an ElevenLabs voice ID, an
+	// OpenAI voice name, a Piper model file or an espeak-ng voice
+	Voice string `yaml:"voice,omitempty" json:"voice,omitempty"`
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// URL replaces the provider's default endpoint
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Speed scales the speaking rate, where 1 is normal
+	Speed float64 `yaml:"speed,omitempty" json:"speed,omitempty"`
+	// Format is the audio format requested from the provider, such as mp3 or wav
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+}
+
+// VoiceProvider is the text-to-speech provider the presentation-wide voice
+// and model are meant for. They predate per-provider settings and hold
+// ElevenLabs IDs, which mean nothing to other providers.
+const VoiceProvider = "elevenlabs"
+
+// SpeechConfig returns the text-to-speech settings of a provider, named in
+// any case: its ttsProviders block, with the presentation-wide voice and
+// model filling in what the block leaves out for VoiceProvider
+func (s *Script) SpeechConfig(provider string) TTSConfig {
+	provider = strings.ToLower(provider)
+	var config TTSConfig
+	for name, block := range s.TTSProviders {
+		if strings.ToLower(name) == provider {
+			config = block
+			break
+		}
+	}
+	if provider != VoiceProvider {
+		return config
+	}
+	if config.Voice == "" {
+		config.Voice = s.Voice
+	}
+	if config.Model == "" {
+		config.Model = s.Model
+	}
+	return config
+}
@@ -0,0 +1,117 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Orientations of a viewport
+const (
+	OrientationLandscape = "landscape"
+	OrientationPortrait  = "portrait"
+)
+
+// Viewport is the size presentations are played, recorded and rendered at
+type Viewport struct {
+	// Width and Height are in CSS pixels
+	Width, Height int
+	// DeviceScale is the number of device pixels per CSS pixel, so a
+	// 1920x1080 viewport at scale 2 records 3840x2160 frames
+	DeviceScale float64
+}
+
+// DefaultViewport is Full HD, 16:9
+var DefaultViewport = Viewport{Width: 1920, Height: 1080, DeviceScale: 1}
+
+// shortSide is the length of the short side of viewports set by aspect ratio
+const shortSide = 1080
+
+// Orientation returns OrientationPortrait when the viewport is taller than wide
+func (v Viewport) Orientation() string {
+	if v.Height > v.Width {
+		return OrientationPortrait
+	}
+	return OrientationLandscape
+}
+
+// ParseResolution parses a resolution written as WIDTHxHEIGHT, such as 1280x720
+func ParseResolution(s string) (width, height int, err error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if ok {
+		width, err = strconv.Atoi(strings.TrimSpace(w))
+		if err == nil {
+			height, err = strconv.Atoi(strings.TrimSpace(h))
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid resolution %q: expected WIDTHxHEIGHT such as 1920x1080", s)
+	}
+	return width, height, nil
+}
+
+// ParseAspect parses an aspect ratio written as W:H, such as 16:9
+func ParseAspect(s string) (width, height int, err error) {
+	w, h, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if ok {
+		width, err = strconv.Atoi(strings.TrimSpace(w))
+		if err == nil {
+			height, err = strconv.Atoi(strings.TrimSpace(h))
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q: expected W:H such as 16:9", s)
+	}
+	return width, height, nil
+}
+
+// ResolveViewport builds a viewport from a resolution, an aspect ratio and a
+// device scale, any of which may be empty. An aspect ratio on its own keeps
+// the short side at 1080 pixels, so 9:16 is 1080x1920; with a resolution it
+// must match it.
+func ResolveViewport(resolution, aspect string, deviceScale float64) (Viewport, error) {
+	v := DefaultViewport
+	if deviceScale < 0 {
+		return v, fmt.Errorf("invalid device scale %v: must be positive", deviceScale)
+	}
+	if deviceScale > 0 {
+		v.DeviceScale = deviceScale
+	}
+
+	if resolution != "" {
+		width, height, err := ParseResolution(resolution)
+		if err != nil {
+			return v, err
+		}
+		v.Width, v.Height = width, height
+	}
+
+	if aspect != "" {
+		aw, ah, err := ParseAspect(aspect)
+		if err != nil {
+			return v, err
+		}
+		if resolution != "" {
+			if v.Width*ah != v.Height*aw {
+				return v, fmt.Errorf("resolution %s does not have an aspect ratio of %s", resolution, aspect)
+			}
+			return v, nil
+		}
+		if aw >= ah {
+			v.Width, v.Height = evenRound(shortSide*aw, ah), shortSide
+		} else {
+			v.Width, v.Height = shortSide, evenRound(shortSide*ah, aw)
+		}
+	}
+	return v, nil
+}
+
+// evenRound returns n/d rounded to an even number, as video encoders require
+func evenRound(n, d int) int {
+	return (n + d) / (2 * d) * 2
+}
+
+// Viewport returns the viewport set by the front matter, or DefaultViewport
+func (s *Script) Viewport() (Viewport, error) {
+	return ResolveViewport(s.Resolution, s.Aspect, s.DeviceScale)
+}
@@ -0,0 +1,172 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSlideTime is the slide duration a script gets without a Default time: line
+const defaultSlideTime = 10 * time.Second
+
+// WriteMarkdown writes the script as canonical script Markdown: front matter,
+// title and metadata first, then every slide with its directives in a fixed
+// order (Duration, Layout, Image), its content, the transcription after `---`
+// and the speaker notes. Slides spliced in by an Include: are written back as
+// the directive rather than inlined. Parsing the output yields the same script.
+func (s *Script) WriteMarkdown(w io.Writer) error {
+	var b bytes.Buffer
+
+	if err := s.writeFrontMatter(&b); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&b, "# %s\n", s.Title)
+
+	var metadata []string
+	if s.Duration > 0 {
+		metadata = append(metadata, "Duration: "+FormatDuration(s.Duration))
+	}
+	if s.DefaultTime > 0 && s.DefaultTime != defaultSlideTime {
+		metadata = append(metadata, "Default time: "+FormatDuration(s.DefaultTime))
+	}
+	if len(metadata) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(metadata, "\n"))
+	}
+
+	includes := make(map[int][]Include)
+	for _, include := range s.Includes {
+		includes[include.Index] = append(includes[include.Index], include)
+	}
+
+	for i := 0; ; {
+		if pending, ok := includes[i]; ok {
+			delete(includes, i)
+			for _, include := range pending {
+				fmt.Fprintf(&b, "\nInclude: %s\n", include.Path)
+				i += include.Count
+			}
+			continue
+		}
+		if i >= len(s.Slides) {
+			break
+		}
+		s.writeSlide(&b, s.Slides[i])
+		i++
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Markdown returns the script as canonical script Markdown
+func (s *Script) Markdown() ([]byte, error) {
+	var b bytes.Buffer
+	if err := s.WriteMarkdown(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (s *Script) writeFrontMatter(b *bytes.Buffer) error {
+	fm := frontMatter{
+		Author:        s.Author,
+		Date:          s.Date,
+		Language:      s.Language,
+		Theme:         s.Theme,
+		Voice:         s.Voice,
+		Model:         s.Model,
+		Tags:          s.Tags,
+		Transcription: s.Transcription,
+		Resolution:    s.Resolution,
+		Aspect:        s.Aspect,
+		DeviceScale:   s.DeviceScale,
+		TTS:           s.TTS,
+		TTSProviders:  s.TTSProviders,
+		Extra:         s.Extra,
+	}
+
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return fmt.Errorf("failed to write front matter: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "{}" {
+		return nil
+	}
+
+	b.WriteString("---\n")
+	b.Write(data)
+	b.WriteString("---\n")
+	return nil
+}
+
+func (s *Script) writeSlide(b *bytes.Buffer, slide Slide) {
+	fmt.Fprintf(b, "\n## %s\n", slide.Title)
+
+	var directives []string
+	if slide.FixedDuration || (slide.Duration > 0 && slide.Duration != s.defaultTime()) {
+		directives = append(directives, "Duration: "+FormatDuration(slide.Duration))
+	}
+	if slide.Layout != LayoutDefault {
+		directives = append(directives, "Layout: "+slide.Layout)
+	}
+	if slide.Image != "" {
+		directives = append(directives, "Image: "+slide.Image)
+	}
+	if len(directives) > 0 {
+		fmt.Fprintf(b, "\n%s\n", strings.Join(directives, "\n"))
+	}
+
+	// Text lines that look like directives are escaped to stay text
+	if slide.Content != "" {
+		fmt.Fprintf(b, "\n%s\n", EscapeText(slide.Content))
+	}
+	if slide.Transcription != "" {
+		fmt.Fprintf(b, "\n---\n\n%s\n", EscapeText(insertCues(slide.Transcription, slide.Cues)))
+	}
+	if slide.Notes != "" {
+		fmt.Fprintf(b, "\nNotes:\n%s\n", EscapeText(slide.Notes))
+	}
+}
+
+// defaultTime returns the duration slides get when they have no Duration: line
+func (s *Script) defaultTime() time.Duration {
+	if s.DefaultTime > 0 {
+		return s.DefaultTime
+	}
+	return defaultSlideTime
+}
+
+// insertCues puts cue markers back into a transcription at the given byte offsets
+func insertCues(transcription string, cues []int) string {
+	if len(cues) == 0 {
+		return transcription
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, offset := range cues {
+		offset = min(max(offset, last), len(transcription))
+		b.WriteString(transcription[last:offset])
+		b.WriteString(CueMarker)
+		// A space after the marker is dropped again when parsing, but only
+		// where the marker starts a word
+		if offset < len(transcription) && (offset == 0 || strings.ContainsAny(transcription[offset-1:offset], " \n")) {
+			b.WriteString(" ")
+		}
+		last = offset
+	}
+	b.WriteString(transcription[last:])
+	return b.String()
+}
+
+// FormatDuration writes a duration the way scripts spell it: seconds, with
+// decimals only when needed (for example "10" or "2.5")
+func FormatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Embedded default styles
@@ -56,6 +57,11 @@ func (sm *StyleManager) GetStyle(themeName string) (string, error) {
 		return string(content), nil
 	}
 
+	// A stylesheet path that does not exist is a mistake, not a theme name
+	if strings.HasSuffix(strings.ToLower(themeName), ".css") {
+		return "", fmt.Errorf("style file %q not found", themeName)
+	}
+
 	// Default to modern theme
 	return sm.themes["modern"], nil
 }
@@ -103,6 +103,13 @@ body {
 	}
 }
 
+func TestGetStyleMissingFile(t *testing.T) {
+	sm := NewStyleManager()
+	if _, err := sm.GetStyle(filepath.Join(t.TempDir(), "missing.css")); err == nil {
+		t.Error("Expected error for missing style file")
+	}
+}
+
 func TestLoadCustomTheme(t *testing.T) {
 	// Create a temporary CSS file
 	tmpDir := t.TempDir()
@@ -143,3 +150,30 @@ func TestLoadCustomTheme(t *testing.T) {
 		t.Error("Expected 'mycustom' to be in available themes")
 	}
 }
+
+func TestThemesStyleAllLayouts(t *testing.T) {
+	sm := NewStyleManager()
+	selectors := []string{
+		".slide.layout-title",
+		".slide.layout-section",
+		".slide.layout-two-column",
+		".slide.layout-image-right",
+		".slide.layout-full-bleed",
+		".slide-quote",
+		".slide.layout-code-focus",
+		".slide-columns",
+		`body[data-orientation="portrait"] .slide-columns`,
+	}
+
+	for _, theme := range []string{"modern", "minimal", "dark", "elegant"} {
+		css, err := sm.GetStyle(theme)
+		if err != nil {
+			t.Fatalf("Failed to get theme %s: %v", theme, err)
+		}
+		for _, selector := range selectors {
+			if !strings.Contains(css, selector) {
+				t.Errorf("Theme %s does not style %s", theme, selector)
+			}
+		}
+	}
+}
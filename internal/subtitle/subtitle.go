@@ -35,7 +35,7 @@ func NewGenerator(format SubtitleFormat) *Generator {
 }
 
 // Generate creates subtitle content from slide transcriptions
-func (g *Generator) Generate(transcriptions []string, durations []int, defaultDuration int) string {
+func (g *Generator) Generate(transcriptions []string, durations []time.Duration, defaultDuration time.Duration) string {
 	var subtitles []Subtitle
 	currentTime := time.Duration(0)
 
@@ -45,11 +45,10 @@ func (g *Generator) Generate(transcriptions []string, durations []int, defaultDu
 		}
 
 		// Get duration for this slide
-		duration := defaultDuration
+		slideDuration := defaultDuration
 		if i < len(durations) && durations[i] > 0 {
-			duration = durations[i]
+			slideDuration = durations[i]
 		}
-		slideDuration := time.Duration(duration) * time.Second
 
 		// Split transcription into subtitle chunks (max 2 lines, ~50 chars per line)
 		chunks := g.splitIntoChunks(transcription, 100)